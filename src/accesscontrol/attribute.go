@@ -11,6 +11,7 @@ const DEFAULT_UNIT_ID = 0
 const STATUS_OK = 1
 const STATUS_PENDING = 2
 const STATUS_SUSPEND = 3
+const STATUS_PENDING_APPROVAL = 4
 
 // Attirbute Unit List
 // Attirbute Unit List
@@ -64,6 +65,24 @@ var MadifyRoleToAttributeMap = map[int]int{
 	model.USER_ROLE_OWNER: ACTION_MANAGE_ROLE_TO_OWNER, model.USER_ROLE_ADMIN: ACTION_MANAGE_ROLE_TO_ADMIN, model.USER_ROLE_EDITOR: ACTION_MANAGE_ROLE_TO_EDITOR, model.USER_ROLE_VIEWER: ACTION_MANAGE_ROLE_TO_VIEWER,
 }
 
+// this config map domain category to the domain attributes
+var ManageDomainAttributeMap = map[int]int{
+	model.DOMAIN_CATEGORY_TEAM: ACTION_MANAGE_TEAM_DOMAIN, model.DOMAIN_CATEGORY_APP: ACTION_MANAGE_APP_DOMAIN,
+}
+var DeleteDomainAttributeMap = map[int]int{
+	model.DOMAIN_CATEGORY_TEAM: ACTION_DELETE_TEAM_DOMAIN, model.DOMAIN_CATEGORY_APP: ACTION_DELETE_APP_DOMAIN,
+}
+
+// the attributes controlled by team permission invite switches
+var InviteAttributes = []int{
+	ACTION_ACCESS_INVITE_BY_LINK, ACTION_ACCESS_INVITE_BY_EMAIL, ACTION_ACCESS_INVITE_OWNER, ACTION_ACCESS_INVITE_ADMIN, ACTION_ACCESS_INVITE_EDITOR, ACTION_ACCESS_INVITE_VIEWER,
+}
+
+// the attributes controlled by team permission manage team member switches
+var ManageTeamMemberAttributes = []int{
+	ACTION_MANAGE_REMOVE_MEMBER, ACTION_MANAGE_ROLE, ACTION_MANAGE_ROLE_FROM_OWNER, ACTION_MANAGE_ROLE_FROM_ADMIN, ACTION_MANAGE_ROLE_FROM_EDITOR, ACTION_MANAGE_ROLE_FROM_VIEWER, ACTION_MANAGE_ROLE_TO_OWNER, ACTION_MANAGE_ROLE_TO_ADMIN, ACTION_MANAGE_ROLE_TO_EDITOR, ACTION_MANAGE_ROLE_TO_VIEWER,
+}
+
 const (
 	ATTRIBUTE_CATEGORY_ACCESS  = 1
 	ATTRIBUTE_CATEGORY_DELETE  = 2
@@ -156,6 +175,7 @@ const (
 )
 
 // action manage special (only owner and admin can access by default)
+// the attribute IDs are passed by other services, so new attributes should be appended only.
 const (
 	// Team Attribute
 	ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW = iota + 1 // the "editor and viewer can invite" switch
@@ -170,10 +190,18 @@ const (
 	// APP Snapshot
 	ACTOIN_SPECIAL_TAKE_SNAPSHOT
 	ACTOIN_SPECIAL_RECOVER_SNAPSHOT
+	// Team Member Attribute
+	ACTION_SPECIAL_MANAGE_MEMBER_PERMISSION // grant and deny attributes for team member
+	ACTION_SPECIAL_MANAGE_USER_GROUP        // manage user groups and their members
+	ACTION_SPECIAL_MANAGE_ROLE              // manage custom roles and their assignments
+	// Unit Role Relation Attribute
+	ACTION_SPECIAL_MANAGE_UNIT_ACL // manage the ACL of a single unit
+	// Team Member Attribute
+	ACTION_SPECIAL_SUSPEND_MEMBER // suspend and reactivate team member
 )
 
 // Attribute Config List
-// Only define avaliable attribute here
+// Only define avaliable attribute here, this is the default policy when no policy file configured
 // map[AttributeCategory][role][unitType][Attribute]status
 var AttributeConfigList = map[int]map[int]map[int]map[int]bool{
 	ATTRIBUTE_CATEGORY_ACCESS: {
@@ -333,15 +361,18 @@ var AttributeConfigList = map[int]map[int]map[int]map[int]bool{
 	},
 	ATTRIBUTE_CATEGORY_SPECIAL: {
 		model.USER_ROLE_OWNER: {
-			UNIT_TYPE_TEAM:        {ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW: true},
-			UNIT_TYPE_TEAM_MEMBER: {ACTION_SPECIAL_TRANSFER_OWNER: true},
-			UNIT_TYPE_INVITE:      {ACTION_SPECIAL_INVITE_LINK_RENEW: true},
-			UNIT_TYPE_APP:         {ACTION_SPECIAL_RELEASE_APP: true},
+			UNIT_TYPE_TEAM:                {ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_SPECIAL_TRANSFER_OWNER: true, ACTION_SPECIAL_SUSPEND_MEMBER: true, ACTION_SPECIAL_MANAGE_MEMBER_PERMISSION: true, ACTION_SPECIAL_MANAGE_USER_GROUP: true, ACTION_SPECIAL_MANAGE_ROLE: true},
+			UNIT_TYPE_INVITE:              {ACTION_SPECIAL_INVITE_LINK_RENEW: true},
+			UNIT_TYPE_APP:                 {ACTION_SPECIAL_RELEASE_APP: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_SPECIAL_MANAGE_UNIT_ACL: true},
 		},
 		model.USER_ROLE_ADMIN: {
-			UNIT_TYPE_TEAM:   {ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW: true},
-			UNIT_TYPE_INVITE: {ACTION_SPECIAL_INVITE_LINK_RENEW: true},
-			UNIT_TYPE_APP:    {ACTION_SPECIAL_RELEASE_APP: true},
+			UNIT_TYPE_TEAM:                {ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_SPECIAL_SUSPEND_MEMBER: true, ACTION_SPECIAL_MANAGE_MEMBER_PERMISSION: true, ACTION_SPECIAL_MANAGE_USER_GROUP: true, ACTION_SPECIAL_MANAGE_ROLE: true},
+			UNIT_TYPE_INVITE:              {ACTION_SPECIAL_INVITE_LINK_RENEW: true},
+			UNIT_TYPE_APP:                 {ACTION_SPECIAL_RELEASE_APP: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_SPECIAL_MANAGE_UNIT_ACL: true},
 		},
 		model.USER_ROLE_EDITOR: {
 			UNIT_TYPE_APP: {ACTION_SPECIAL_RELEASE_APP: true},
@@ -357,21 +388,70 @@ type Attribute struct {
 	Special map[int]bool
 }
 
+// the attribute maps are copied from now policy, so the attribute group can strip them safely.
 func NewAttribute(userRole int, unitType int) *Attribute {
+	policy := GetPolicy()
 	attr := &Attribute{
-		Access:  AttributeConfigList[ATTRIBUTE_CATEGORY_ACCESS][userRole][unitType],
-		Delete:  AttributeConfigList[ATTRIBUTE_CATEGORY_DELETE][userRole][unitType],
-		Manage:  AttributeConfigList[ATTRIBUTE_CATEGORY_MANAGE][userRole][unitType],
-		Special: AttributeConfigList[ATTRIBUTE_CATEGORY_SPECIAL][userRole][unitType],
+		Access:  copyAttributeMap(policy.ExportAttributes(ATTRIBUTE_CATEGORY_ACCESS, userRole, unitType)),
+		Delete:  copyAttributeMap(policy.ExportAttributes(ATTRIBUTE_CATEGORY_DELETE, userRole, unitType)),
+		Manage:  copyAttributeMap(policy.ExportAttributes(ATTRIBUTE_CATEGORY_MANAGE, userRole, unitType)),
+		Special: copyAttributeMap(policy.ExportAttributes(ATTRIBUTE_CATEGORY_SPECIAL, userRole, unitType)),
 	}
 	return attr
 }
 
+func copyAttributeMap(attributes map[int]bool) map[int]bool {
+	ret := make(map[int]bool, len(attributes))
+	for attribute, value := range attributes {
+		ret[attribute] = value
+	}
+	return ret
+}
+
+// merge the granted attributes of other into this attribute.
+func (attr *Attribute) Merge(other *Attribute) {
+	for _, category := range []int{ATTRIBUTE_CATEGORY_ACCESS, ATTRIBUTE_CATEGORY_DELETE, ATTRIBUTE_CATEGORY_MANAGE, ATTRIBUTE_CATEGORY_SPECIAL} {
+		attributeMap := attr.ExportCategory(category)
+		for attribute, value := range other.ExportCategory(category) {
+			if value {
+				attributeMap[attribute] = true
+			}
+		}
+	}
+}
+
+func (attr *Attribute) ExportCategory(category int) map[int]bool {
+	switch category {
+	case ATTRIBUTE_CATEGORY_ACCESS:
+		return attr.Access
+	case ATTRIBUTE_CATEGORY_DELETE:
+		return attr.Delete
+	case ATTRIBUTE_CATEGORY_MANAGE:
+		return attr.Manage
+	case ATTRIBUTE_CATEGORY_SPECIAL:
+		return attr.Special
+	}
+	return nil
+}
+
 type AttributeGroup struct {
-	UserRole  int
-	UnitType  int
-	UnitID    int
-	Attribute *Attribute
+	UserRole            int
+	UnitType            int
+	UnitID              int
+	Attribute           *Attribute
+	PermissionOverrides []*model.PermissionOverride
+	ReadOnly            bool                  // the archived team is read-only
+	TeamPermission      *model.TeamPermission // the team switches are evaluated as hard deny
+	UserID              int
+	RoleIDs             []int // the custom roles of user
+	UserGroupIDs        []int // the user groups of user
+	GroupUserRoles      []int // the built-in roles granted by user groups
+	UnitACL             []*model.UnitRoleRelation
+	UnitAncestors       []*model.UnitReference // from parent to root
+
+	// the sources of grants on top of the built-in role, for explaining the decision
+	overrideSources  map[*model.PermissionOverride]string
+	attributeSources map[int]map[int]string
 }
 
 func (attrg *AttributeGroup) SetUserRole(userRole int) {
@@ -386,36 +466,88 @@ func (attrg *AttributeGroup) SetUnitID(unitID int) {
 	attrg.UnitID = unitID
 }
 
-func (attrg *AttributeGroup) CanAccess(attribute int) bool {
-	r, match := attrg.Attribute.Access[attribute]
-	if !match {
-		return false
+func (attrg *AttributeGroup) SetUserID(userID int) {
+	attrg.UserID = userID
+}
+
+// strip the invite and manage attributes which closed by the team permission switches.
+func (attrg *AttributeGroup) ApplyTeamPermission(tp *model.TeamPermission) {
+	attrg.TeamPermission = tp
+	if !tp.DoesInviteLinkEnabled() {
+		attrg.StripAttributes(ATTRIBUTE_CATEGORY_ACCESS, UNIT_TYPE_INVITE, ACTION_ACCESS_INVITE_BY_LINK)
+	}
+	switch attrg.UserRole {
+	case model.USER_ROLE_EDITOR:
+		if !tp.DoesEditorCanInvite() {
+			attrg.StripAttributes(ATTRIBUTE_CATEGORY_ACCESS, UNIT_TYPE_INVITE, InviteAttributes...)
+		}
+		if !tp.DoesEditorCanManageTeamMember() {
+			attrg.StripAttributes(ATTRIBUTE_CATEGORY_MANAGE, UNIT_TYPE_TEAM_MEMBER, ManageTeamMemberAttributes...)
+		}
+	case model.USER_ROLE_VIEWER:
+		if !tp.DoesViewerCanInvite() {
+			attrg.StripAttributes(ATTRIBUTE_CATEGORY_ACCESS, UNIT_TYPE_INVITE, InviteAttributes...)
+		}
+		if !tp.DoesViewerCanManageTeamMember() {
+			attrg.StripAttributes(ATTRIBUTE_CATEGORY_MANAGE, UNIT_TYPE_TEAM_MEMBER, ManageTeamMemberAttributes...)
+		}
 	}
-	return r
 }
 
-func (attrg *AttributeGroup) CanDelete(attribute int) bool {
-	r, match := attrg.Attribute.Delete[attribute]
-	if !match {
-		return false
+// strip attributes of target category, only works when the unit type matched.
+func (attrg *AttributeGroup) StripAttributes(category int, unitType int, attributes ...int) {
+	if attrg.UnitType != unitType {
+		return
+	}
+	attributeMap := attrg.Attribute.ExportCategory(category)
+	for _, attribute := range attributes {
+		delete(attributeMap, attribute)
 	}
-	return r
+}
+
+func (attrg *AttributeGroup) CanAccess(attribute int) bool {
+	return attrg.can(ATTRIBUTE_CATEGORY_ACCESS, attribute)
+}
+
+func (attrg *AttributeGroup) CanDelete(attribute int) bool {
+	return attrg.can(ATTRIBUTE_CATEGORY_DELETE, attribute)
 }
 
 func (attrg *AttributeGroup) CanManage(attribute int) bool {
-	r, match := attrg.Attribute.Manage[attribute]
-	if !match {
-		return false
-	}
-	return r
+	return attrg.can(ATTRIBUTE_CATEGORY_MANAGE, attribute)
 }
 
 func (attrg *AttributeGroup) CanManageSpecial(attribute int) bool {
-	r, match := attrg.Attribute.Special[attribute]
-	if !match {
+	return attrg.can(ATTRIBUTE_CATEGORY_SPECIAL, attribute)
+}
+
+// check attribute by category, the unknown category is always denied.
+func (attrg *AttributeGroup) CanInCategory(category int, attribute int) bool {
+	return attrg.can(category, attribute)
+}
+
+// all attribute checks are evaluated here, the permission overrides are evaluated on top of the role attributes.
+// the team switches and a team-wide deny override always win, then the nearest unit with overrides in unit chain decides.
+func (attrg *AttributeGroup) can(category int, attribute int) bool {
+	if attrg.isDeniedByReadOnly(category) {
+		return false
+	}
+	if attrg.isDeniedByTeamPermission(category, attribute) {
+		return false
+	}
+	if attrg.isDeniedByUnitACL(category) {
+		return false
+	}
+	if attrg.matchTeamWidePermissionOverride(model.PERMISSION_EFFECT_DENY, category, attribute) {
 		return false
 	}
-	return r
+	if allowed, decidedBy := attrg.decideByUnitPermissionOverrides(category, attribute); decidedBy != nil {
+		return allowed
+	}
+	if r, match := attrg.Attribute.ExportCategory(category)[attribute]; match && r {
+		return true
+	}
+	return attrg.matchTeamWidePermissionOverride(model.PERMISSION_EFFECT_ALLOW, category, attribute)
 }
 
 func (attrg *AttributeGroup) CanModify(attribute, fromID, toID int) bool {
@@ -433,11 +565,20 @@ func (attrg *AttributeGroup) CanInvite(userRole int) bool {
 		return false
 	}
 	// check attirbute
-	r, match := attrg.Attribute.Access[attribute]
-	if !match {
+	return attrg.CanAccess(attribute)
+}
+
+// remove a team member requires the remove member attribute and the permission to modify target member's role
+func (attrg *AttributeGroup) CanRemove(userRole int) bool {
+	if !attrg.CanManage(ACTION_MANAGE_REMOVE_MEMBER) {
+		return false
+	}
+	// convert to attribute
+	attribute, hit := ModifyRoleFromAttributeMap[userRole]
+	if !hit {
 		return false
 	}
-	return r
+	return attrg.CanManage(attribute)
 }
 
 func (attrg *AttributeGroup) CanModifyRoleFromTo(fromRole, toRole int) bool {
@@ -448,12 +589,25 @@ func (attrg *AttributeGroup) CanModifyRoleFromTo(fromRole, toRole int) bool {
 		return false
 	}
 	// check attirbute
-	fromResult, fromMatch := attrg.Attribute.Manage[fromRoleAttribute]
-	toResult, toMatch := attrg.Attribute.Manage[toRoleAttribute]
-	if !fromMatch || !toMatch {
+	return attrg.CanManage(fromRoleAttribute) && attrg.CanManage(toRoleAttribute)
+}
+
+func (attrg *AttributeGroup) CanManageDomain(category int) bool {
+	// convert to attribute
+	attribute, hit := ManageDomainAttributeMap[category]
+	if !hit {
 		return false
 	}
-	return fromResult && toResult
+	return attrg.CanManage(attribute)
+}
+
+func (attrg *AttributeGroup) CanDeleteDomain(category int) bool {
+	// convert to attribute
+	attribute, hit := DeleteDomainAttributeMap[category]
+	if !hit {
+		return false
+	}
+	return attrg.CanDelete(attribute)
 }
 
 func (attrg *AttributeGroup) DoesNowUserAreEditorOrViewer() bool {
@@ -463,12 +617,30 @@ func (attrg *AttributeGroup) DoesNowUserAreEditorOrViewer() bool {
 	return false
 }
 
+// build attribute group with the live team context, the attributes closed by team will be stripped.
+func NewAttributeGroupInTeam(userRole int, unitType int, team *model.Team) *AttributeGroup {
+	attrg := NewAttributeGroup(userRole, unitType)
+	attrg.ApplyTeamPermission(team.ExportTeamPermission())
+	attrg.ApplyTeamArchive(team)
+	return attrg
+}
+
+// build attribute group for team member, the user groups and member permission overrides will be evaluated on top of the role.
+func NewAttributeGroupForTeamMember(teamMember *model.TeamMember, userGroups []*model.UserGroup, roles []*model.Role, unitType int, team *model.Team) *AttributeGroup {
+	attrg := NewAttributeGroupInTeam(teamMember.ExportUserRole(), unitType, team)
+	attrg.SetUserID(teamMember.UserID)
+	attrg.SetPermissionOverrides(teamMember.ExportPermission().ExportOverrides())
+	attrg.ApplyUserGroups(userGroups, team)
+	attrg.ApplyRoles(roles)
+	return attrg
+}
+
 func NewAttributeGroup(userRole int, unitType int) *AttributeGroup {
 	attr := NewAttribute(userRole, unitType)
 	attrg := &AttributeGroup{
 		UserRole:  userRole,
 		UnitType:  unitType,
-		UnitID:    0, // 0 for placeholder, this feature has not implemented.
+		UnitID:    DEFAULT_UNIT_ID, // 0 means the team-wide attributes
 		Attribute: attr,
 	}
 	return attrg
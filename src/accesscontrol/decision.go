@@ -0,0 +1,195 @@
+package accesscontrol
+
+import (
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// the reasons are listed in evaluation order of AttributeGroup.can().
+const (
+	DECISION_REASON_TEAM_READ_ONLY           = "teamReadOnly"
+	DECISION_REASON_TEAM_SWITCH              = "teamSwitch"
+	DECISION_REASON_UNIT_ACL                 = "unitACL"
+	DECISION_REASON_INHERITED_UNIT_ACL       = "inheritedUnitACL"
+	DECISION_REASON_DENY_OVERRIDE            = "denyOverride"
+	DECISION_REASON_INHERITED_DENY_OVERRIDE  = "inheritedDenyOverride"
+	DECISION_REASON_INHERITED_ALLOW_OVERRIDE = "inheritedAllowOverride"
+	DECISION_REASON_ROLE_POLICY              = "rolePolicy"
+	DECISION_REASON_USER_GROUP               = "userGroup"
+	DECISION_REASON_CUSTOM_ROLE              = "customRole"
+	DECISION_REASON_TEMPORARY_GRANT          = "temporaryGrant"
+	DECISION_REASON_ALLOW_OVERRIDE           = "allowOverride"
+	DECISION_REASON_NOT_GRANTED              = "notGranted"
+)
+
+// the policy entry of the built-in role, the team switches may strip it and the user groups may grant it.
+type DecisionPolicyEntry struct {
+	Role      string `json:"role"`
+	UnitType  string `json:"unitType"`
+	Category  string `json:"category"`
+	Attribute string `json:"attribute"`
+	Granted   bool   `json:"granted"`
+}
+
+type DecisionTeamSwitches struct {
+	ReadOnly       bool                  `json:"readOnly"`
+	TeamPermission *model.TeamPermission `json:"teamPermission"`
+	Stripped       bool                  `json:"stripped"` // the policy entry was stripped by team permission
+}
+
+type DecisionUnitACL struct {
+	Restricted bool                               `json:"restricted"` // the category has ACL entries in unit chain
+	Matched    bool                               `json:"matched"`    // the user matches one of the entries
+	DecidedBy  *model.UnitReferenceForExport      `json:"decidedBy"`  // the nearest unit has entries, it may be an ancestor
+	Entries    []*model.UnitRoleRelationForExport `json:"entries"`
+}
+
+type Decision struct {
+	Allowed          bool                            `json:"allowed"`
+	Reason           string                          `json:"reason"`
+	GrantSource      string                          `json:"grantSource"` // where the grant beyond the role policy comes from, empty for the others
+	Category         int                             `json:"category"`
+	UnitType         int                             `json:"unitType"`
+	UnitID           string                          `json:"unitID"`
+	UnitAncestors    []*model.UnitReferenceForExport `json:"unitAncestors"`
+	Attribute        int                             `json:"attribute"`
+	UserID           string                          `json:"userID"`
+	UserRole         int                             `json:"userRole"`
+	RoleIDs          []string                        `json:"roleIDs"`
+	PolicyEntry      *DecisionPolicyEntry            `json:"policyEntry"`
+	TeamSwitches     *DecisionTeamSwitches           `json:"teamSwitches"`
+	MatchedOverrides []*model.PermissionOverride     `json:"matchedOverrides"`
+	UnitACL          *DecisionUnitACL                `json:"unitACL"`
+}
+
+// explain the check with the same steps of can(), the allowed field is always the result of can().
+func (attrg *AttributeGroup) Explain(category int, attribute int) *Decision {
+	policyGranted := GetPolicy().ExportAttributes(category, attrg.UserRole, attrg.UnitType)[attribute]
+	effectiveGranted := attrg.Attribute.ExportCategory(category)[attribute]
+	decision := &Decision{
+		Allowed:       attrg.can(category, attribute),
+		Category:      category,
+		UnitType:      attrg.UnitType,
+		UnitID:        idconvertor.ConvertIntToString(attrg.UnitID),
+		UnitAncestors: model.ExportUnitReferences(attrg.UnitAncestors),
+		Attribute:     attribute,
+		UserID:        idconvertor.ConvertIntToString(attrg.UserID),
+		UserRole:      attrg.UserRole,
+		RoleIDs:       make([]string, 0, len(attrg.RoleIDs)),
+		PolicyEntry: &DecisionPolicyEntry{
+			Role:      lookUpPolicyName(PolicyRoleNames, attrg.UserRole),
+			UnitType:  lookUpPolicyName(PolicyUnitTypeNames, attrg.UnitType),
+			Category:  lookUpPolicyName(PolicyCategoryNames, category),
+			Attribute: lookUpPolicyName(PolicyAttributeNames[category], attribute),
+			Granted:   policyGranted,
+		},
+		TeamSwitches: &DecisionTeamSwitches{
+			ReadOnly: attrg.ReadOnly,
+			Stripped: policyGranted && !effectiveGranted,
+		},
+		MatchedOverrides: attrg.exportMatchedPermissionOverrides(category, attribute),
+		UnitACL:          attrg.explainUnitACL(category),
+	}
+	for _, roleID := range attrg.RoleIDs {
+		decision.RoleIDs = append(decision.RoleIDs, idconvertor.ConvertIntToString(roleID))
+	}
+	decision.Reason, decision.GrantSource = attrg.explainReason(category, attribute, policyGranted, effectiveGranted)
+	return decision
+}
+
+func (decision *Decision) SetTeamPermission(tp *model.TeamPermission) {
+	decision.TeamSwitches.TeamPermission = tp
+}
+
+func (decision *Decision) ExportForFeedback() interface{} {
+	return decision
+}
+
+// the grant source is reported for the checks allowed beyond the role policy.
+func (attrg *AttributeGroup) explainReason(category int, attribute int, policyGranted bool, effectiveGranted bool) (string, string) {
+	if attrg.isDeniedByReadOnly(category) {
+		return DECISION_REASON_TEAM_READ_ONLY, ""
+	}
+	if attrg.isDeniedByTeamPermission(category, attribute) {
+		return DECISION_REASON_TEAM_SWITCH, ""
+	}
+	if restricted, matched, decidedBy := attrg.decideByUnitACL(category); restricted && !matched {
+		if attrg.isNowUnit(decidedBy) {
+			return DECISION_REASON_UNIT_ACL, ""
+		}
+		return DECISION_REASON_INHERITED_UNIT_ACL, ""
+	}
+	if attrg.matchTeamWidePermissionOverride(model.PERMISSION_EFFECT_DENY, category, attribute) {
+		return DECISION_REASON_DENY_OVERRIDE, ""
+	}
+	if allowed, decidedBy := attrg.decideByUnitPermissionOverrides(category, attribute); decidedBy != nil {
+		inherited := !attrg.isNowUnit(decidedBy)
+		switch {
+		case allowed && inherited:
+			return DECISION_REASON_INHERITED_ALLOW_OVERRIDE, attrg.exportPermissionOverrideSource(attrg.exportUnitPermissionOverrides(category, attribute, decidedBy)[0])
+		case allowed:
+			return DECISION_REASON_ALLOW_OVERRIDE, attrg.exportPermissionOverrideSource(attrg.exportUnitPermissionOverrides(category, attribute, decidedBy)[0])
+		case inherited:
+			return DECISION_REASON_INHERITED_DENY_OVERRIDE, ""
+		}
+		return DECISION_REASON_DENY_OVERRIDE, ""
+	}
+	if effectiveGranted && policyGranted {
+		return DECISION_REASON_ROLE_POLICY, ""
+	}
+	if effectiveGranted {
+		source := attrg.exportAttributeSource(category, attribute)
+		return explainReasonByGrantSource(source), source
+	}
+	if override := attrg.findTeamWidePermissionOverride(model.PERMISSION_EFFECT_ALLOW, category, attribute); override != nil {
+		source := attrg.exportPermissionOverrideSource(override)
+		return explainReasonByGrantSource(source), source
+	}
+	if policyGranted {
+		return DECISION_REASON_TEAM_SWITCH, ""
+	}
+	return DECISION_REASON_NOT_GRANTED, ""
+}
+
+func explainReasonByGrantSource(source string) string {
+	switch source {
+	case GRANT_SOURCE_USER_GROUP:
+		return DECISION_REASON_USER_GROUP
+	case GRANT_SOURCE_ROLE:
+		return DECISION_REASON_CUSTOM_ROLE
+	case GRANT_SOURCE_TEMPORARY_GRANT:
+		return DECISION_REASON_TEMPORARY_GRANT
+	}
+	return DECISION_REASON_ALLOW_OVERRIDE
+}
+
+// export the team-wide overrides and the overrides of units in unit chain.
+func (attrg *AttributeGroup) exportMatchedPermissionOverrides(category int, attribute int) []*model.PermissionOverride {
+	matched := make([]*model.PermissionOverride, 0)
+	for _, override := range attrg.PermissionOverrides {
+		if override.Category == category && override.Attribute == attribute && override.UnitType == attrg.UnitType && override.DoesMatchAllUnits() {
+			matched = append(matched, override)
+		}
+	}
+	for _, unit := range attrg.ExportUnitChain() {
+		matched = append(matched, attrg.exportUnitPermissionOverrides(category, attribute, unit)...)
+	}
+	return matched
+}
+
+func (attrg *AttributeGroup) explainUnitACL(category int) *DecisionUnitACL {
+	unitACL := &DecisionUnitACL{
+		Entries: make([]*model.UnitRoleRelationForExport, 0),
+	}
+	restricted, matched, decidedBy := attrg.decideByUnitACL(category)
+	if decidedBy == nil {
+		return unitACL
+	}
+	unitACL.Restricted = restricted
+	unitACL.Matched = matched
+	unitACL.DecidedBy = decidedBy.Export()
+	for _, entry := range attrg.exportUnitACLEntries(category, decidedBy) {
+		unitACL.Entries = append(unitACL.Entries, entry.Export())
+	}
+	return unitACL
+}
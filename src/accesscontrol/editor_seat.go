@@ -0,0 +1,28 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the members of user group take editor seats when the group grants an editor seat role, or any attribute which the viewer role does not have.
+func DoesUserGroupTakeEditorSeat(userGroup *model.UserGroup) bool {
+	if model.DoesUserRoleTakeEditorSeat(userGroup.ExportUserRole()) {
+		return true
+	}
+	return doesPermissionOverridesTakeEditorSeat(userGroup.ExportPermissionOverrides())
+}
+
+// the users of custom role take editor seats when the role grants any attribute which the viewer role does not have.
+func DoesRoleTakeEditorSeat(role *model.Role) bool {
+	return doesPermissionOverridesTakeEditorSeat(role.ExportPermissionOverrides())
+}
+
+func doesPermissionOverridesTakeEditorSeat(overrides []*model.PermissionOverride) bool {
+	for _, override := range overrides {
+		if !override.IsAllow() {
+			continue
+		}
+		if !NewAttributeGroup(model.USER_ROLE_VIEWER, override.UnitType).CanInCategory(override.Category, override.Attribute) {
+			return true
+		}
+	}
+	return false
+}
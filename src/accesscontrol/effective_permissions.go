@@ -0,0 +1,68 @@
+package accesscontrol
+
+import (
+	"sort"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// the effective permissions are evaluated as same as the checks, so the frontend can hide the actions which will be denied.
+type EffectivePermissions struct {
+	TeamID       string                         `json:"teamID"`
+	UserID       string                         `json:"userID"`
+	UserRole     int                            `json:"userRole"`
+	UserRoleName string                         `json:"userRoleName"`
+	RoleIDs      []string                       `json:"roleIDs"`
+	ReadOnly     bool                           `json:"readOnly"`
+	UnitTypes    map[string]map[string][]string `json:"unitTypes"` // unit type name -> category name -> attribute names
+}
+
+// only team-wide attributes are evaluated, the unit ACL narrows them on target unit.
+func NewEffectivePermissions(subject *Subject) *EffectivePermissions {
+	ep := &EffectivePermissions{
+		TeamID:    idconvertor.ConvertIntToString(subject.Team.ExportID()),
+		RoleIDs:   make([]string, 0, len(subject.Roles)),
+		ReadOnly:  subject.Team.IsArchived(),
+		UnitTypes: make(map[string]map[string][]string),
+	}
+	for _, role := range subject.Roles {
+		ep.RoleIDs = append(ep.RoleIDs, idconvertor.ConvertIntToString(role.ExportID()))
+	}
+	for unitTypeName, unitType := range PolicyUnitTypeNames {
+		attrg := subject.NewAttributeGroup(unitType)
+		ep.UserRole = attrg.UserRole
+		ep.UserID = idconvertor.ConvertIntToString(attrg.UserID)
+		for categoryName, category := range PolicyCategoryNames {
+			attributeNames := make([]string, 0)
+			for attributeName, attribute := range PolicyAttributeNames[category] {
+				if attrg.can(category, attribute) {
+					attributeNames = append(attributeNames, attributeName)
+				}
+			}
+			if len(attributeNames) == 0 {
+				continue
+			}
+			sort.Strings(attributeNames)
+			if ep.UnitTypes[unitTypeName] == nil {
+				ep.UnitTypes[unitTypeName] = make(map[string][]string)
+			}
+			ep.UnitTypes[unitTypeName][categoryName] = attributeNames
+		}
+	}
+	ep.UserRoleName = lookUpPolicyName(PolicyRoleNames, ep.UserRole)
+	return ep
+}
+
+func (ep *EffectivePermissions) ExportForFeedback() interface{} {
+	return ep
+}
+
+// the value without symbolic name returns empty string.
+func lookUpPolicyName(names map[string]int, value int) string {
+	for name, v := range names {
+		if v == value {
+			return name
+		}
+	}
+	return ""
+}
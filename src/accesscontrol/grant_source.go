@@ -0,0 +1,57 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the sources of grants on top of the built-in role, they are recorded to explain the decision.
+const (
+	GRANT_SOURCE_MEMBER          = "member"
+	GRANT_SOURCE_USER_GROUP      = "userGroup"
+	GRANT_SOURCE_ROLE            = "role"
+	GRANT_SOURCE_TEMPORARY_GRANT = "temporaryGrant"
+)
+
+// append the overrides and record their source, the first source wins when the same override is applied twice.
+func (attrg *AttributeGroup) appendPermissionOverrides(source string, overrides []*model.PermissionOverride) {
+	if attrg.overrideSources == nil {
+		attrg.overrideSources = make(map[*model.PermissionOverride]string)
+	}
+	for _, override := range overrides {
+		if _, hit := attrg.overrideSources[override]; !hit {
+			attrg.overrideSources[override] = source
+		}
+	}
+	attrg.PermissionOverrides = append(attrg.PermissionOverrides, overrides...)
+}
+
+// merge the attributes and record the source of the attributes not granted yet.
+func (attrg *AttributeGroup) mergeAttribute(source string, attr *Attribute) {
+	if attrg.attributeSources == nil {
+		attrg.attributeSources = make(map[int]map[int]string)
+	}
+	for _, category := range []int{ATTRIBUTE_CATEGORY_ACCESS, ATTRIBUTE_CATEGORY_DELETE, ATTRIBUTE_CATEGORY_MANAGE, ATTRIBUTE_CATEGORY_SPECIAL} {
+		attributeMap := attrg.Attribute.ExportCategory(category)
+		for attribute, value := range attr.ExportCategory(category) {
+			if !value || attributeMap[attribute] {
+				continue
+			}
+			if attrg.attributeSources[category] == nil {
+				attrg.attributeSources[category] = make(map[int]string)
+			}
+			attrg.attributeSources[category][attribute] = source
+		}
+	}
+	attrg.Attribute.Merge(attr)
+}
+
+// the overrides set by member permission have no recorded source.
+func (attrg *AttributeGroup) exportPermissionOverrideSource(override *model.PermissionOverride) string {
+	if source, hit := attrg.overrideSources[override]; hit {
+		return source
+	}
+	return GRANT_SOURCE_MEMBER
+}
+
+// the empty source means the attribute is granted by the built-in role.
+func (attrg *AttributeGroup) exportAttributeSource(category int, attribute int) string {
+	return attrg.attributeSources[category][attribute]
+}
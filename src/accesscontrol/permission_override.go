@@ -0,0 +1,67 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+func (attrg *AttributeGroup) SetPermissionOverrides(overrides []*model.PermissionOverride) {
+	attrg.PermissionOverrides = overrides
+}
+
+// check if any team-wide override with target effect matches the attribute of now unit type.
+func (attrg *AttributeGroup) matchTeamWidePermissionOverride(effect string, category int, attribute int) bool {
+	return attrg.findTeamWidePermissionOverride(effect, category, attribute) != nil
+}
+
+func (attrg *AttributeGroup) findTeamWidePermissionOverride(effect string, category int, attribute int) *model.PermissionOverride {
+	for _, override := range attrg.PermissionOverrides {
+		if override.Effect != effect || override.Category != category || override.Attribute != attribute {
+			continue
+		}
+		if override.UnitType == attrg.UnitType && override.DoesMatchAllUnits() {
+			return override
+		}
+	}
+	return nil
+}
+
+// export the overrides target the unit exactly.
+func (attrg *AttributeGroup) exportUnitPermissionOverrides(category int, attribute int, unit *model.UnitReference) []*model.PermissionOverride {
+	overrides := make([]*model.PermissionOverride, 0)
+	for _, override := range attrg.PermissionOverrides {
+		if override.Category != category || override.Attribute != attribute || override.DoesMatchAllUnits() {
+			continue
+		}
+		if override.UnitType == unit.UnitType && override.UnitID == unit.UnitID {
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides
+}
+
+// the nearest unit with matched overrides decides, so the override of child unit overrides the one inherited from ancestors.
+// deny overrides allow in the same unit.
+func (attrg *AttributeGroup) decideByUnitPermissionOverrides(category int, attribute int) (allowed bool, decidedBy *model.UnitReference) {
+	for _, unit := range attrg.ExportUnitChain() {
+		overrides := attrg.exportUnitPermissionOverrides(category, attribute, unit)
+		if len(overrides) == 0 {
+			continue
+		}
+		for _, override := range overrides {
+			if !override.IsAllow() {
+				return false, unit
+			}
+		}
+		return true, unit
+	}
+	return false, nil
+}
+
+// the operator can only grant the attribute which he can do in the same unit, this avoids privilege escalation.
+func (attrg *AttributeGroup) CanGrantPermissionOverride(override *model.PermissionOverride) bool {
+	if override.UnitType != attrg.UnitType {
+		return false
+	}
+	if !override.DoesMatchAllUnits() && override.UnitID != attrg.UnitID {
+		return false
+	}
+	return attrg.can(override.Category, override.Attribute)
+}
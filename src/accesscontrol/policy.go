@@ -0,0 +1,309 @@
+package accesscontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+	"gopkg.in/yaml.v2"
+)
+
+// the source of compiled-in policy.
+const POLICY_SOURCE_DEFAULT = "default"
+
+// the version of compiled-in policy, the policy file should declare its own version.
+const POLICY_DEFAULT_VERSION = 0
+
+// the symbolic names used in policy file.
+var PolicyRoleNames = map[string]int{
+	"anonymous": model.USER_ROLE_ANONYMOUS,
+	"owner":     model.USER_ROLE_OWNER,
+	"admin":     model.USER_ROLE_ADMIN,
+	"editor":    model.USER_ROLE_EDITOR,
+	"viewer":    model.USER_ROLE_VIEWER,
+}
+
+var PolicyUnitTypeNames = map[string]int{
+	"team":                     UNIT_TYPE_TEAM,
+	"teamMember":               UNIT_TYPE_TEAM_MEMBER,
+	"user":                     UNIT_TYPE_USER,
+	"invite":                   UNIT_TYPE_INVITE,
+	"domain":                   UNIT_TYPE_DOMAIN,
+	"billing":                  UNIT_TYPE_BILLING,
+	"builderDashboard":         UNIT_TYPE_BUILDER_DASHBOARD,
+	"app":                      UNIT_TYPE_APP,
+	"components":               UNIT_TYPE_COMPONENTS,
+	"resource":                 UNIT_TYPE_RESOURCE,
+	"action":                   UNIT_TYPE_ACTION,
+	"transformer":              UNIT_TYPE_TRANSFORMER,
+	"job":                      UNIT_TYPE_JOB,
+	"treeStates":               UNIT_TYPE_TREE_STATES,
+	"kvStates":                 UNIT_TYPE_KV_STATES,
+	"setStates":                UNIT_TYPE_SET_STATES,
+	"promoteCodes":             UNIT_TYPE_PROMOTE_CODES,
+	"promoteCodeUsages":        UNIT_TYPE_PROMOTE_CODE_USAGES,
+	"roles":                    UNIT_TYPE_ROLES,
+	"userRoleRelations":        UNIT_TYPE_USER_ROLE_RELATIONS,
+	"unitRoleRelations":        UNIT_TYPE_UNIT_ROLE_RELATIONS,
+	"compensatingTransactions": UNIT_TYPE_COMPENSATING_TRANSACTIONS,
+	"transactionSerials":       UNIT_TYPE_TRANSACTION_SERIALS,
+	"capacities":               UNIT_TYPE_CAPACITIES,
+	"drive":                    UNIT_TYPE_DRIVE,
+	"peripheralService":        UNIT_TYPE_PERIPHERAL_SERVICE,
+}
+
+var PolicyCategoryNames = map[string]int{
+	"access":  ATTRIBUTE_CATEGORY_ACCESS,
+	"delete":  ATTRIBUTE_CATEGORY_DELETE,
+	"manage":  ATTRIBUTE_CATEGORY_MANAGE,
+	"special": ATTRIBUTE_CATEGORY_SPECIAL,
+}
+
+// map[AttributeCategory][name]Attribute
+var PolicyAttributeNames = map[int]map[string]int{
+	ATTRIBUTE_CATEGORY_ACCESS: {
+		"view":          ACTION_ACCESS_VIEW,
+		"inviteByLink":  ACTION_ACCESS_INVITE_BY_LINK,
+		"inviteByEmail": ACTION_ACCESS_INVITE_BY_EMAIL,
+		"inviteOwner":   ACTION_ACCESS_INVITE_OWNER,
+		"inviteAdmin":   ACTION_ACCESS_INVITE_ADMIN,
+		"inviteEditor":  ACTION_ACCESS_INVITE_EDITOR,
+		"inviteViewer":  ACTION_ACCESS_INVITE_VIEWER,
+	},
+	ATTRIBUTE_CATEGORY_DELETE: {
+		"delete":           ACTION_DELETE,
+		"deleteTeamDomain": ACTION_DELETE_TEAM_DOMAIN,
+		"deleteAppDomain":  ACTION_DELETE_APP_DOMAIN,
+	},
+	ATTRIBUTE_CATEGORY_MANAGE: {
+		"teamName":           ACTION_MANAGE_TEAM_NAME,
+		"teamIcon":           ACTION_MANAGE_TEAM_ICON,
+		"teamConfig":         ACTION_MANAGE_TEAM_CONFIG,
+		"updateTeamDomain":   ACTION_MANAGE_UPDATE_TEAM_DOMAIN,
+		"removeMember":       ACTION_MANAGE_REMOVE_MEMBER,
+		"role":               ACTION_MANAGE_ROLE,
+		"roleFromOwner":      ACTION_MANAGE_ROLE_FROM_OWNER,
+		"roleFromAdmin":      ACTION_MANAGE_ROLE_FROM_ADMIN,
+		"roleFromEditor":     ACTION_MANAGE_ROLE_FROM_EDITOR,
+		"roleFromViewer":     ACTION_MANAGE_ROLE_FROM_VIEWER,
+		"roleToOwner":        ACTION_MANAGE_ROLE_TO_OWNER,
+		"roleToAdmin":        ACTION_MANAGE_ROLE_TO_ADMIN,
+		"roleToEditor":       ACTION_MANAGE_ROLE_TO_EDITOR,
+		"roleToViewer":       ACTION_MANAGE_ROLE_TO_VIEWER,
+		"renameUser":         ACTION_MANAGE_RENAME_USER,
+		"updateUserAvatar":   ACTION_MANAGE_UPDATE_USER_AVATAR,
+		"configInvite":       ACTION_MANAGE_CONFIG_INVITE,
+		"inviteLink":         ACTION_MANAGE_INVITE_LINK,
+		"teamDomain":         ACTION_MANAGE_TEAM_DOMAIN,
+		"appDomain":          ACTION_MANAGE_APP_DOMAIN,
+		"payment":            ACTION_MANAGE_PAYMENT,
+		"paymentInfo":        ACTION_MANAGE_PAYMENT_INFO,
+		"dashboardBroadcast": ACTION_MANAGE_DASHBOARD_BROADCAST,
+		"createApp":          ACTION_MANAGE_CREATE_APP,
+		"editApp":            ACTION_MANAGE_EDIT_APP,
+		"createResource":     ACTION_MANAGE_CREATE_RESOURCE,
+		"editResource":       ACTION_MANAGE_EDIT_RESOURCE,
+		"createAction":       ACTION_MANAGE_CREATE_ACTION,
+		"editAction":         ACTION_MANAGE_EDIT_ACTION,
+		"previewAction":      ACTION_MANAGE_PREVIEW_ACTION,
+		"runAction":          ACTION_MANAGE_RUN_ACTION,
+		"createFile":         ACTION_MANAGE_CREATE_FILE,
+		"editFile":           ACTION_MANAGE_EDIT_FILE,
+		"createSharelink":    ACTION_MANAGE_CREATE_SHARELINK,
+	},
+	ATTRIBUTE_CATEGORY_SPECIAL: {
+		"editorAndViewerCanInviteByLinkSwitch": ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW,
+		"transferOwner":                        ACTION_SPECIAL_TRANSFER_OWNER,
+		"inviteLinkRenew":                      ACTION_SPECIAL_INVITE_LINK_RENEW,
+		"suspendMember":                        ACTION_SPECIAL_SUSPEND_MEMBER,
+		"releaseApp":                           ACTION_SPECIAL_RELEASE_APP,
+		"generateSQL":                          ACTION_SPECIAL_GENERATE_SQL,
+		"takeSnapshot":                         ACTOIN_SPECIAL_TAKE_SNAPSHOT,
+		"recoverSnapshot":                      ACTOIN_SPECIAL_RECOVER_SNAPSHOT,
+		"manageMemberPermission":               ACTION_SPECIAL_MANAGE_MEMBER_PERMISSION,
+		"manageUserGroup":                      ACTION_SPECIAL_MANAGE_USER_GROUP,
+		"manageRole":                           ACTION_SPECIAL_MANAGE_ROLE,
+		"manageUnitACL":                        ACTION_SPECIAL_MANAGE_UNIT_ACL,
+	},
+}
+
+// the policy file declares role -> unit type -> category -> attributes with symbolic names.
+type PolicyFile struct {
+	Version int                                       `json:"version" yaml:"version"`
+	Roles   map[string]map[string]map[string][]string `json:"roles" yaml:"roles"`
+}
+
+// the policy is immutable after built, the reload replaces it as a whole.
+type Policy struct {
+	Version             int
+	Source              string
+	LoadedAt            time.Time
+	AttributeConfigList map[int]map[int]map[int]map[int]bool // same layout as AttributeConfigList
+}
+
+type PolicyForExport struct {
+	Version  int                                       `json:"version"`
+	Source   string                                    `json:"source"`
+	LoadedAt time.Time                                 `json:"loadedAt"`
+	Roles    map[string]map[string]map[string][]string `json:"roles"`
+}
+
+var nowPolicy = NewDefaultPolicy()
+var nowPolicyLock sync.RWMutex
+
+func GetPolicy() *Policy {
+	nowPolicyLock.RLock()
+	defer nowPolicyLock.RUnlock()
+	return nowPolicy
+}
+
+func SetPolicy(policy *Policy) {
+	nowPolicyLock.Lock()
+	defer nowPolicyLock.Unlock()
+	nowPolicy = policy
+}
+
+// the compiled-in AttributeConfigList is the default policy.
+func NewDefaultPolicy() *Policy {
+	return &Policy{
+		Version:             POLICY_DEFAULT_VERSION,
+		Source:              POLICY_SOURCE_DEFAULT,
+		LoadedAt:            time.Now().UTC(),
+		AttributeConfigList: AttributeConfigList,
+	}
+}
+
+// load and validate the policy file, the format is decided by file extension.
+func LoadPolicyFile(path string) (*Policy, error) {
+	raw, errInRead := os.ReadFile(path)
+	if errInRead != nil {
+		return nil, errInRead
+	}
+	policyFile := &PolicyFile{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if errInDecode := yaml.UnmarshalStrict(raw, policyFile); errInDecode != nil {
+			return nil, errors.New("decode policy file error: " + errInDecode.Error())
+		}
+	case ".json":
+		decoder := json.NewDecoder(strings.NewReader(string(raw)))
+		decoder.DisallowUnknownFields()
+		if errInDecode := decoder.Decode(policyFile); errInDecode != nil {
+			return nil, errors.New("decode policy file error: " + errInDecode.Error())
+		}
+	default:
+		return nil, errors.New("unsupported policy file format: " + path)
+	}
+	policy, errInBuild := NewPolicyByFile(policyFile)
+	if errInBuild != nil {
+		return nil, errInBuild
+	}
+	policy.Source = path
+	return policy, nil
+}
+
+// convert the symbolic names to attributes, any unknown name fails the whole policy.
+func NewPolicyByFile(policyFile *PolicyFile) (*Policy, error) {
+	if policyFile.Version <= POLICY_DEFAULT_VERSION {
+		return nil, errors.New("policy version should be greater than 0.")
+	}
+	if len(policyFile.Roles) == 0 {
+		return nil, errors.New("policy should define at least one role.")
+	}
+	attributeConfigList := make(map[int]map[int]map[int]map[int]bool)
+	for _, category := range PolicyCategoryNames {
+		attributeConfigList[category] = make(map[int]map[int]map[int]bool)
+	}
+	for roleName, unitTypes := range policyFile.Roles {
+		userRole, hit := PolicyRoleNames[roleName]
+		if !hit {
+			return nil, fmt.Errorf("roles.%s: unknown role", roleName)
+		}
+		for unitTypeName, categories := range unitTypes {
+			unitType, hit := PolicyUnitTypeNames[unitTypeName]
+			if !hit {
+				return nil, fmt.Errorf("roles.%s.%s: unknown unit type", roleName, unitTypeName)
+			}
+			for categoryName, attributeNames := range categories {
+				category, hit := PolicyCategoryNames[categoryName]
+				if !hit {
+					return nil, fmt.Errorf("roles.%s.%s.%s: unknown category", roleName, unitTypeName, categoryName)
+				}
+				if attributeConfigList[category][userRole] == nil {
+					attributeConfigList[category][userRole] = make(map[int]map[int]bool)
+				}
+				attributes := make(map[int]bool, len(attributeNames))
+				for _, attributeName := range attributeNames {
+					attribute, hit := PolicyAttributeNames[category][attributeName]
+					if !hit {
+						return nil, fmt.Errorf("roles.%s.%s.%s: unknown attribute %q", roleName, unitTypeName, categoryName, attributeName)
+					}
+					attributes[attribute] = true
+				}
+				attributeConfigList[category][userRole][unitType] = attributes
+			}
+		}
+	}
+	return &Policy{
+		Version:             policyFile.Version,
+		LoadedAt:            time.Now().UTC(),
+		AttributeConfigList: attributeConfigList,
+	}, nil
+}
+
+func (p *Policy) ExportAttributes(category int, userRole int, unitType int) map[int]bool {
+	return p.AttributeConfigList[category][userRole][unitType]
+}
+
+// convert the attributes back to symbolic names, the attribute names are sorted.
+func (p *Policy) ExportPolicyFile() *PolicyFile {
+	policyFile := &PolicyFile{
+		Version: p.Version,
+		Roles:   make(map[string]map[string]map[string][]string),
+	}
+	for roleName, userRole := range PolicyRoleNames {
+		for unitTypeName, unitType := range PolicyUnitTypeNames {
+			for categoryName, category := range PolicyCategoryNames {
+				attributes := p.ExportAttributes(category, userRole, unitType)
+				if len(attributes) == 0 {
+					continue
+				}
+				attributeNames := make([]string, 0, len(attributes))
+				for attributeName, attribute := range PolicyAttributeNames[category] {
+					if attributes[attribute] {
+						attributeNames = append(attributeNames, attributeName)
+					}
+				}
+				sort.Strings(attributeNames)
+				if policyFile.Roles[roleName] == nil {
+					policyFile.Roles[roleName] = make(map[string]map[string][]string)
+				}
+				if policyFile.Roles[roleName][unitTypeName] == nil {
+					policyFile.Roles[roleName][unitTypeName] = make(map[string][]string)
+				}
+				policyFile.Roles[roleName][unitTypeName][categoryName] = attributeNames
+			}
+		}
+	}
+	return policyFile
+}
+
+func (p *Policy) Export() *PolicyForExport {
+	return &PolicyForExport{
+		Version:  p.Version,
+		Source:   p.Source,
+		LoadedAt: p.LoadedAt,
+		Roles:    p.ExportPolicyFile().Roles,
+	}
+}
+
+func (p *Policy) ExportForFeedback() interface{} {
+	return p.Export()
+}
@@ -0,0 +1,142 @@
+package accesscontrol
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const POLICY_EXAMPLE_FILE = "../../DOCUMENTS/access-control-policy.example.yaml"
+
+func TestNewPolicyByFileRejectsInvalidFile(t *testing.T) {
+	cases := []struct {
+		name       string
+		policyFile *PolicyFile
+		errorHint  string
+	}{
+		{
+			name:       "zero version",
+			policyFile: &PolicyFile{Version: 0, Roles: map[string]map[string]map[string][]string{"admin": {"app": {"access": {"view"}}}}},
+			errorHint:  "version",
+		},
+		{
+			name:       "negative version",
+			policyFile: &PolicyFile{Version: -1, Roles: map[string]map[string]map[string][]string{"admin": {"app": {"access": {"view"}}}}},
+			errorHint:  "version",
+		},
+		{
+			name:       "no roles",
+			policyFile: &PolicyFile{Version: 1},
+			errorHint:  "at least one role",
+		},
+		{
+			name:       "unknown role",
+			policyFile: &PolicyFile{Version: 1, Roles: map[string]map[string]map[string][]string{"superuser": {"app": {"access": {"view"}}}}},
+			errorHint:  "roles.superuser: unknown role",
+		},
+		{
+			name:       "unknown unit type",
+			policyFile: &PolicyFile{Version: 1, Roles: map[string]map[string]map[string][]string{"admin": {"spaceship": {"access": {"view"}}}}},
+			errorHint:  "roles.admin.spaceship: unknown unit type",
+		},
+		{
+			name:       "unknown category",
+			policyFile: &PolicyFile{Version: 1, Roles: map[string]map[string]map[string][]string{"admin": {"app": {"fly": {"view"}}}}},
+			errorHint:  "roles.admin.app.fly: unknown category",
+		},
+		{
+			name:       "unknown attribute",
+			policyFile: &PolicyFile{Version: 1, Roles: map[string]map[string]map[string][]string{"admin": {"app": {"access": {"view", "teleport"}}}}},
+			errorHint:  `roles.admin.app.access: unknown attribute "teleport"`,
+		},
+		{
+			name:       "attribute of other category",
+			policyFile: &PolicyFile{Version: 1, Roles: map[string]map[string]map[string][]string{"admin": {"app": {"access": {"editApp"}}}}},
+			errorHint:  `unknown attribute "editApp"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			policy, err := NewPolicyByFile(c.policyFile)
+			if err == nil {
+				t.Fatalf("expected error, got policy %+v", policy)
+			}
+			if !strings.Contains(err.Error(), c.errorHint) {
+				t.Fatalf("expected error contains %q, got %q", c.errorHint, err.Error())
+			}
+		})
+	}
+}
+
+func TestNewPolicyByFileAcceptsValidFile(t *testing.T) {
+	policy, err := NewPolicyByFile(&PolicyFile{Version: 2, Roles: map[string]map[string]map[string][]string{"editor": {"app": {"access": {"view"}, "manage": {"editApp"}}}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Version != 2 {
+		t.Fatalf("expected version 2, got %d", policy.Version)
+	}
+	if !policy.ExportAttributes(ATTRIBUTE_CATEGORY_MANAGE, PolicyRoleNames["editor"], UNIT_TYPE_APP)[ACTION_MANAGE_EDIT_APP] {
+		t.Fatalf("expected editor can edit app")
+	}
+	if policy.ExportAttributes(ATTRIBUTE_CATEGORY_MANAGE, PolicyRoleNames["viewer"], UNIT_TYPE_APP)[ACTION_MANAGE_EDIT_APP] {
+		t.Fatalf("expected viewer not declared in policy can not edit app")
+	}
+}
+
+// the example file documents the compiled-in policy, so they should never drift.
+func TestExamplePolicyFileEqualsDefaultPolicy(t *testing.T) {
+	policy, err := LoadPolicyFile(POLICY_EXAMPLE_FILE)
+	if err != nil {
+		t.Fatalf("load example policy file failed: %v", err)
+	}
+	got := exportGrantedAttributes(policy.AttributeConfigList)
+	want := exportGrantedAttributes(AttributeConfigList)
+	if !reflect.DeepEqual(got, want) {
+		for key := range want {
+			if !got[key] {
+				t.Errorf("example policy file misses %s", key)
+			}
+		}
+		for key := range got {
+			if !want[key] {
+				t.Errorf("example policy file has extra %s", key)
+			}
+		}
+	}
+}
+
+// flatten the granted attributes to "role.unitType.category.attribute" keys, the false and empty entries are ignored.
+func exportGrantedAttributes(attributeConfigList map[int]map[int]map[int]map[int]bool) map[string]bool {
+	granted := make(map[string]bool)
+	for categoryName, category := range PolicyCategoryNames {
+		for roleName, userRole := range PolicyRoleNames {
+			for unitTypeName, unitType := range PolicyUnitTypeNames {
+				for attributeName, attribute := range PolicyAttributeNames[category] {
+					if attributeConfigList[category][userRole][unitType][attribute] {
+						granted[roleName+"."+unitTypeName+"."+categoryName+"."+attributeName] = true
+					}
+				}
+			}
+		}
+	}
+	return granted
+}
+
+// the attribute without symbolic name can not be written in policy file.
+func TestDefaultPolicyAttributesHaveNames(t *testing.T) {
+	for category, userRoles := range AttributeConfigList {
+		for userRole, unitTypes := range userRoles {
+			for unitType, attributes := range unitTypes {
+				for attribute, granted := range attributes {
+					if !granted {
+						continue
+					}
+					if lookUpPolicyName(PolicyAttributeNames[category], attribute) == "" {
+						t.Errorf("attribute %d of category %d granted to role %d on unit type %d has no policy name", attribute, category, userRole, unitType)
+					}
+				}
+			}
+		}
+	}
+}
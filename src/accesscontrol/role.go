@@ -0,0 +1,15 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the custom roles extend the built-in role, so their permissions only grant attributes.
+func (attrg *AttributeGroup) ApplyRoles(roles []*model.Role) {
+	attrg.applyRoles(GRANT_SOURCE_ROLE, roles)
+}
+
+func (attrg *AttributeGroup) applyRoles(source string, roles []*model.Role) {
+	for _, role := range roles {
+		attrg.RoleIDs = append(attrg.RoleIDs, role.ExportID())
+		attrg.appendPermissionOverrides(source, role.ExportPermissionOverrides())
+	}
+}
@@ -0,0 +1,84 @@
+package accesscontrol
+
+import (
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+// the subject holds everything to build attribute groups of a user in team, so it can be resolved once for many checks.
+// the team member is nil for anonymous user.
+type Subject struct {
+	Team        *model.Team
+	TeamMember  *model.TeamMember
+	UserGroups  []*model.UserGroup
+	Roles       []*model.Role
+	IPAllowlist *model.TeamIPAllowlist
+
+	// the unexpired temporary grants and the custom roles granted by them, they are filtered by time on evaluation.
+	TemporaryGrants     []*model.TemporaryGrant
+	TemporaryGrantRoles []*model.Role
+}
+
+func NewAnonymousSubject(team *model.Team) *Subject {
+	return &Subject{
+		Team: team,
+	}
+}
+
+func NewTeamMemberSubject(team *model.Team, teamMember *model.TeamMember, userGroups []*model.UserGroup, roles []*model.Role) *Subject {
+	return &Subject{
+		Team:       team,
+		TeamMember: teamMember,
+		UserGroups: userGroups,
+		Roles:      roles,
+	}
+}
+
+func (s *Subject) SetTemporaryGrants(temporaryGrants []*model.TemporaryGrant, roles []*model.Role) {
+	s.TemporaryGrants = temporaryGrants
+	s.TemporaryGrantRoles = roles
+}
+
+func (s *Subject) SetIPAllowlist(allowlist *model.TeamIPAllowlist) {
+	s.IPAllowlist = allowlist
+}
+
+func (s *Subject) IsAnonymous() bool {
+	return s.TeamMember == nil
+}
+
+func (s *Subject) NewAttributeGroup(unitType int) *AttributeGroup {
+	if s.IsAnonymous() {
+		return NewAttributeGroupInTeam(model.USER_ROLE_ANONYMOUS, unitType, s.Team)
+	}
+	attrg := NewAttributeGroupForTeamMember(s.TeamMember, s.UserGroups, s.Roles, unitType, s.Team)
+	attrg.ApplyTemporaryGrants(s.TemporaryGrants, s.TemporaryGrantRoles, time.Now().UTC())
+	return attrg
+}
+
+func NewSubjectBySnapshot(snapshot *model.AccessControlSnapshot) *Subject {
+	return &Subject{
+		Team:        snapshot.Team,
+		TeamMember:  snapshot.TeamMember,
+		UserGroups:  snapshot.UserGroups,
+		Roles:       snapshot.Roles,
+		IPAllowlist: snapshot.IPAllowlist,
+
+		TemporaryGrants:     snapshot.TemporaryGrants,
+		TemporaryGrantRoles: snapshot.TemporaryGrantRoles,
+	}
+}
+
+func (s *Subject) ExportSnapshot() *model.AccessControlSnapshot {
+	return &model.AccessControlSnapshot{
+		Team:        s.Team,
+		TeamMember:  s.TeamMember,
+		UserGroups:  s.UserGroups,
+		Roles:       s.Roles,
+		IPAllowlist: s.IPAllowlist,
+
+		TemporaryGrants:     s.TemporaryGrants,
+		TemporaryGrantRoles: s.TemporaryGrantRoles,
+	}
+}
@@ -0,0 +1,23 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the archived team is read-only, all manage and delete attributes are denied and no one can be invited.
+func (attrg *AttributeGroup) ApplyTeamArchive(team *model.Team) {
+	if !team.IsArchived() {
+		return
+	}
+	attrg.ReadOnly = true
+	attrg.StripAttributes(ATTRIBUTE_CATEGORY_ACCESS, UNIT_TYPE_INVITE, InviteAttributes...)
+}
+
+// the invite attributes are denied here too, so an allow override can not invite anyone into the archived team.
+func (attrg *AttributeGroup) isDeniedByReadOnly(category int) bool {
+	if !attrg.ReadOnly {
+		return false
+	}
+	if category == ATTRIBUTE_CATEGORY_ACCESS && attrg.UnitType == UNIT_TYPE_INVITE {
+		return true
+	}
+	return category == ATTRIBUTE_CATEGORY_MANAGE || category == ATTRIBUTE_CATEGORY_DELETE
+}
@@ -0,0 +1,55 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the attributes closed by team permission switches are denied even if an allow override grants them.
+// they are only open when one of the built-in roles of user (the member role or the roles granted by user groups) is not closed by the switches.
+func (attrg *AttributeGroup) isDeniedByTeamPermission(category int, attribute int) bool {
+	if attrg.TeamPermission == nil {
+		return false
+	}
+	for _, userRole := range attrg.ExportUserRoles() {
+		if !isClosedByTeamPermission(attrg.TeamPermission, userRole, attrg.UnitType, category, attribute) {
+			return false
+		}
+	}
+	return true
+}
+
+func isClosedByTeamPermission(tp *model.TeamPermission, userRole int, unitType int, category int, attribute int) bool {
+	switch {
+	case category == ATTRIBUTE_CATEGORY_ACCESS && unitType == UNIT_TYPE_INVITE:
+		if attribute == ACTION_ACCESS_INVITE_BY_LINK && !tp.DoesInviteLinkEnabled() {
+			return true
+		}
+		if !doesAttributesInclude(InviteAttributes, attribute) {
+			return false
+		}
+		switch userRole {
+		case model.USER_ROLE_EDITOR:
+			return !tp.DoesEditorCanInvite()
+		case model.USER_ROLE_VIEWER:
+			return !tp.DoesViewerCanInvite()
+		}
+	case category == ATTRIBUTE_CATEGORY_MANAGE && unitType == UNIT_TYPE_TEAM_MEMBER:
+		if !doesAttributesInclude(ManageTeamMemberAttributes, attribute) {
+			return false
+		}
+		switch userRole {
+		case model.USER_ROLE_EDITOR:
+			return !tp.DoesEditorCanManageTeamMember()
+		case model.USER_ROLE_VIEWER:
+			return !tp.DoesViewerCanManageTeamMember()
+		}
+	}
+	return false
+}
+
+func doesAttributesInclude(attributes []int, attribute int) bool {
+	for _, target := range attributes {
+		if target == attribute {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package accesscontrol
+
+import (
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+// only the grants active at now are applied, so the grant takes effect and expires without cleanup.
+// the granted role missing in roles was deleted and grants nothing.
+func (attrg *AttributeGroup) ApplyTemporaryGrants(temporaryGrants []*model.TemporaryGrant, roles []*model.Role, now time.Time) {
+	rolesLT := make(map[int]*model.Role, len(roles))
+	for _, role := range roles {
+		rolesLT[role.ExportID()] = role
+	}
+	for _, temporaryGrant := range temporaryGrants {
+		if !temporaryGrant.IsActiveAt(now) {
+			continue
+		}
+		if role, hit := rolesLT[temporaryGrant.RoleID]; hit && temporaryGrant.DoesGrantRole() {
+			attrg.applyRoles(GRANT_SOURCE_TEMPORARY_GRANT, []*model.Role{role})
+		}
+		attrg.appendPermissionOverrides(GRANT_SOURCE_TEMPORARY_GRANT, temporaryGrant.ExportPermissionOverrides())
+	}
+}
@@ -0,0 +1,44 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the unit ACL only narrows the team-wide attributes on one unit, the subject in ACL still needs the attribute itself.
+// the ACL of ancestors should also be set, they cascade to the children.
+func (attrg *AttributeGroup) SetUnitACL(unitACL []*model.UnitRoleRelation) {
+	attrg.UnitACL = unitACL
+}
+
+// the nearest unit which has ACL entries of the category decides, the category without ACL entries in unit chain
+// falls back to team-wide attributes, and the owner is never restricted.
+func (attrg *AttributeGroup) isDeniedByUnitACL(category int) bool {
+	restricted, matched, _ := attrg.decideByUnitACL(category)
+	return restricted && !matched
+}
+
+func (attrg *AttributeGroup) decideByUnitACL(category int) (restricted bool, matched bool, decidedBy *model.UnitReference) {
+	if attrg.UserRole == model.USER_ROLE_OWNER {
+		return false, false, nil
+	}
+	for _, unit := range attrg.ExportUnitChain() {
+		for _, entry := range attrg.exportUnitACLEntries(category, unit) {
+			restricted = true
+			if entry.DoesMatchSubject(attrg.ExportUserRoles(), attrg.UserID, attrg.RoleIDs, attrg.UserGroupIDs) {
+				matched = true
+			}
+		}
+		if restricted {
+			return restricted, matched, unit
+		}
+	}
+	return false, false, nil
+}
+
+func (attrg *AttributeGroup) exportUnitACLEntries(category int, unit *model.UnitReference) []*model.UnitRoleRelation {
+	entries := make([]*model.UnitRoleRelation, 0)
+	for _, entry := range attrg.UnitACL {
+		if entry.UnitType == unit.UnitType && entry.UnitID == unit.UnitID && entry.Category == category {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
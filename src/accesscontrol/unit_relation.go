@@ -0,0 +1,37 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// map[child unit type][parent unit type], the units of other types have no parent and inherit from team only.
+// the parent unit types never have parent, so the hierarchy has one level and can not make a cycle.
+var UnitParentTypes = map[int]map[int]bool{
+	UNIT_TYPE_ACTION:      {UNIT_TYPE_APP: true},
+	UNIT_TYPE_COMPONENTS:  {UNIT_TYPE_APP: true},
+	UNIT_TYPE_TRANSFORMER: {UNIT_TYPE_APP: true},
+	UNIT_TYPE_TREE_STATES: {UNIT_TYPE_APP: true},
+	UNIT_TYPE_KV_STATES:   {UNIT_TYPE_APP: true},
+	UNIT_TYPE_SET_STATES:  {UNIT_TYPE_APP: true},
+}
+
+func CanBeParentUnitType(unitType int, parentUnitType int) bool {
+	return UnitParentTypes[unitType][parentUnitType]
+}
+
+// the ancestors are ordered from parent to root.
+func (attrg *AttributeGroup) SetUnitAncestors(ancestors []*model.UnitReference) {
+	attrg.UnitAncestors = ancestors
+}
+
+// export now unit and its ancestors, the nearest unit comes first. the team-wide check has no unit chain.
+func (attrg *AttributeGroup) ExportUnitChain() []*model.UnitReference {
+	if attrg.UnitID == DEFAULT_UNIT_ID {
+		return []*model.UnitReference{}
+	}
+	chain := make([]*model.UnitReference, 0, len(attrg.UnitAncestors)+1)
+	chain = append(chain, model.NewUnitReference(attrg.UnitType, attrg.UnitID))
+	return append(chain, attrg.UnitAncestors...)
+}
+
+func (attrg *AttributeGroup) isNowUnit(unit *model.UnitReference) bool {
+	return unit.UnitType == attrg.UnitType && unit.UnitID == attrg.UnitID
+}
@@ -0,0 +1,188 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+const (
+	TEST_APP_ID    = 10
+	TEST_ACTION_ID = 20
+)
+
+func newActionInAppAttributeGroup(userRole int, unitACL []*model.UnitRoleRelation, overrides []*model.PermissionOverride) *AttributeGroup {
+	attrg := NewAttributeGroup(userRole, UNIT_TYPE_ACTION)
+	attrg.SetUnitID(TEST_ACTION_ID)
+	attrg.SetUnitAncestors([]*model.UnitReference{model.NewUnitReference(UNIT_TYPE_APP, TEST_APP_ID)})
+	attrg.SetUnitACL(unitACL)
+	attrg.SetPermissionOverrides(overrides)
+	return attrg
+}
+
+func newActionDeleteOverride(effect string, unitType int, unitID int) *model.PermissionOverride {
+	return &model.PermissionOverride{
+		Effect:    effect,
+		Category:  ATTRIBUTE_CATEGORY_DELETE,
+		UnitType:  unitType,
+		UnitID:    unitID,
+		Attribute: ACTION_DELETE,
+	}
+}
+
+func newAccessACLEntry(unitType int, unitID int, userRole int) *model.UnitRoleRelation {
+	return &model.UnitRoleRelation{
+		UnitType: unitType,
+		UnitID:   unitID,
+		Category: ATTRIBUTE_CATEGORY_ACCESS,
+		UserRole: userRole,
+	}
+}
+
+func TestPermissionOverridesCascadeFromParentUnit(t *testing.T) {
+	cases := []struct {
+		name      string
+		userRole  int
+		overrides []*model.PermissionOverride
+		allowed   bool
+	}{
+		{
+			name:     "viewer without overrides",
+			userRole: model.USER_ROLE_VIEWER,
+			allowed:  false,
+		},
+		{
+			name:      "allow on app is inherited by action",
+			userRole:  model.USER_ROLE_VIEWER,
+			overrides: []*model.PermissionOverride{newActionDeleteOverride(model.PERMISSION_EFFECT_ALLOW, UNIT_TYPE_APP, TEST_APP_ID)},
+			allowed:   true,
+		},
+		{
+			name:      "deny on app is inherited by action",
+			userRole:  model.USER_ROLE_EDITOR,
+			overrides: []*model.PermissionOverride{newActionDeleteOverride(model.PERMISSION_EFFECT_DENY, UNIT_TYPE_APP, TEST_APP_ID)},
+			allowed:   false,
+		},
+		{
+			name:     "deny on action beats allow on app",
+			userRole: model.USER_ROLE_VIEWER,
+			overrides: []*model.PermissionOverride{
+				newActionDeleteOverride(model.PERMISSION_EFFECT_ALLOW, UNIT_TYPE_APP, TEST_APP_ID),
+				newActionDeleteOverride(model.PERMISSION_EFFECT_DENY, UNIT_TYPE_ACTION, TEST_ACTION_ID),
+			},
+			allowed: false,
+		},
+		{
+			name:     "allow on action beats deny on app",
+			userRole: model.USER_ROLE_EDITOR,
+			overrides: []*model.PermissionOverride{
+				newActionDeleteOverride(model.PERMISSION_EFFECT_DENY, UNIT_TYPE_APP, TEST_APP_ID),
+				newActionDeleteOverride(model.PERMISSION_EFFECT_ALLOW, UNIT_TYPE_ACTION, TEST_ACTION_ID),
+			},
+			allowed: true,
+		},
+		{
+			name:      "override on other app is not inherited",
+			userRole:  model.USER_ROLE_VIEWER,
+			overrides: []*model.PermissionOverride{newActionDeleteOverride(model.PERMISSION_EFFECT_ALLOW, UNIT_TYPE_APP, TEST_APP_ID+1)},
+			allowed:   false,
+		},
+		{
+			name:     "team-wide deny beats allow on action",
+			userRole: model.USER_ROLE_EDITOR,
+			overrides: []*model.PermissionOverride{
+				newActionDeleteOverride(model.PERMISSION_EFFECT_DENY, UNIT_TYPE_ACTION, model.PERMISSION_OVERRIDE_ALL_UNITS),
+				newActionDeleteOverride(model.PERMISSION_EFFECT_ALLOW, UNIT_TYPE_ACTION, TEST_ACTION_ID),
+			},
+			allowed: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			attrg := newActionInAppAttributeGroup(c.userRole, nil, c.overrides)
+			if allowed := attrg.CanDelete(ACTION_DELETE); allowed != c.allowed {
+				t.Fatalf("expected allowed %v, got %v", c.allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestUnitACLCascadesFromParentUnit(t *testing.T) {
+	cases := []struct {
+		name     string
+		userRole int
+		unitACL  []*model.UnitRoleRelation
+		allowed  bool
+	}{
+		{
+			name:     "no ACL falls back to team-wide attributes",
+			userRole: model.USER_ROLE_EDITOR,
+			allowed:  true,
+		},
+		{
+			name:     "ACL on app restricts action",
+			userRole: model.USER_ROLE_EDITOR,
+			unitACL:  []*model.UnitRoleRelation{newAccessACLEntry(UNIT_TYPE_APP, TEST_APP_ID, model.USER_ROLE_ADMIN)},
+			allowed:  false,
+		},
+		{
+			name:     "ACL on app includes subject",
+			userRole: model.USER_ROLE_ADMIN,
+			unitACL:  []*model.UnitRoleRelation{newAccessACLEntry(UNIT_TYPE_APP, TEST_APP_ID, model.USER_ROLE_ADMIN)},
+			allowed:  true,
+		},
+		{
+			name:     "ACL on action overrides ACL on app",
+			userRole: model.USER_ROLE_EDITOR,
+			unitACL: []*model.UnitRoleRelation{
+				newAccessACLEntry(UNIT_TYPE_APP, TEST_APP_ID, model.USER_ROLE_ADMIN),
+				newAccessACLEntry(UNIT_TYPE_ACTION, TEST_ACTION_ID, model.USER_ROLE_EDITOR),
+			},
+			allowed: true,
+		},
+		{
+			name:     "ACL on action restricts even if app includes subject",
+			userRole: model.USER_ROLE_EDITOR,
+			unitACL: []*model.UnitRoleRelation{
+				newAccessACLEntry(UNIT_TYPE_APP, TEST_APP_ID, model.USER_ROLE_EDITOR),
+				newAccessACLEntry(UNIT_TYPE_ACTION, TEST_ACTION_ID, model.USER_ROLE_ADMIN),
+			},
+			allowed: false,
+		},
+		{
+			name:     "owner is never restricted",
+			userRole: model.USER_ROLE_OWNER,
+			unitACL:  []*model.UnitRoleRelation{newAccessACLEntry(UNIT_TYPE_APP, TEST_APP_ID, model.USER_ROLE_ADMIN)},
+			allowed:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			attrg := newActionInAppAttributeGroup(c.userRole, c.unitACL, nil)
+			if allowed := attrg.CanAccess(ACTION_ACCESS_VIEW); allowed != c.allowed {
+				t.Fatalf("expected allowed %v, got %v", c.allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestUnitACLIsCheckedBeforeUnitOverrides(t *testing.T) {
+	unitACL := []*model.UnitRoleRelation{
+		{UnitType: UNIT_TYPE_APP, UnitID: TEST_APP_ID, Category: ATTRIBUTE_CATEGORY_DELETE, UserRole: model.USER_ROLE_ADMIN},
+	}
+	overrides := []*model.PermissionOverride{newActionDeleteOverride(model.PERMISSION_EFFECT_ALLOW, UNIT_TYPE_ACTION, TEST_ACTION_ID)}
+	attrg := newActionInAppAttributeGroup(model.USER_ROLE_VIEWER, unitACL, overrides)
+	if attrg.CanDelete(ACTION_DELETE) {
+		t.Fatal("expected the ACL inherited from app to deny the allow override on action")
+	}
+}
+
+func TestParentUnitTypesHaveNoParent(t *testing.T) {
+	for unitType, parentUnitTypes := range UnitParentTypes {
+		for parentUnitType := range parentUnitTypes {
+			if _, hit := UnitParentTypes[parentUnitType]; hit {
+				t.Fatalf("unit type %d is the parent of unit type %d but has parent itself", parentUnitType, unitType)
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package accesscontrol
+
+import "github.com/illacloud/illa-supervisor-backend/src/model"
+
+// the effective permissions are the union of the role and the user groups, so the groups only grant attributes.
+func (attrg *AttributeGroup) ApplyUserGroups(userGroups []*model.UserGroup, team *model.Team) {
+	for _, userGroup := range userGroups {
+		attrg.UserGroupIDs = append(attrg.UserGroupIDs, userGroup.ExportID())
+		if userGroup.HasUserRole() {
+			attrg.GroupUserRoles = append(attrg.GroupUserRoles, userGroup.ExportUserRole())
+			groupAttrg := NewAttributeGroupInTeam(userGroup.ExportUserRole(), attrg.UnitType, team)
+			attrg.mergeAttribute(GRANT_SOURCE_USER_GROUP, groupAttrg.Attribute)
+		}
+		for _, override := range userGroup.ExportPermissionOverrides() {
+			if override.IsAllow() {
+				attrg.appendPermissionOverrides(GRANT_SOURCE_USER_GROUP, []*model.PermissionOverride{override})
+			}
+		}
+	}
+}
+
+// the built-in role of user and the roles granted by user groups.
+func (attrg *AttributeGroup) ExportUserRoles() []int {
+	return append([]int{attrg.UserRole}, attrg.GroupUserRoles...)
+}
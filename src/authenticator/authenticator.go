@@ -2,8 +2,6 @@ package authenticator
 
 import (
 	"errors"
-	"fmt"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,10 +14,14 @@ import (
 	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
 )
 
+const PARAM_TEAM_ID = "teamID"
+
 type AuthClaims struct {
-	User   int       `json:"user"`
-	UUID   uuid.UUID `json:"uuid"`
-	Random string    `json:"rnd"`
+	User       int       `json:"user"`
+	UUID       uuid.UUID `json:"uuid"`
+	Random     string    `json:"rnd"`
+	AuthMethod string    `json:"amr"`
+	TwoFactor  bool      `json:"tfa"`
 	jwt.RegisteredClaims
 }
 
@@ -80,6 +82,28 @@ func ExtractExpiresAtFromToken(accessToken string) (*jwt.NumericDate, error) {
 	return claims.ExpiresAt, nil
 }
 
+func ExtractAccessSessionFromToken(accessToken string) (*model.AccessSession, error) {
+	authClaims := &AuthClaims{}
+	token, err := jwt.ParseWithClaims(accessToken, authClaims, func(token *jwt.Token) (interface{}, error) {
+		conf := config.GetInstance()
+		return []byte(conf.GetSecretKey()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*AuthClaims)
+	if !(ok && token.Valid) {
+		return nil, errors.New("invalied access token.")
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return model.NewAccessSession(claims.AuthMethod, claims.TwoFactor, issuedAt, claims.ExpiresAt.Time), nil
+}
+
 func (a *Authenticator) ValidateUser(user *model.User, id int, uid uuid.UUID) (bool, error) {
 	// refuse invalied user
 	emptyUUID, _ := uuid.Parse("00000000-0000-0000-0000-000000000000")
@@ -93,31 +117,6 @@ func (a *Authenticator) ValidateUser(user *model.User, id int, uid uuid.UUID) (b
 	return true, nil
 }
 
-func CreateAccessToken(id int, uid uuid.UUID) (string, error) {
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-	vCode := fmt.Sprintf("%06v", rnd.Int31n(10000))
-
-	claims := &AuthClaims{
-		User:   id,
-		UUID:   uid,
-		Random: vCode,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer: "ILLA",
-			ExpiresAt: &jwt.NumericDate{
-				Time: time.Now().Add(time.Hour * 24 * 7),
-			},
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	conf := config.GetInstance()
-	accessToken, err := token.SignedString([]byte(conf.GetSecretKey()))
-	if err != nil {
-		return "", err
-	}
-	return accessToken, nil
-}
-
 func (a *Authenticator) JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		accessToken := c.Request.Header["Authorization"]
@@ -144,11 +143,89 @@ func (a *Authenticator) JWTAuth() gin.HandlerFunc {
 			c.Set("userID", userID)
 		} else {
 			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		// enforce the IP allowlist and security policy of the team being accessed
+		if teamID, hit := ExtractTeamIDFromRequest(c); hit {
+			ipAllowed, errInValidateIP := a.DoesIPAllowedInTeam(teamID, c.ClientIP())
+			if errInValidateIP != nil {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			if !ipAllowed {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"errorCode":    403,
+					"errorFlag":    model.IP_NOT_ALLOWED_ERROR_FLAG,
+					"errorMessage": "your IP address is not allowed by this team.",
+				})
+				return
+			}
+			violation, errInValidatePolicy := a.ValidateTeamSecurityPolicy(teamID, userID, token)
+			if errInValidatePolicy != nil {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			if violation != nil {
+				c.AbortWithStatusJSON(http.StatusForbidden, violation.ExportForFeedback())
+				return
+			}
 		}
 		c.Next()
 	}
 }
 
+// the team id param only appears in team routes, the self-hosted supervisor serves the default team for all of them.
+func ExtractTeamIDFromRequest(c *gin.Context) (int, bool) {
+	if len(c.Param(PARAM_TEAM_ID)) == 0 {
+		return 0, false
+	}
+	return model.TEAM_DEFAULT_ID, true
+}
+
+func (a *Authenticator) DoesIPAllowedInTeam(teamID int, ip string) (bool, error) {
+	allowlist, errInRetrieveAllowlist := a.Storage.TeamIPAllowlistStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveAllowlist != nil {
+		return false, errInRetrieveAllowlist
+	}
+	return allowlist.DoesIPAllowed(ip), nil
+}
+
+// validate the session of access token against the team security policy, nil violation means the session is compliant.
+// the session activity is recorded when the team has an idle timeout.
+func (a *Authenticator) ValidateTeamSecurityPolicy(teamID int, userID int, accessToken string) (*model.SecurityPolicyViolation, error) {
+	policy, errInRetrievePolicy := a.Storage.TeamSecurityPolicyStorage.RetrieveByTeamID(teamID)
+	if errInRetrievePolicy != nil {
+		return nil, errInRetrievePolicy
+	}
+	if !policy.IsEnabled() {
+		return nil, nil
+	}
+	session, errInExtractSession := ExtractAccessSessionFromToken(accessToken)
+	if errInExtractSession != nil {
+		return nil, errInExtractSession
+	}
+	now := time.Now().UTC()
+	if !policy.HasIdleTimeout() {
+		return policy.Check(session, now), nil
+	}
+
+	// check idle timeout with the last activity of this token
+	tokenHash := model.ExportAccessTokenHash(accessToken)
+	lastActiveAt, errInGetLastActiveAt := a.Cache.JWTCache.GetUserJWTTokenLastActiveAt(userID, tokenHash)
+	if errInGetLastActiveAt != nil {
+		return nil, errInGetLastActiveAt
+	}
+	session.SetLastActiveAt(lastActiveAt)
+	if violation := policy.Check(session, now); violation != nil {
+		return violation, nil
+	}
+	if errInTouch := a.Cache.JWTCache.TouchUserJWTTokenActiveAt(userID, tokenHash, now); errInTouch != nil {
+		return nil, errInTouch
+	}
+	return nil, nil
+}
+
 func (a *Authenticator) ManualAuth(accessToken string) (bool, error) {
 	// fetch user
 	userID, userUID, extractErr := ExtractUserIDFromToken(accessToken)
@@ -188,3 +265,17 @@ func (a *Authenticator) DoesAccessTokenExpiredAtAvaliable(user *model.User, acce
 	// get history data
 	return a.Cache.JWTCache.DoesUserJWTTokenAvaliable(user, expiresAt)
 }
+
+// revoke all access tokens which issued before now for target user in target team
+func (a *Authenticator) RevokeAccessTokensInTeam(teamID int, userID int) error {
+	return a.Cache.JWTCache.RevokeUserJWTTokenInTeam(teamID, userID, time.Now())
+}
+
+// the token is compared by its issued at, so the token issued after revocation is avaliable at once.
+func (a *Authenticator) DoesAccessTokenAvaliableInTeam(teamID int, userID int, accessToken string) (bool, error) {
+	session, errInExtract := ExtractAccessSessionFromToken(accessToken)
+	if errInExtract != nil {
+		return false, errInExtract
+	}
+	return a.Cache.JWTCache.DoesUserJWTTokenAvaliableInTeam(teamID, userID, session.IssuedAt)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/illacloud/illa-supervisor-backend/src/driver/postgres"
 	"github.com/illacloud/illa-supervisor-backend/src/driver/redis"
 	"github.com/illacloud/illa-supervisor-backend/src/internalrouter"
+	"github.com/illacloud/illa-supervisor-backend/src/job"
 	"github.com/illacloud/illa-supervisor-backend/src/model"
 	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
 	"github.com/illacloud/illa-supervisor-backend/src/utils/cors"
@@ -21,18 +22,22 @@ import (
 )
 
 type Server struct {
-	engine *gin.Engine
-	router *internalrouter.Router
-	logger *zap.SugaredLogger
-	config *config.Config
+	engine                        *gin.Engine
+	router                        *internalrouter.Router
+	policyWatcher                 *job.PolicyWatcher
+	accessControlCacheInvalidator *job.AccessControlCacheInvalidator
+	logger                        *zap.SugaredLogger
+	config                        *config.Config
 }
 
-func NewServer(config *config.Config, engine *gin.Engine, router *internalrouter.Router, logger *zap.SugaredLogger) *Server {
+func NewServer(config *config.Config, engine *gin.Engine, router *internalrouter.Router, policyWatcher *job.PolicyWatcher, accessControlCacheInvalidator *job.AccessControlCacheInvalidator, logger *zap.SugaredLogger) *Server {
 	return &Server{
-		engine: engine,
-		config: config,
-		router: router,
-		logger: logger,
+		engine:                        engine,
+		config:                        config,
+		router:                        router,
+		policyWatcher:                 policyWatcher,
+		accessControlCacheInvalidator: accessControlCacheInvalidator,
+		logger:                        logger,
 	}
 }
 
@@ -78,7 +83,9 @@ func initServer() (*Server, error) {
 	a := authenticator.NewAuthenticator(storage, cache)
 	c := controller.NewController(storage, cache, drive, validator, a)
 	router := internalrouter.NewRouter(c, a)
-	server := NewServer(globalConfig, engine, router, sugaredLogger)
+	policyWatcher := job.NewPolicyWatcher(sugaredLogger)
+	accessControlCacheInvalidator := job.NewAccessControlCacheInvalidator(cache, sugaredLogger)
+	server := NewServer(globalConfig, engine, router, policyWatcher, accessControlCacheInvalidator, sugaredLogger)
 	return server, nil
 
 }
@@ -88,11 +95,27 @@ func (server *Server) Start() {
 
 	// init
 	gin.SetMode(server.config.ServerMode)
+	// init trusted proxies for client IP
+	if err := server.engine.SetTrustedProxies(server.config.GetTrustedProxies()); err != nil {
+		server.logger.Errorw("Error in startup, invalid trusted proxies", "err", err)
+		os.Exit(2)
+	}
+	// init access control policy
+	if err := server.policyWatcher.Load(); err != nil {
+		server.logger.Errorw("Error in startup, invalid access control policy", "err", err)
+		os.Exit(2)
+	}
 	// init cors
 	server.engine.Use(gin.CustomRecovery(recovery.CorsHandleRecovery))
 	server.engine.Use(cors.Cors())
 	server.router.RegisterRouters(server.engine)
 
+	// reload the access control policy in background
+	go server.policyWatcher.Run()
+
+	// invalidate the cached access control snapshots by supervisor events
+	go server.accessControlCacheInvalidator.Run()
+
 	err := server.engine.Run(server.config.ServerHost + ":" + server.config.InternalServerPort)
 	if err != nil {
 		server.logger.Errorw("Error in startup", "err", err)
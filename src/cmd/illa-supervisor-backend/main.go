@@ -8,6 +8,7 @@ import (
 	"github.com/illacloud/illa-supervisor-backend/src/driver/minio"
 	"github.com/illacloud/illa-supervisor-backend/src/driver/postgres"
 	"github.com/illacloud/illa-supervisor-backend/src/driver/redis"
+	"github.com/illacloud/illa-supervisor-backend/src/job"
 	"github.com/illacloud/illa-supervisor-backend/src/model"
 	"github.com/illacloud/illa-supervisor-backend/src/router"
 	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
@@ -21,18 +22,22 @@ import (
 )
 
 type Server struct {
-	engine *gin.Engine
-	router *router.Router
-	logger *zap.SugaredLogger
-	config *config.Config
+	engine        *gin.Engine
+	router        *router.Router
+	teamPurger    *job.TeamPurger
+	policyWatcher *job.PolicyWatcher
+	logger        *zap.SugaredLogger
+	config        *config.Config
 }
 
-func NewServer(config *config.Config, engine *gin.Engine, router *router.Router, logger *zap.SugaredLogger) *Server {
+func NewServer(config *config.Config, engine *gin.Engine, router *router.Router, teamPurger *job.TeamPurger, policyWatcher *job.PolicyWatcher, logger *zap.SugaredLogger) *Server {
 	return &Server{
-		engine: engine,
-		config: config,
-		router: router,
-		logger: logger,
+		engine:        engine,
+		config:        config,
+		router:        router,
+		teamPurger:    teamPurger,
+		policyWatcher: policyWatcher,
+		logger:        logger,
 	}
 }
 
@@ -79,7 +84,11 @@ func initServer() (*Server, error) {
 	a := authenticator.NewAuthenticator(storage, cache)
 	c := controller.NewController(storage, cache, drive, validator, a)
 	router := router.NewRouter(c, a)
-	server := NewServer(globalConfig, engine, router, sugaredLogger)
+
+	// init background job
+	teamPurger := job.NewTeamPurger(storage, cache, sugaredLogger)
+	policyWatcher := job.NewPolicyWatcher(sugaredLogger)
+	server := NewServer(globalConfig, engine, router, teamPurger, policyWatcher, sugaredLogger)
 	return server, nil
 
 }
@@ -89,11 +98,26 @@ func (server *Server) Start() {
 
 	// init
 	gin.SetMode(server.config.ServerMode)
+	// init trusted proxies for client IP
+	if err := server.engine.SetTrustedProxies(server.config.GetTrustedProxies()); err != nil {
+		server.logger.Errorw("Error in startup, invalid trusted proxies", "err", err)
+		os.Exit(2)
+	}
+	// init access control policy
+	if err := server.policyWatcher.Load(); err != nil {
+		server.logger.Errorw("Error in startup, invalid access control policy", "err", err)
+		os.Exit(2)
+	}
 	// init cors
 	server.engine.Use(gin.CustomRecovery(recovery.CorsHandleRecovery))
 	server.engine.Use(cors.Cors())
 	server.router.RegisterRouters(server.engine)
 
+	// purge the expired archived teams in background
+	go server.teamPurger.Run()
+	// reload the access control policy in background
+	go server.policyWatcher.Run()
+
 	err := server.engine.Run(server.config.ServerHost + ":" + server.config.ServerPort)
 	if err != nil {
 		server.logger.Errorw("Error in startup", "err", err)
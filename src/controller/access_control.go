@@ -1,6 +1,10 @@
 package controller
 
 import (
+	"errors"
+	"log"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
@@ -8,11 +12,268 @@ import (
 	"github.com/illacloud/illa-supervisor-backend/src/model"
 )
 
+// build attribute group for the team member with the live team context, the error will feedback by this method.
+func (controller *Controller) BuildAttributeGroup(c *gin.Context, teamMember *model.TeamMember, unitType int) (*accesscontrol.AttributeGroup, error) {
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamMember.TeamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, errInRetrieveTeam
+	}
+	return controller.BuildAttributeGroupInTeam(c, teamMember, unitType, team)
+}
+
+// build attribute group with the team retrieved by caller, the user groups and custom roles of team member will be applied.
+func (controller *Controller) BuildAttributeGroupInTeam(c *gin.Context, teamMember *model.TeamMember, unitType int, team *model.Team) (*accesscontrol.AttributeGroup, error) {
+	subject, errInRetrieveSubject := controller.retrieveTeamMemberSubject(c, teamMember, team)
+	if errInRetrieveSubject != nil {
+		return nil, errInRetrieveSubject
+	}
+	return subject.NewAttributeGroup(unitType), nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) retrieveTeamMemberSubject(c *gin.Context, teamMember *model.TeamMember, team *model.Team) (*accesscontrol.Subject, error) {
+	userGroups, errInRetrieveUserGroups := controller.Storage.UserGroupStorage.RetrieveByTeamIDAndTeamMemberID(teamMember.TeamID, teamMember.ExportID())
+	if errInRetrieveUserGroups != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user groups of team member error: "+errInRetrieveUserGroups.Error())
+		return nil, errInRetrieveUserGroups
+	}
+	roles, errInRetrieveRoles := controller.Storage.RoleStorage.RetrieveByTeamIDAndUserID(teamMember.TeamID, teamMember.UserID)
+	if errInRetrieveRoles != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get roles of team member error: "+errInRetrieveRoles.Error())
+		return nil, errInRetrieveRoles
+	}
+	subject := accesscontrol.NewTeamMemberSubject(team, teamMember, userGroups, roles)
+	if errInRetrieveTemporaryGrants := controller.retrieveTemporaryGrantsForSubject(c, subject); errInRetrieveTemporaryGrants != nil {
+		return nil, errInRetrieveTemporaryGrants
+	}
+	return subject, nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) retrieveTemporaryGrantsForSubject(c *gin.Context, subject *accesscontrol.Subject) error {
+	teamID := subject.TeamMember.TeamID
+	temporaryGrants, errInRetrieveTemporaryGrants := controller.Storage.TemporaryGrantStorage.RetrieveUnexpiredByTeamIDAndUserID(teamID, subject.TeamMember.UserID, time.Now().UTC())
+	if errInRetrieveTemporaryGrants != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEMPORARY_GRANT, "get temporary grants of team member error: "+errInRetrieveTemporaryGrants.Error())
+		return errInRetrieveTemporaryGrants
+	}
+	roles := make([]*model.Role, 0)
+	if roleIDs := model.PickUpRoleIDsInTemporaryGrants(temporaryGrants); len(roleIDs) > 0 {
+		var errInRetrieveRoles error
+		roles, errInRetrieveRoles = controller.Storage.RoleStorage.RetrieveByTeamIDAndIDs(teamID, roleIDs)
+		if errInRetrieveRoles != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get roles of temporary grants error: "+errInRetrieveRoles.Error())
+			return errInRetrieveRoles
+		}
+	}
+	subject.SetTemporaryGrants(temporaryGrants, roles)
+	return nil
+}
+
+// notify other units and supervisor replicas, the cached access control snapshots of team will be invalidated.
+func (controller *Controller) publishTeamAccessControlChanged(teamID int, operatorID int) {
+	event := model.NewSupervisorTeamEventByTeamID(model.SUPERVISOR_EVENT_TEAM_ACCESS_CONTROL_CHANGED, teamID, operatorID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team access control changed event failed: " + errInPublish.Error())
+	}
+}
+
+// set the unit being checked and load its ancestors and the ACL of unit chain, the error will feedback by this method.
+func (controller *Controller) SetUnitForAccessControl(c *gin.Context, attrg *accesscontrol.AttributeGroup, teamID int, unitID int) error {
+	unitContext, errInRetrieveUnitContext := controller.retrieveUnitContextForAccessControl(c, teamID, attrg.UnitType, unitID)
+	if errInRetrieveUnitContext != nil {
+		return errInRetrieveUnitContext
+	}
+	unitContext.applyTo(attrg)
+	return nil
+}
+
+// the unit context holds the ancestors of unit and the ACL of whole unit chain.
+type unitContextForAccessControl struct {
+	unitID    int
+	ancestors []*model.UnitReference
+	unitACL   []*model.UnitRoleRelation
+}
+
+func (uc *unitContextForAccessControl) applyTo(attrg *accesscontrol.AttributeGroup) {
+	attrg.SetUnitID(uc.unitID)
+	attrg.SetUnitAncestors(uc.ancestors)
+	attrg.SetUnitACL(uc.unitACL)
+}
+
+// the team-wide check has empty unit context, the unit context is cached since it is loaded on every unit check.
+// the error will feedback by this method.
+func (controller *Controller) retrieveUnitContextForAccessControl(c *gin.Context, teamID int, unitType int, unitID int) (*unitContextForAccessControl, error) {
+	unitContext := &unitContextForAccessControl{
+		unitID: unitID,
+	}
+	if unitID == accesscontrol.DEFAULT_UNIT_ID {
+		return unitContext, nil
+	}
+	cachedUnitContext, errInGetUnitContext := controller.Cache.AccessControlCache.GetUnitContext(teamID, unitType, unitID)
+	if errInGetUnitContext != nil {
+		log.Println("get access control unit context failed: " + errInGetUnitContext.Error())
+	}
+	if cachedUnitContext == nil {
+		generation, errInGetGeneration := controller.Cache.AccessControlCache.GetGeneration(teamID)
+		if errInGetGeneration != nil {
+			log.Println("get access control generation failed: " + errInGetGeneration.Error())
+		}
+		var errInRetrieveUnitContext error
+		cachedUnitContext, errInRetrieveUnitContext = controller.retrieveUnitContextForAccessControlFromStorage(c, teamID, unitType, unitID)
+		if errInRetrieveUnitContext != nil {
+			return nil, errInRetrieveUnitContext
+		}
+		if generation != nil {
+			if errInSetUnitContext := controller.Cache.AccessControlCache.SetUnitContext(teamID, unitType, unitID, cachedUnitContext, generation); errInSetUnitContext != nil {
+				log.Println("set access control unit context failed: " + errInSetUnitContext.Error())
+			}
+		}
+	}
+	unitContext.ancestors = cachedUnitContext.Ancestors
+	unitContext.unitACL = cachedUnitContext.UnitACL
+	return unitContext, nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) retrieveUnitContextForAccessControlFromStorage(c *gin.Context, teamID int, unitType int, unitID int) (*model.AccessControlUnitContext, error) {
+	unit := model.NewUnitReference(unitType, unitID)
+	ancestors, errInRetrieveAncestors := controller.Storage.UnitRelationStorage.RetrieveAncestorsByTeamIDAndUnit(teamID, unit)
+	if errInRetrieveAncestors != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_RELATION, "get unit ancestors error: "+errInRetrieveAncestors.Error())
+		return nil, errInRetrieveAncestors
+	}
+	unitACL, errInRetrieveUnitACL := controller.Storage.UnitRoleRelationStorage.RetrieveByTeamIDAndUnits(teamID, append([]*model.UnitReference{unit}, ancestors...))
+	if errInRetrieveUnitACL != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_ACL, "get unit ACL error: "+errInRetrieveUnitACL.Error())
+		return nil, errInRetrieveUnitACL
+	}
+	return model.NewAccessControlUnitContext(ancestors, unitACL), nil
+}
+
+// build attribute group for internal access control request, the error will feedback by this method.
+func (controller *Controller) BuildAttributeGroupForAccessControl(c *gin.Context, teamID int, userID int, authorizationToken string, unitType int) (*accesscontrol.AttributeGroup, error) {
+	subject, errInRetrieveSubject := controller.RetrieveSubjectForAccessControl(c, teamID, userID, authorizationToken)
+	if errInRetrieveSubject != nil {
+		return nil, errInRetrieveSubject
+	}
+	return subject.NewAttributeGroup(unitType), nil
+}
+
+// resolve the user of internal access control request once, the error will feedback by this method.
+// the IP allowlist, team member status, access token and security policy are always validated, only the storage data is cached.
+func (controller *Controller) RetrieveSubjectForAccessControl(c *gin.Context, teamID int, userID int, authorizationToken string) (*accesscontrol.Subject, error) {
+	subject, errInRetrieveSubject := controller.retrieveCachedSubjectForAccessControl(c, teamID, userID)
+	if errInRetrieveSubject != nil {
+		return nil, errInRetrieveSubject
+	}
+	if errInValidateIP := controller.validateEndUserIP(c, subject.IPAllowlist); errInValidateIP != nil {
+		return nil, errInValidateIP
+	}
+	if subject.IsAnonymous() {
+		return subject, nil
+	}
+	if errInValidateStatus := controller.validateTeamMemberStatus(c, subject.TeamMember); errInValidateStatus != nil {
+		return nil, errInValidateStatus
+	}
+
+	// check if the access token was revoked in this team
+	tokenAvaliable, errInValidateToken := controller.Authenticator.DoesAccessTokenAvaliableInTeam(teamID, userID, authorizationToken)
+	if errInValidateToken != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate account failed: "+errInValidateToken.Error())
+		return nil, errInValidateToken
+	}
+	if !tokenAvaliable {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate account failed: access token has been revoked in this team.")
+		return nil, errors.New("access token has been revoked in this team.")
+	}
+
+	// the other units authorize with this subject, so the session must comply with the team security policy
+	if errInValidatePolicy := controller.validateTeamSecurityPolicy(c, teamID, userID, authorizationToken); errInValidatePolicy != nil {
+		return nil, errInValidatePolicy
+	}
+	return subject, nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) validateTeamSecurityPolicy(c *gin.Context, teamID int, userID int, authorizationToken string) error {
+	violation, errInValidatePolicy := controller.Authenticator.ValidateTeamSecurityPolicy(teamID, userID, authorizationToken)
+	if errInValidatePolicy != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate team security policy failed: "+errInValidatePolicy.Error())
+		return errInValidatePolicy
+	}
+	if violation != nil {
+		controller.FeedbackSecurityPolicyViolation(c, violation)
+		return errors.New(violation.ErrorMessage)
+	}
+	return nil
+}
+
+// the cache failure falls back to storage, only the active team member will be cached.
+// the generation is read before loading, so the snapshot loaded before an invalidation will not be cached.
+// the error will feedback by this method.
+func (controller *Controller) retrieveCachedSubjectForAccessControl(c *gin.Context, teamID int, userID int) (*accesscontrol.Subject, error) {
+	snapshot, errInGetSnapshot := controller.Cache.AccessControlCache.Get(teamID, userID)
+	if errInGetSnapshot != nil {
+		log.Println("get access control snapshot failed: " + errInGetSnapshot.Error())
+	}
+	if snapshot != nil {
+		return accesscontrol.NewSubjectBySnapshot(snapshot), nil
+	}
+	generation, errInGetGeneration := controller.Cache.AccessControlCache.GetGeneration(teamID)
+	if errInGetGeneration != nil {
+		log.Println("get access control generation failed: " + errInGetGeneration.Error())
+	}
+	subject, errInRetrieveSubject := controller.retrieveSubjectForAccessControlFromStorage(c, teamID, userID)
+	if errInRetrieveSubject != nil {
+		return nil, errInRetrieveSubject
+	}
+	if generation == nil || (!subject.IsAnonymous() && !subject.TeamMember.IsStatusOK()) {
+		return subject, nil
+	}
+	if errInSetSnapshot := controller.Cache.AccessControlCache.Set(teamID, userID, subject.ExportSnapshot(), generation); errInSetSnapshot != nil {
+		log.Println("set access control snapshot failed: " + errInSetSnapshot.Error())
+	}
+	return subject, nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) retrieveSubjectForAccessControlFromStorage(c *gin.Context, teamID int, userID int) (*accesscontrol.Subject, error) {
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, errInRetrieveTeam
+	}
+	allowlist, errInRetrieveAllowlist := controller.Storage.TeamIPAllowlistStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveAllowlist != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_IP_ALLOWLIST, "get team IP allowlist error: "+errInRetrieveAllowlist.Error())
+		return nil, errInRetrieveAllowlist
+	}
+	subject := accesscontrol.NewAnonymousSubject(team)
+	if userID != model.USER_ROLE_ANONYMOUS {
+		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+		if errInRetrieveTeamMember != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
+			return nil, errInRetrieveTeamMember
+		}
+		var errInRetrieveSubject error
+		subject, errInRetrieveSubject = controller.retrieveTeamMemberSubject(c, teamMember, team)
+		if errInRetrieveSubject != nil {
+			return nil, errInRetrieveSubject
+		}
+	}
+	subject.SetIPAllowlist(allowlist)
+	return subject, nil
+}
+
+// validate account with the security policy of the team being accessed, the self-hosted supervisor serves the default team only.
 func (controller *Controller) ValidateAccount(c *gin.Context) {
 	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
 	if errInGetAuthorizationToken != nil {
 		return
 	}
+	teamID := model.TEAM_DEFAULT_ID
 
 	// validate request data
 	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, authorizationToken)
@@ -26,6 +287,16 @@ func (controller *Controller) ValidateAccount(c *gin.Context) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate account failed: "+err.Error())
 		return
 	}
+	userID, _, errInGetUserID := authenticator.ExtractUserIDFromToken(authorizationToken)
+	if errInGetUserID != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate account failed: "+errInGetUserID.Error())
+		return
+	}
+
+	// validate team security policy
+	if errInValidatePolicy := controller.validateTeamSecurityPolicy(c, teamID, userID, authorizationToken); errInValidatePolicy != nil {
+		return
+	}
 
 	// feedback
 	controller.FeedbackOK(c, nil)
@@ -90,19 +361,15 @@ func (controller *Controller) CanAccess(c *gin.Context) {
 	}
 
 	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupForAccessControl(c, teamID, userID, authorizationToken, unitType)
+	if errInBuildAttributeGroup != nil {
+		return
 	}
 
-	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
+	// check attribute with the ACL of target unit
+	if errInSetUnit := controller.SetUnitForAccessControl(c, attrg, teamID, unitID); errInSetUnit != nil {
+		return
+	}
 	if !attrg.CanAccess(attributeID) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -144,19 +411,15 @@ func (controller *Controller) CanManage(c *gin.Context) {
 	}
 
 	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupForAccessControl(c, teamID, userID, authorizationToken, unitType)
+	if errInBuildAttributeGroup != nil {
+		return
 	}
 
-	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
+	// check attribute with the ACL of target unit
+	if errInSetUnit := controller.SetUnitForAccessControl(c, attrg, teamID, unitID); errInSetUnit != nil {
+		return
+	}
 	if !attrg.CanManage(attributeID) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -198,19 +461,15 @@ func (controller *Controller) CanManageSpecial(c *gin.Context) {
 	}
 
 	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupForAccessControl(c, teamID, userID, authorizationToken, unitType)
+	if errInBuildAttributeGroup != nil {
+		return
 	}
 
-	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
+	// check attribute with the ACL of target unit
+	if errInSetUnit := controller.SetUnitForAccessControl(c, attrg, teamID, unitID); errInSetUnit != nil {
+		return
+	}
 	if !attrg.CanManageSpecial(attributeID) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -256,19 +515,15 @@ func (controller *Controller) CanModify(c *gin.Context) {
 	}
 
 	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupForAccessControl(c, teamID, userID, authorizationToken, unitType)
+	if errInBuildAttributeGroup != nil {
+		return
 	}
 
-	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
+	// check attribute with the ACL of target unit
+	if errInSetUnit := controller.SetUnitForAccessControl(c, attrg, teamID, unitID); errInSetUnit != nil {
+		return
+	}
 	if !attrg.CanModify(attributeID, fromID, toID) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -310,19 +565,15 @@ func (controller *Controller) CanDelete(c *gin.Context) {
 	}
 
 	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupForAccessControl(c, teamID, userID, authorizationToken, unitType)
+	if errInBuildAttributeGroup != nil {
+		return
 	}
 
-	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
+	// check attribute with the ACL of target unit
+	if errInSetUnit := controller.SetUnitForAccessControl(c, attrg, teamID, unitID); errInSetUnit != nil {
+		return
+	}
 	if !attrg.CanDelete(attributeID) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/authenticator"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+type batchCheckUnit struct {
+	unitType int
+	unitID   int
+}
+
+// check many attributes with one request, the team member will be resolved only once.
+// the request token is generated by the authorization token, team ID and the sha256 hex of request body.
+func (controller *Controller) BatchCheck(c *gin.Context) {
+	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
+	teamID := model.TEAM_DEFAULT_ID
+	userID := model.USER_ROLE_ANONYMOUS
+	var errInGetUserID error
+	if authorizationToken != accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		userID, _, errInGetUserID = authenticator.ExtractUserIDFromToken(authorizationToken)
+	}
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	if errInGetAuthorizationToken != nil || errInGetUserID != nil || errInGetTeamIDString != nil {
+		return
+	}
+
+	body, errInReadBody := io.ReadAll(c.Request.Body)
+	if errInReadBody != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "read request body error: "+errInReadBody.Error())
+		return
+	}
+
+	// validate request data, the request token covers the body hash too
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, authorizationToken, teamIDString, model.ExportBatchCheckRequestBodyHash(body))
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewBatchCheckRequest()
+	if err := json.Unmarshal(body, &req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	subject, errInRetrieveSubject := controller.RetrieveSubjectForAccessControl(c, teamID, userID, authorizationToken)
+	if errInRetrieveSubject != nil {
+		return
+	}
+
+	// check attributes, the attribute groups and unit contexts are shared by checks of same unit type and unit
+	attrgs := make(map[int]*accesscontrol.AttributeGroup)
+	unitContexts := make(map[batchCheckUnit]*unitContextForAccessControl)
+	resp := model.NewBatchCheckResponse(len(req.Checks))
+	for index, check := range req.Checks {
+		attrg, hit := attrgs[check.UnitType]
+		if !hit {
+			attrg = subject.NewAttributeGroup(check.UnitType)
+			attrgs[check.UnitType] = attrg
+		}
+		unit := batchCheckUnit{unitType: check.UnitType, unitID: check.ExportUnitIDInInt()}
+		unitContext, hit := unitContexts[unit]
+		if !hit {
+			var errInRetrieveUnitContext error
+			unitContext, errInRetrieveUnitContext = controller.retrieveUnitContextForAccessControl(c, teamID, unit.unitType, unit.unitID)
+			if errInRetrieveUnitContext != nil {
+				return
+			}
+			unitContexts[unit] = unitContext
+		}
+		unitContext.applyTo(attrg)
+		resp.Append(index, check, canInBatchCheck(attrg, check))
+	}
+
+	// feedback
+	controller.FeedbackOK(c, resp)
+	return
+}
+
+// the modify check only works in manage category.
+func canInBatchCheck(attrg *accesscontrol.AttributeGroup, check *model.BatchCheckItemRequest) bool {
+	if check.IsModifyCheck() {
+		if check.Category != accesscontrol.ATTRIBUTE_CATEGORY_MANAGE {
+			return false
+		}
+		return attrg.CanModify(check.Attribute, check.FromID, check.ToID)
+	}
+	return attrg.CanInCategory(check.Category, check.Attribute)
+}
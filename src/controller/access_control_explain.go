@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/authenticator"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+// get all team-wide attributes the user can do, grouped by unit type and category.
+func (controller *Controller) GetEffectivePermissions(c *gin.Context) {
+	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
+	teamID := model.TEAM_DEFAULT_ID
+	userID := model.USER_ROLE_ANONYMOUS
+	var errInGetUserID error
+	if authorizationToken != accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		userID, _, errInGetUserID = authenticator.ExtractUserIDFromToken(authorizationToken)
+	}
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	if errInGetAuthorizationToken != nil || errInGetUserID != nil || errInGetTeamIDString != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, authorizationToken, teamIDString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// validate user
+	subject, errInRetrieveSubject := controller.RetrieveSubjectForAccessControl(c, teamID, userID, authorizationToken)
+	if errInRetrieveSubject != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, accesscontrol.NewEffectivePermissions(subject))
+	return
+}
+
+// explain why a single check is allowed or denied, this endpoint never feedback ERROR_FLAG_ACCESS_DENIED.
+func (controller *Controller) ExplainAccessControl(c *gin.Context) {
+	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
+	teamID := model.TEAM_DEFAULT_ID
+	userID := model.USER_ROLE_ANONYMOUS
+	var errInGetUserID error
+	if authorizationToken != accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		userID, _, errInGetUserID = authenticator.ExtractUserIDFromToken(authorizationToken)
+	}
+	unitType, errInGetUnitType := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	category, errInGetCategory := controller.GetMagicIntParamFromRequest(c, PARAM_CATEGORY)
+	attributeID, errInGetAttributeID := controller.GetMagicIntParamFromRequest(c, PARAM_ATTRIBUTE_ID)
+	if errInGetAuthorizationToken != nil || errInGetUserID != nil || errInGetUnitType != nil || errInGetUnitID != nil || errInGetCategory != nil || errInGetAttributeID != nil {
+		return
+	}
+
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	unitTypeString, errInGetUnitTypeString := controller.GetStringParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitIDString, errInGetUnitIDString := controller.GetStringParamFromRequest(c, PARAM_UNIT_ID)
+	categoryString, errInGetCategoryString := controller.GetStringParamFromRequest(c, PARAM_CATEGORY)
+	attributeIDString, errInGetAttributeIDString := controller.GetStringParamFromRequest(c, PARAM_ATTRIBUTE_ID)
+	if errInGetTeamIDString != nil || errInGetUnitTypeString != nil || errInGetUnitIDString != nil || errInGetCategoryString != nil || errInGetAttributeIDString != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, authorizationToken, teamIDString, unitTypeString, unitIDString, categoryString, attributeIDString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// validate user
+	subject, errInRetrieveSubject := controller.RetrieveSubjectForAccessControl(c, teamID, userID, authorizationToken)
+	if errInRetrieveSubject != nil {
+		return
+	}
+	attrg := subject.NewAttributeGroup(unitType)
+	if errInSetUnit := controller.SetUnitForAccessControl(c, attrg, teamID, unitID); errInSetUnit != nil {
+		return
+	}
+
+	// explain
+	decision := attrg.Explain(category, attributeID)
+	decision.SetTeamPermission(subject.Team.ExportTeamPermission())
+
+	// feedback
+	controller.FeedbackOK(c, decision)
+	return
+}
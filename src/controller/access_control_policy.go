@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+)
+
+// feedback the access control policy in use, it is read-only.
+func (controller *Controller) GetAccessControlPolicy(c *gin.Context) {
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, accesscontrol.GetPolicy())
+	return
+}
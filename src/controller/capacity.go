@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetTeamCapacityByInternalRequest(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	if errInGetTeamIDString != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, teamIDString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// feedback
+	controller.feedbackTeamCapacity(c, teamID)
+	return
+}
+
+func (controller *Controller) UpdateTeamCapacityByInternalRequest(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	if errInGetTeamIDString != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, teamIDString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateCapacityRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// update limits, the seats already taken will not be released
+	capacity := model.NewCapacity(teamID)
+	capacity.UpdateByRequest(req)
+	if errInUpdate := controller.Storage.CapacityStorage.UpsertLimits(capacity); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_CAPACITY, "update team capacity error: "+errInUpdate.Error())
+		return
+	}
+
+	// feedback
+	controller.feedbackTeamCapacity(c, teamID)
+	return
+}
+
+func (controller *Controller) ReserveTeamCapacity(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	if errInGetTeamIDString != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, teamIDString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewReserveCapacityRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// reserve
+	errInReserve := controller.Storage.CapacityStorage.ReserveStorage(teamID, req.ExportStorage())
+	if errors.Is(errInReserve, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team storage quota exceeded.")
+		return
+	}
+	if errInReserve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_CAPACITY, "reserve team capacity error: "+errInReserve.Error())
+		return
+	}
+
+	// feedback
+	controller.feedbackTeamCapacity(c, teamID)
+	return
+}
+
+func (controller *Controller) ReleaseTeamCapacity(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	if errInGetTeamIDString != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, teamIDString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewReserveCapacityRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// release
+	if errInRelease := controller.Storage.CapacityStorage.ReleaseStorage(teamID, req.ExportStorage()); errInRelease != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_CAPACITY, "release team capacity error: "+errInRelease.Error())
+		return
+	}
+
+	// feedback
+	controller.feedbackTeamCapacity(c, teamID)
+	return
+}
+
+// retrieve team capacity with the seats taken, the error will feedback by this method.
+func (controller *Controller) retrieveTeamCapacity(c *gin.Context, teamID int) (*model.Capacity, int, int, error) {
+	capacity, errInRetrieveCapacity := controller.Storage.CapacityStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveCapacity != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_CAPACITY, "get team capacity error: "+errInRetrieveCapacity.Error())
+		return nil, 0, 0, errInRetrieveCapacity
+	}
+	members, editors, errInCountSeats := controller.Storage.CapacityStorage.CountSeatsByTeamID(teamID)
+	if errInCountSeats != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_CAPACITY, "count team seats error: "+errInCountSeats.Error())
+		return nil, 0, 0, errInCountSeats
+	}
+	return capacity, members, editors, nil
+}
+
+func (controller *Controller) feedbackTeamCapacity(c *gin.Context, teamID int) {
+	capacity, members, editors, errInRetrieve := controller.retrieveTeamCapacity(c, teamID)
+	if errInRetrieve != nil {
+		return
+	}
+	controller.FeedbackOK(c, model.NewCapacityResponse(capacity, members, editors))
+}
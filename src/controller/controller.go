@@ -3,6 +3,7 @@ package controller
 import (
 	"github.com/illacloud/illa-supervisor-backend/src/authenticator"
 	"github.com/illacloud/illa-supervisor-backend/src/model"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/dnsresolver"
 	"github.com/illacloud/illa-supervisor-backend/src/utils/tokenvalidator"
 )
 
@@ -12,6 +13,7 @@ type Controller struct {
 	Drive                 *model.Drive
 	RequestTokenValidator *tokenvalidator.RequestTokenValidator
 	Authenticator         *authenticator.Authenticator
+	DNSResolver           dnsresolver.Resolver
 }
 
 func NewController(storage *model.Storage, cache *model.Cache, drive *model.Drive, validator *tokenvalidator.RequestTokenValidator, auth *authenticator.Authenticator) *Controller {
@@ -21,5 +23,6 @@ func NewController(storage *model.Storage, cache *model.Cache, drive *model.Driv
 		Drive:                 drive,
 		RequestTokenValidator: validator,
 		Authenticator:         auth,
+		DNSResolver:           dnsresolver.NewResolverByGlobalConfig(),
 	}
 }
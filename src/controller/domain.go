@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetAllDomains(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get domains
+	domains, errInRetrieve := controller.Storage.DomainStorage.RetrieveByTeamID(teamID)
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domains error: "+errInRetrieve.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllDomainsResponse(domains))
+	return
+}
+
+func (controller *Controller) CreateDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewCreateDomainRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if model.IsSystemDomainOrSubDomain(req.ExportUserDomain()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "the user domain can not be the system domain, please use system domain prefix instead.")
+		return
+	}
+	if model.IsReservedSystemDomainPrefix(req.ExportSystemDomainPrefix()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "the system domain prefix is reserved.")
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManageDomain(req.Category) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// check if the domain already taken
+	domain := model.NewDomainByCreateRequest(teamID, userID, req)
+	if errInValidateAvailable := controller.validateDomainAvailable(c, domain); errInValidateAvailable != nil {
+		return
+	}
+
+	// create
+	if _, errInCreate := controller.Storage.DomainStorage.Create(domain); errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_DOMAIN, "create domain error: "+errInCreate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewDomainResponse(domain))
+	return
+}
+
+func (controller *Controller) VerifyDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	domainID, errInGetDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_DOMAIN_ID)
+	if errInGetDomainID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get domain
+	domain, errInRetrieve := controller.Storage.DomainStorage.RetrieveByTeamIDAndID(teamID, domainID)
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieve.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManageDomain(domain.ExportCategory()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+	if !domain.HasUserDomain() {
+		controller.FeedbackOK(c, model.NewDomainResponse(domain))
+		return
+	}
+
+	// the expired claim should be created again
+	if domain.IsClaimExpired(time.Now().UTC()) {
+		if errInDelete := controller.Storage.DomainStorage.DeleteByTeamIDAndID(teamID, domain.ExportID()); errInDelete != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_DOMAIN, "delete expired domain error: "+errInDelete.Error())
+			return
+		}
+		controller.FeedbackBadRequest(c, ERROR_FLAG_DOMAIN_CLAIM_EXPIRED, "the domain was not verified in time, please add it again.")
+		return
+	}
+
+	// the domain may be verified by other team during this claim
+	if !domain.IsResolved() {
+		if errInValidateAvailable := controller.validateDomainAvailable(c, domain); errInValidateAvailable != nil {
+			return
+		}
+	}
+
+	// check the DNS records, the resolve status will be recorded even if failed
+	resolved := controller.resolveDomain(domain)
+	if errInUpdate := controller.Storage.DomainStorage.UpdateResolveStatus(domain); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN, "update domain error: "+errInUpdate.Error())
+		return
+	}
+	if !resolved {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_DOMAIN_RESOLVE_FAILED, "the verification TXT record not matched, the DNS changes may take a while to take effect.")
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewDomainResponse(domain))
+	return
+}
+
+func (controller *Controller) DeleteDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	domainID, errInGetDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_DOMAIN_ID)
+	if errInGetDomainID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get domain
+	domain, errInRetrieve := controller.Storage.DomainStorage.RetrieveByTeamIDAndID(teamID, domainID)
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieve.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanDeleteDomain(domain.ExportCategory()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// delete
+	if errInDelete := controller.Storage.DomainStorage.DeleteByTeamIDAndID(teamID, domain.ExportID()); errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_DOMAIN, "delete domain error: "+errInDelete.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) GetTargetTeamByHost(c *gin.Context) {
+	hostString, errInGetHost := controller.GetStringParamFromRequest(c, PARAM_HOST)
+	if errInGetHost != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, hostString)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// the system sub domain matches by prefix, others match the user domain
+	host := model.NormalizeHost(hostString)
+	var domain *model.Domain
+	var errInRetrieveDomain error
+	if systemDomainPrefix := model.ExtractSystemDomainPrefixFromHost(host); systemDomainPrefix != "" {
+		domain, errInRetrieveDomain = controller.Storage.DomainStorage.RetrieveResolvedBySystemDomainPrefix(systemDomainPrefix)
+	} else {
+		domain, errInRetrieveDomain = controller.Storage.DomainStorage.RetrieveResolvedByUserDomain(host)
+	}
+	if errInRetrieveDomain != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieveDomain.Error())
+		return
+	}
+
+	// fetch target team info
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(domain.TeamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTargetTeamByHostResponse(team, domain))
+	return
+}
+
+// the TXT record proves the ownership, the CNAME record is only checked for routing.
+func (controller *Controller) resolveDomain(domain *model.Domain) bool {
+	cname, errInLookupCNAME := controller.DNSResolver.LookupCNAME(domain.UserDomain)
+	domain.SetCNAMEMatched(errInLookupCNAME == nil && domain.DoesCNAMEMatch(cname))
+	records, errInLookupTXT := controller.DNSResolver.LookupTXT(model.DNS_VERIFICATION_RECORD_PREFIX + domain.UserDomain)
+	if errInLookupTXT == nil && domain.DoesTXTRecordsMatch(records) {
+		domain.Resolve()
+		return true
+	}
+	domain.FailToResolve()
+	return false
+}
+
+// the user domain and system domain prefix can not be taken by other resolved domain, the error will feedback by this method.
+func (controller *Controller) validateDomainAvailable(c *gin.Context, domain *model.Domain) error {
+	if domain.HasUserDomain() {
+		exists, errInCheck := controller.Storage.DomainStorage.DoesResolvedUserDomainExist(domain.UserDomain, domain.ExportID())
+		if errInCheck != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "check user domain error: "+errInCheck.Error())
+			return errInCheck
+		}
+		if exists {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_DOMAIN_ALREADY_EXISTS, "the user domain already taken.")
+			return errors.New("the user domain already taken.")
+		}
+	}
+	if domain.HasSystemDomainPrefix() {
+		exists, errInCheck := controller.Storage.DomainStorage.DoesResolvedSystemDomainPrefixExist(domain.SystemDomainPrefix, domain.ExportID())
+		if errInCheck != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "check system domain prefix error: "+errInCheck.Error())
+			return errInCheck
+		}
+		if exists {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_DOMAIN_ALREADY_EXISTS, "the system domain prefix already taken.")
+			return errors.New("the system domain prefix already taken.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetAllEmailDomains(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateEmailDomainManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get email domains
+	emailDomains, errInRetrieve := controller.Storage.EmailDomainStorage.RetrieveByTeamID(teamID)
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_EMAIL_DOMAIN, "get email domains error: "+errInRetrieve.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllEmailDomainsResponse(emailDomains))
+	return
+}
+
+func (controller *Controller) CreateEmailDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewCreateEmailDomainRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateEmailDomainManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateUserRole := controller.validateEmailDomainUserRole(c, teamMember, team, req.UserRole); errInValidateUserRole != nil {
+		return
+	}
+
+	// check domain
+	exists, errInCheckDomain := controller.Storage.EmailDomainStorage.DoesDomainExist(teamID, req.ExportDomain())
+	if errInCheckDomain != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_EMAIL_DOMAIN, "check email domain error: "+errInCheckDomain.Error())
+		return
+	}
+	if exists {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_EMAIL_DOMAIN_ALREADY_EXISTS, "email domain already exists in this team.")
+		return
+	}
+
+	// create, the domain should be verified before auto-join
+	emailDomain := model.NewEmailDomainByCreateRequest(teamID, userID, req)
+	if _, errInCreate := controller.Storage.EmailDomainStorage.Create(emailDomain); errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_EMAIL_DOMAIN, "create email domain error: "+errInCreate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewEmailDomainResponse(emailDomain))
+	return
+}
+
+func (controller *Controller) UpdateEmailDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	emailDomainID, errInGetEmailDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_EMAIL_DOMAIN_ID)
+	if errInGetEmailDomainID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateEmailDomainRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateEmailDomainManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateUserRole := controller.validateEmailDomainUserRole(c, teamMember, team, req.UserRole); errInValidateUserRole != nil {
+		return
+	}
+
+	// get email domain
+	emailDomain, errInRetrieve := controller.Storage.EmailDomainStorage.RetrieveByTeamIDAndID(teamID, emailDomainID)
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_EMAIL_DOMAIN, "get email domain error: "+errInRetrieve.Error())
+		return
+	}
+
+	// update
+	emailDomain.UpdateByRequest(req)
+	if errInUpdate := controller.Storage.EmailDomainStorage.UpdateByID(emailDomain); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_EMAIL_DOMAIN, "update email domain error: "+errInUpdate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewEmailDomainResponse(emailDomain))
+	return
+}
+
+func (controller *Controller) VerifyEmailDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	emailDomainID, errInGetEmailDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_EMAIL_DOMAIN_ID)
+	if errInGetEmailDomainID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateEmailDomainManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get email domain
+	emailDomain, errInRetrieve := controller.Storage.EmailDomainStorage.RetrieveByTeamIDAndID(teamID, emailDomainID)
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_EMAIL_DOMAIN, "get email domain error: "+errInRetrieve.Error())
+		return
+	}
+	if emailDomain.IsVerified() {
+		controller.FeedbackOK(c, model.NewEmailDomainResponse(emailDomain))
+		return
+	}
+
+	// check the TXT record
+	records, errInLookup := controller.DNSResolver.LookupTXT(emailDomain.ExportVerificationRecord().Name)
+	if errInLookup != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_EMAIL_DOMAIN_VERIFICATION_FAILED, "lookup TXT record error: "+errInLookup.Error())
+		return
+	}
+	if !emailDomain.DoesTXTRecordsMatch(records) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_EMAIL_DOMAIN_VERIFICATION_FAILED, "the verification TXT record not found, the DNS changes may take a while to take effect.")
+		return
+	}
+
+	// update
+	emailDomain.Verify()
+	if errInUpdate := controller.Storage.EmailDomainStorage.UpdateByID(emailDomain); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_EMAIL_DOMAIN, "update email domain error: "+errInUpdate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewEmailDomainResponse(emailDomain))
+	return
+}
+
+func (controller *Controller) DeleteEmailDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	emailDomainID, errInGetEmailDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_EMAIL_DOMAIN_ID)
+	if errInGetEmailDomainID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateEmailDomainManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// delete, the joined team members will be kept
+	if errInDelete := controller.Storage.EmailDomainStorage.DeleteByTeamIDAndID(teamID, emailDomainID); errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_EMAIL_DOMAIN, "delete email domain error: "+errInDelete.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// check if the user can manage email domains of the team, the error will feedback by this method.
+func (controller *Controller) validateEmailDomainManager(c *gin.Context, teamID int, userID int) (*model.TeamMember, *model.Team, error) {
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return nil, nil, errInRetrieveTeamMember
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, nil, errInRetrieveTeam
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_TEAM, team)
+	if errInBuildAttributeGroup != nil {
+		return nil, nil, errInBuildAttributeGroup
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_CONFIG) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, nil, errors.New("access denied")
+	}
+	return teamMember, team, nil
+}
+
+// the default role of email domain works like an invite, so the user can not set the role he can not invite.
+func (controller *Controller) validateEmailDomainUserRole(c *gin.Context, teamMember *model.TeamMember, team *model.Team, userRole int) error {
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_INVITE, team)
+	if errInBuildAttributeGroup != nil {
+		return errInBuildAttributeGroup
+	}
+	if !attrg.CanInvite(userRole) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return errors.New("access denied")
+	}
+	return nil
+}
+
+// join the teams which verified the email domain of user, the failed teams will be skipped.
+func (controller *Controller) joinTeamsByEmailDomain(user *model.User) {
+	domain := model.ExtractDomainFromEmail(user.Email)
+	if domain == "" {
+		return
+	}
+	emailDomains, errInRetrieve := controller.Storage.EmailDomainStorage.RetrieveVerifiedByDomain(domain)
+	if errInRetrieve != nil {
+		log.Println("retrieve email domains failed: " + errInRetrieve.Error())
+		return
+	}
+	for _, emailDomain := range emailDomains {
+		joined, errInCheckJoined := controller.Storage.TeamMemberStorage.DoesTeamIncludedTargetUser(emailDomain.TeamID, user.ID)
+		if errInCheckJoined != nil || joined {
+			continue
+		}
+		team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(emailDomain.TeamID)
+		if errInRetrieveTeam != nil || team.IsArchived() {
+			continue
+		}
+
+		// create team member, the pending approval team member takes seat too, the team without seats left will be skipped
+		teamMember := model.NewTeamMemberByEmailDomain(emailDomain, user.ID)
+		teamMemberID, errInCreate := controller.Storage.TeamMemberStorage.CreateWithinCapacity(teamMember)
+		if errors.Is(errInCreate, model.ErrCapacityExceeded) {
+			continue
+		}
+		if errInCreate != nil {
+			log.Println("create team member by email domain failed: " + errInCreate.Error())
+			continue
+		}
+		teamMember.SetID(teamMemberID)
+		if !teamMember.IsStatusOK() {
+			continue
+		}
+
+		// notify other units
+		event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_JOINED, teamMember, user.ID)
+		if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+			log.Println("publish team member joined event failed: " + errInPublish.Error())
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) InviteMemberByEmail(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewInviteByEmailRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// check team switches first for detailed error message
+	if !team.DoesUserRoleCanInviteMember(teamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CLOSED_THE_PERMISSION, "this team closed the invite permission for your role.")
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_INVITE, team)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_INVITE_BY_EMAIL) || !attrg.CanInvite(req.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+	userGroupIDs := req.ExportUserGroupIDsInInt()
+	if errInValidateUserGroups := controller.validateInviteUserGroups(c, teamMember, team, userGroupIDs); errInValidateUserGroups != nil {
+		return
+	}
+
+	// check if target email already joined or invited
+	invitedUserID := model.PENDING_USER_ID
+	invitedUser, errInRetrieveInvitedUser := controller.Storage.UserStorage.RetrieveByEmail(req.Email)
+	if errInRetrieveInvitedUser == nil {
+		invitedUserID = invitedUser.ExportID()
+		joined, errInCheckJoined := controller.Storage.TeamMemberStorage.DoesTeamIncludedTargetUser(teamID, invitedUserID)
+		if errInCheckJoined != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CHECK_TEAM_MEMBER, "check team member error: "+errInCheckJoined.Error())
+			return
+		}
+		if joined {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_USER_ALREADY_JOINED_TEAM, "target user already joined this team.")
+			return
+		}
+	}
+	invited, errInCheckInvited := controller.Storage.InviteStorage.DoesEmailHasPendingInvite(teamID, req.Email)
+	if errInCheckInvited != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "check pending invite error: "+errInCheckInvited.Error())
+		return
+	}
+	if invited {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_EMAIL_ALREADY_INVITED, "target email already invited, please resend the invite.")
+		return
+	}
+
+	// create pending team member & invite, the pending team member takes seat once invited.
+	// the pending team member joins the user groups now, it takes effect after the invite accepted.
+	targetTeamMember := model.NewPendingTeamMember(teamID, invitedUserID, req.ExportUserRole())
+	invite := model.NewEmailInvite(teamMember, targetTeamMember, req.Email)
+	invite.SetUserGroupIDs(userGroupIDs)
+	errInCreateInvite := controller.Storage.InviteStorage.CreateWithTargetTeamMember(invite, targetTeamMember)
+	if errors.Is(errInCreateInvite, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team seats are used up.")
+		return
+	}
+	if errInCreateInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_INVITE, "create invite error: "+errInCreateInvite.Error())
+		return
+	}
+
+	// send invite email, the email status will recorded in invite, so user can resend it later.
+	if errInSend := controller.sendInviteEmail(team, userID, invite); errInSend != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_INVITE, "update invite error: "+errInSend.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteResponse(invite))
+	return
+}
+
+func (controller *Controller) GetAllPendingInvites(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_INVITE)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get pending invites
+	invites, errInRetrieveInvites := controller.Storage.InviteStorage.RetrieveByTeamIDAndCategoryAndStatus(teamID, model.INVITE_CATEGORY_EMAIL, model.INVITE_STATUS_PENDING)
+	if errInRetrieveInvites != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "get invites error: "+errInRetrieveInvites.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllInvitesResponse(invites))
+	return
+}
+
+func (controller *Controller) ResendInvite(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	inviteID, errInGetInviteID := controller.GetMagicIntParamFromRequest(c, PARAM_INVITE_ID)
+	if errInGetInviteID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// get invite
+	invite, errInRetrieveInvite := controller.Storage.InviteStorage.RetrieveByTeamIDAndID(teamID, inviteID)
+	if errInRetrieveInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "get invite error: "+errInRetrieveInvite.Error())
+		return
+	}
+	if !invite.IsEmailInvite() || !invite.IsStatusPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_CODE_ALREADY_USED, "this invite is not pending.")
+		return
+	}
+
+	// check team switches first for detailed error message
+	if !team.DoesUserRoleCanInviteMember(teamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CLOSED_THE_PERMISSION, "this team closed the invite permission for your role.")
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_INVITE, team)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_INVITE_BY_EMAIL) || !attrg.CanInvite(invite.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// renew expiration and resend
+	invite.InitExpiredAt(model.INVITE_EMAIL_EXPIRATION)
+	if errInSend := controller.sendInviteEmail(team, userID, invite); errInSend != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_INVITE, "update invite error: "+errInSend.Error())
+		return
+	}
+	if !invite.EmailStatus {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_SEND_EMAIL_FAILED, "send invite email failed.")
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteResponse(invite))
+	return
+}
+
+func (controller *Controller) RevokeInvite(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	inviteID, errInGetInviteID := controller.GetMagicIntParamFromRequest(c, PARAM_INVITE_ID)
+	if errInGetInviteID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get invite
+	invite, errInRetrieveInvite := controller.Storage.InviteStorage.RetrieveByTeamIDAndID(teamID, inviteID)
+	if errInRetrieveInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "get invite error: "+errInRetrieveInvite.Error())
+		return
+	}
+	if !invite.IsEmailInvite() || !invite.IsStatusPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_CODE_ALREADY_USED, "this invite is not pending.")
+		return
+	}
+
+	// validate user role, only the member who can invite target role can revoke it
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_INVITE)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanDelete(accesscontrol.ACTION_DELETE) || !attrg.CanInvite(invite.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// revoke invite
+	invite.Revoke()
+	if errInUpdateInvite := controller.Storage.InviteStorage.UpdateByID(invite); errInUpdateInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_INVITE, "update invite error: "+errInUpdateInvite.Error())
+		return
+	}
+
+	// remove the pending team member created by this invite
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, invite.TargetTeamMemberID)
+	if errInRetrieveTargetTeamMember == nil && targetTeamMember.IsStatusPending() {
+		if errInDeleteTeamMember := controller.Storage.TeamMemberStorage.DeleteByIDAndTeamID(targetTeamMember.ExportID(), teamID); errInDeleteTeamMember != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER, "delete team member error: "+errInDeleteTeamMember.Error())
+			return
+		}
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) AcceptInvite(c *gin.Context) {
+	// get user id & invite hash
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	inviteHash, errInGetInviteHash := controller.GetStringParamFromRequest(c, PARAM_INVITE_HASH)
+	if errInGetInviteHash != nil {
+		return
+	}
+	inviteUID, errInParseHash := model.ParseInviteHash(inviteHash)
+	if errInParseHash != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED, "parse invite hash error: "+errInParseHash.Error())
+		return
+	}
+
+	// get invite
+	invite, errInRetrieveInvite := controller.Storage.InviteStorage.RetrieveByUID(inviteUID)
+	if errInRetrieveInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "get invite error: "+errInRetrieveInvite.Error())
+		return
+	}
+	if !invite.IsEmailInvite() || !invite.IsStatusPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_CODE_ALREADY_USED, "this invite is already used or revoked.")
+		return
+	}
+	if invite.IsExpired() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_EXPIRED, "this invite is expired, please ask the team member to resend it.")
+		return
+	}
+
+	// validate user email
+	user, errInRetrieveUser := controller.Storage.UserStorage.RetrieveByID(userID)
+	if errInRetrieveUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+errInRetrieveUser.Error())
+		return
+	}
+	if !invite.DoesEmailMatch(user.ExportEmail()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITE_EMAIL_MISMATCH, "this invite does not belong to your email.")
+		return
+	}
+
+	// the archived team can not be joined
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(invite.TeamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+	if team.IsArchived() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_ARCHIVED, "this team has been archived.")
+		return
+	}
+
+	// accept, the pending team member of invite is reused, and the user can not accept it when already joined this team.
+	errInAccept := controller.Storage.InviteStorage.AcceptByUser(invite, userID)
+	if errors.Is(errInAccept, model.ErrTeamMemberAlreadyJoined) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_USER_ALREADY_JOINED_TEAM, "you already joined this team.")
+		return
+	}
+	if errInAccept != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "accept invite error: "+errInAccept.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteResponse(invite))
+	return
+}
+
+// send invite email and record the email status in invite.
+func (controller *Controller) sendInviteEmail(team *model.Team, userID int, invite *model.Invite) error {
+	inviterNickname := ""
+	inviter, errInRetrieveInviter := controller.Storage.UserStorage.RetrieveByID(userID)
+	if errInRetrieveInviter == nil {
+		inviterNickname = inviter.Nickname
+	}
+	errInSendEmail := model.SendInviteEmail(invite.Email, team.Name, inviterNickname, invite.ExportInviteLink(), invite.ExportUserRole())
+	if errInSendEmail != nil {
+		log.Println("send invite email to " + invite.Email + " failed: " + errInSendEmail.Error())
+	}
+	invite.SetEmailStatus(errInSendEmail == nil)
+	return controller.Storage.InviteStorage.UpdateByID(invite)
+}
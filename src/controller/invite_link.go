@@ -0,0 +1,328 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) CreateInviteLink(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userRole, errInGetUserRole := controller.GetIntParamFromRequest(c, PARAM_USER_ROLE)
+	if errInGetUserRole != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewInviteLinkConfigRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, team, errInValidate := controller.validateInviteLinkIssuer(c, teamID, userID, userRole)
+	if errInValidate != nil {
+		return
+	}
+
+	// validate target user groups
+	if errInValidateUserGroups := controller.validateInviteUserGroups(c, teamMember, team, req.ExportUserGroupIDsInInt()); errInValidateUserGroups != nil {
+		return
+	}
+
+	// one team can only have one avaliable invite link for each role
+	_, errInRetrieveInviteLink := controller.Storage.InviteStorage.RetrieveActiveLinkByTeamIDAndUserRole(team.ExportID(), userRole)
+	if errInRetrieveInviteLink == nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_INVITE, "invite link of this role already exists, please renew it for new config.")
+		return
+	}
+
+	// create invite link
+	invite := model.NewLinkInvite(teamMember, userRole, req.ExportMaxUses(), req.ExportExpiration())
+	invite.SetUserGroupIDs(req.ExportUserGroupIDsInInt())
+	if _, errInCreateInvite := controller.Storage.InviteStorage.Create(invite); errInCreateInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_INVITE, "create invite link error: "+errInCreateInvite.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteLinkResponse(invite))
+	return
+}
+
+func (controller *Controller) GetInviteLink(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userRole, errInGetUserRole := controller.GetIntParamFromRequest(c, PARAM_USER_ROLE)
+	if errInGetUserRole != nil {
+		return
+	}
+
+	// validate user
+	_, team, errInValidate := controller.validateInviteLinkIssuer(c, teamID, userID, userRole)
+	if errInValidate != nil {
+		return
+	}
+
+	// get invite link
+	invite, errInRetrieveInviteLink := controller.Storage.InviteStorage.RetrieveActiveLinkByTeamIDAndUserRole(team.ExportID(), userRole)
+	if errInRetrieveInviteLink != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "get invite link error: "+errInRetrieveInviteLink.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteLinkResponse(invite))
+	return
+}
+
+func (controller *Controller) RenewInviteLink(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userRole, errInGetUserRole := controller.GetIntParamFromRequest(c, PARAM_USER_ROLE)
+	if errInGetUserRole != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewInviteLinkConfigRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, team, errInValidate := controller.validateInviteLinkIssuer(c, teamID, userID, userRole)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateUserGroups := controller.validateInviteUserGroups(c, teamMember, team, req.ExportUserGroupIDsInInt()); errInValidateUserGroups != nil {
+		return
+	}
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_INVITE, team)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_INVITE_LINK_RENEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// renew invite link, the old one will be revoked
+	invite := model.NewLinkInvite(teamMember, userRole, req.ExportMaxUses(), req.ExportExpiration())
+	invite.SetUserGroupIDs(req.ExportUserGroupIDsInInt())
+	if errInRenew := controller.Storage.InviteStorage.RenewLink(invite); errInRenew != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_INVITE, "renew invite link error: "+errInRenew.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteLinkResponse(invite))
+	return
+}
+
+func (controller *Controller) DisableInviteLink(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userRole, errInGetUserRole := controller.GetIntParamFromRequest(c, PARAM_USER_ROLE)
+	if errInGetUserRole != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_INVITE)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_INVITE_LINK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// revoke invite link
+	if errInRevoke := controller.Storage.InviteStorage.RevokeLinkByTeamIDAndUserRole(teamID, userRole); errInRevoke != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_INVITE, "disable invite link error: "+errInRevoke.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// this is a public method, anyone who owned the invite link can preview the team.
+func (controller *Controller) PreviewInviteLink(c *gin.Context) {
+	invite, team, errInRetrieve := controller.retrieveAvaliableInviteLink(c)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteLinkPreviewResponse(team, invite))
+	return
+}
+
+func (controller *Controller) JoinByInviteLink(c *gin.Context) {
+	// get user id
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get invite link
+	invite, team, errInRetrieve := controller.retrieveAvaliableInviteLink(c)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// check if user already joined or invited to this team
+	joinedTeamMember, errInRetrieveJoinedTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(team.ExportID(), userID)
+	if errInRetrieveJoinedTeamMember == nil {
+		if joinedTeamMember.IsStatusPending() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_USER_ALREADY_JOINED_TEAM, "you have a pending invite of this team, please accept it.")
+			return
+		}
+		controller.FeedbackBadRequest(c, ERROR_FLAG_USER_ALREADY_JOINED_TEAM, "you already joined this team.")
+		return
+	}
+
+	// join, the team capacity is checked with the join
+	teamMember := model.NewTeamMemberByInviteLink(invite, userID)
+	errInJoin := controller.Storage.InviteStorage.JoinByLink(invite, teamMember)
+	if errors.Is(errInJoin, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team seats are used up.")
+		return
+	}
+	if errInJoin != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_LINK_UNAVALIABLE, "join team by invite link error: "+errInJoin.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewInviteLinkPreviewResponse(team, invite))
+	return
+}
+
+// validate the team member can issue invite link of target role, the error will feedback by this method.
+func (controller *Controller) validateInviteLinkIssuer(c *gin.Context, teamID int, userID int, userRole int) (*model.TeamMember, *model.Team, error) {
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return nil, nil, errInRetrieveTeamMember
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, nil, errInRetrieveTeam
+	}
+
+	// check team switches first for detailed error message
+	if !team.DoesInviteLinkEnabled() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_LINK_UNAVALIABLE, "this team closed the invite link.")
+		return nil, nil, errors.New("invite link disabled.")
+	}
+	if !team.DoesUserRoleCanInviteMember(teamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CLOSED_THE_PERMISSION, "this team closed the invite permission for your role.")
+		return nil, nil, errors.New("team closed the permission.")
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_INVITE, team)
+	if errInBuildAttributeGroup != nil {
+		return nil, nil, errInBuildAttributeGroup
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_INVITE_BY_LINK) || !attrg.CanInvite(userRole) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, nil, errors.New("access denied.")
+	}
+	return teamMember, team, nil
+}
+
+// retrieve invite link by hash in request param and make sure it is avaliable, the error will feedback by this method.
+func (controller *Controller) retrieveAvaliableInviteLink(c *gin.Context) (*model.Invite, *model.Team, error) {
+	inviteLinkHash, errInGetInviteLinkHash := controller.GetStringParamFromRequest(c, PARAM_INVITE_LINK_HASH)
+	if errInGetInviteLinkHash != nil {
+		return nil, nil, errInGetInviteLinkHash
+	}
+	inviteUID, errInParseHash := model.ParseInviteHash(inviteLinkHash)
+	if errInParseHash != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED, "parse invite link hash error: "+errInParseHash.Error())
+		return nil, nil, errInParseHash
+	}
+
+	// get invite link
+	invite, errInRetrieveInvite := controller.Storage.InviteStorage.RetrieveByUID(inviteUID)
+	if errInRetrieveInvite != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_INVITE, "get invite link error: "+errInRetrieveInvite.Error())
+		return nil, nil, errInRetrieveInvite
+	}
+	if !invite.IsLinkInvite() || !invite.IsStatusPending() || invite.IsUsedUp() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_LINK_UNAVALIABLE, "this invite link is unavaliable.")
+		return nil, nil, errors.New("invite link unavaliable.")
+	}
+	if invite.IsExpired() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_EXPIRED, "this invite link is expired.")
+		return nil, nil, errors.New("invite link expired.")
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(invite.TeamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, nil, errInRetrieveTeam
+	}
+	if team.IsArchived() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_ARCHIVED, "this team has been archived.")
+		return nil, nil, errors.New("team archived.")
+	}
+	if !team.DoesInviteLinkEnabled() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVITATION_LINK_UNAVALIABLE, "this team closed the invite link.")
+		return nil, nil, errors.New("invite link disabled.")
+	}
+	return invite, team, nil
+}
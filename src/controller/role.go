@@ -0,0 +1,442 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetAllRoles(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get roles with users
+	roles, errInRetrieveRoles := controller.Storage.RoleStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveRoles != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get roles error: "+errInRetrieveRoles.Error())
+		return
+	}
+	userRoleRelations, errInRetrieveUserRoleRelations := controller.Storage.UserRoleRelationStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveUserRoleRelations != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role users error: "+errInRetrieveUserRoleRelations.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllRolesResponse(roles, userRoleRelations))
+	return
+}
+
+func (controller *Controller) CreateRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewRoleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateRoleManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateGrants := controller.validateRoleGrants(c, teamMember, team, req.ExportPermissions()); errInValidateGrants != nil {
+		return
+	}
+
+	// check name
+	if errInValidateName := controller.validateRoleName(c, teamID, req.Name, 0); errInValidateName != nil {
+		return
+	}
+
+	// create
+	role := model.NewRoleByCreateRequest(teamID, req)
+	role.SetEditorSeat(accesscontrol.DoesRoleTakeEditorSeat(role))
+	if _, errInCreate := controller.Storage.RoleStorage.Create(role); errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ROLE, "create role error: "+errInCreate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewRoleResponse(role, nil))
+	return
+}
+
+func (controller *Controller) GetRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetRoleID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get role with users
+	role, userRoleRelations, errInRetrieve := controller.retrieveRoleWithUsers(c, teamID, roleID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewRoleResponse(role, model.PickUpUserIDsInUserRoleRelations(userRoleRelations)))
+	return
+}
+
+func (controller *Controller) UpdateRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetRoleID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewRoleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateRoleManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateGrants := controller.validateRoleGrants(c, teamMember, team, req.ExportPermissions()); errInValidateGrants != nil {
+		return
+	}
+
+	// get role with users
+	role, userRoleRelations, errInRetrieve := controller.retrieveRoleWithUsers(c, teamID, roleID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// check name
+	if errInValidateName := controller.validateRoleName(c, teamID, req.Name, role.ExportID()); errInValidateName != nil {
+		return
+	}
+
+	// update
+	role.UpdateByRequest(req)
+	role.SetEditorSeat(accesscontrol.DoesRoleTakeEditorSeat(role))
+	errInUpdate := controller.Storage.RoleStorage.UpdateByID(role)
+	if errors.Is(errInUpdate, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team editor seats are used up by the users of this role.")
+		return
+	}
+	if errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_ROLE, "update role error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, model.NewRoleResponse(role, model.PickUpUserIDsInUserRoleRelations(userRoleRelations)))
+	return
+}
+
+func (controller *Controller) DeleteRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetRoleID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateRoleManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get role
+	role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, roleID)
+	if errInRetrieveRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+		return
+	}
+
+	// delete role with user relations
+	if errInDelete := controller.Storage.RoleStorage.DeleteByTeamIDAndID(teamID, role.ExportID()); errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_ROLE, "delete role error: "+errInDelete.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) AssignRoleUsers(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetRoleID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateRoleUsersRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateRoleManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get role
+	role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, roleID)
+	if errInRetrieveRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+		return
+	}
+
+	// the role can only be assigned when the operator can grant all of its attributes
+	if errInValidateGrants := controller.validateRoleGrants(c, teamMember, team, role.ExportPermissions()); errInValidateGrants != nil {
+		return
+	}
+
+	// the target users must be members of this team
+	targetUserIDs := req.ExportUserIDsInInt()
+	targetTeamMembers, errInRetrieveTargetTeamMembers := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserIDs(teamID, targetUserIDs)
+	if errInRetrieveTargetTeamMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team members error: "+errInRetrieveTargetTeamMembers.Error())
+		return
+	}
+	if len(targetTeamMembers) != len(targetUserIDs) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "target team members not found.")
+		return
+	}
+
+	// assign
+	errInCreate := controller.Storage.UserRoleRelationStorage.CreateByTeamIDAndRoleIDAndUserIDs(teamID, role.ExportID(), targetUserIDs)
+	if errors.Is(errInCreate, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team editor seats are used up.")
+		return
+	}
+	if errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_ROLE, "assign role to users error: "+errInCreate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback with latest users
+	_, userRoleRelations, errInRetrieve := controller.retrieveRoleWithUsers(c, teamID, roleID)
+	if errInRetrieve != nil {
+		return
+	}
+	controller.FeedbackOK(c, model.NewRoleResponse(role, model.PickUpUserIDsInUserRoleRelations(userRoleRelations)))
+	return
+}
+
+func (controller *Controller) RemoveRoleUser(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetRoleID != nil {
+		return
+	}
+	targetUserID, errInGetTargetUserID := controller.GetMagicIntParamFromRequest(c, PARAM_TARGET_USER_ID)
+	if errInGetTargetUserID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateRoleManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// remove user
+	errInDelete := controller.Storage.UserRoleRelationStorage.DeleteByTeamIDAndRoleIDAndUserID(teamID, roleID, targetUserID)
+	if errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_ROLE, "remove role user error: "+errInDelete.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// check if the user can manage custom roles, the error will feedback by this method.
+func (controller *Controller) validateRoleManager(c *gin.Context, teamID int, userID int) (*model.TeamMember, *model.Team, error) {
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return nil, nil, errInRetrieveTeamMember
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, nil, errInRetrieveTeam
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER, team)
+	if errInBuildAttributeGroup != nil {
+		return nil, nil, errInBuildAttributeGroup
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_MANAGE_ROLE) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, nil, errors.New("access denied.")
+	}
+	return teamMember, team, nil
+}
+
+// the role can only carry the attributes which the operator can grant, the error will feedback by this method.
+// one attribute group is built for each unit type.
+func (controller *Controller) validateRoleGrants(c *gin.Context, teamMember *model.TeamMember, team *model.Team, permissions []*model.RolePermission) error {
+	attrgs := make(map[int]*accesscontrol.AttributeGroup)
+	for _, permission := range permissions {
+		override := permission.ExportPermissionOverride()
+		attrg, hit := attrgs[override.UnitType]
+		if !hit {
+			var errInBuildAttributeGroup error
+			attrg, errInBuildAttributeGroup = controller.BuildAttributeGroupInTeam(c, teamMember, override.UnitType, team)
+			if errInBuildAttributeGroup != nil {
+				return errInBuildAttributeGroup
+			}
+			attrgs[override.UnitType] = attrg
+		}
+		if !attrg.CanGrantPermissionOverride(override) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not grant the attribute you do not have.")
+			return errors.New("access denied.")
+		}
+	}
+	return nil
+}
+
+// the role name is unique in team, the error will feedback by this method.
+func (controller *Controller) validateRoleName(c *gin.Context, teamID int, name string, excludedRoleID int) error {
+	nameExists, errInCheckName := controller.Storage.RoleStorage.DoesNameExist(teamID, name, excludedRoleID)
+	if errInCheckName != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "check role name error: "+errInCheckName.Error())
+		return errInCheckName
+	}
+	if nameExists {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ROLE_NAME_ALREADY_EXISTS, "role name already exists.")
+		return errors.New("role name already exists.")
+	}
+	return nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) retrieveRoleWithUsers(c *gin.Context, teamID int, roleID int) (*model.Role, []*model.UserRoleRelation, error) {
+	role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, roleID)
+	if errInRetrieveRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+		return nil, nil, errInRetrieveRole
+	}
+	userRoleRelations, errInRetrieveUserRoleRelations := controller.Storage.UserRoleRelationStorage.RetrieveByTeamIDAndRoleID(teamID, roleID)
+	if errInRetrieveUserRoleRelations != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role users error: "+errInRetrieveUserRoleRelations.Error())
+		return nil, nil, errInRetrieveUserRoleRelations
+	}
+	return role, userRoleRelations, nil
+}
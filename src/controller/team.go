@@ -2,8 +2,10 @@ package controller
 
 import (
 	"encoding/json"
+	"log"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 
 	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
 	"github.com/illacloud/illa-supervisor-backend/src/model"
@@ -15,8 +17,8 @@ func (controller *Controller) GetMyTeams(c *gin.Context) {
 		return
 	}
 
-	// retrieve
-	teamMembers, errInGetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByUserID(userID)
+	// retrieve, the teams which user is suspended or not joined yet are excluded
+	teamMembers, errInGetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByUserIDAndStatus(userID, model.TEAM_MEMBER_STATUS_OK)
 	if errInGetTeamMember != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team by id error: "+errInGetTeamMember.Error())
 		return
@@ -52,14 +54,16 @@ func (controller *Controller) UpdateTeamConfig(c *gin.Context) {
 	}
 
 	// validate user
-	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
 	if errInRetrieveTeamMember != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
 		return
 	}
 
 	// validate user role
-	attrg := accesscontrol.NewAttributeGroup(teamMember.ExportUserRole(), accesscontrol.UNIT_TYPE_TEAM)
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
 	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_CONFIG) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -73,15 +77,16 @@ func (controller *Controller) UpdateTeamConfig(c *gin.Context) {
 	}
 
 	// update team config
+	before := model.NewTeamSettingsByTeam(team)
 	errInConstructRawConfig := team.UpdateByUpdateTeamConfigRawRequest(rawRequest)
 	if errInConstructRawConfig != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_BUILD_TEAM_CONFIG_FAILED, "build team config error: "+errInConstructRawConfig.Error())
 		return
 	}
 
-	// update
-	if err := controller.Storage.TeamStorage.UpdateByID(team); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+err.Error())
+	// update with revision
+	revision := model.NewTeamSettingRevision(model.TEAM_SETTING_REVISION_CATEGORY_CONFIG, before, model.NewTeamSettingsByTeam(team), userID)
+	if errInUpdate := controller.updateTeamSettings(c, team, revision); errInUpdate != nil {
 		return
 	}
 
@@ -106,14 +111,16 @@ func (controller *Controller) UpdateTeamPermission(c *gin.Context) {
 	}
 
 	// validate user
-	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
 	if errInRetrieveTeamMember != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
 		return
 	}
 
 	// validate user role
-	attrg := accesscontrol.NewAttributeGroup(teamMember.ExportUserRole(), accesscontrol.UNIT_TYPE_TEAM)
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
 	if !attrg.CanManage(accesscontrol.ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -126,14 +133,149 @@ func (controller *Controller) UpdateTeamPermission(c *gin.Context) {
 		return
 	}
 
-	// update team permission
+	// update team permission with revision
+	before := model.NewTeamSettingsByTeam(team)
 	errInParseRawReq := team.UpdateByUpdateTeamPermissionRawRequest(rawRequest)
 	if errInParseRawReq != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_BUILD_TEAM_PERMISSION_FAILED, "build team permission error: "+errInParseRawReq.Error())
 		return
 	}
-	if err := controller.Storage.TeamStorage.UpdateByID(team); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+err.Error())
+	revision := model.NewTeamSettingRevision(model.TEAM_SETTING_REVISION_CATEGORY_PERMISSION, before, model.NewTeamSettingsByTeam(team), userID)
+	if errInUpdate := controller.updateTeamSettings(c, team, revision); errInUpdate != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) GetTeamIconUploadAddress(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	fileName, errInGetFileName := controller.GetStringParamFromRequest(c, PARAM_FILE_NAME)
+	if errInGetFileName != nil {
+		return
+	}
+
+	// sanitize file name
+	sanitizedFileName, contentType, errInSanitize := model.SanitizeTeamIconFileName(fileName)
+	if errInSanitize != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_FILE_NAME_FAILED, "validate file name error: "+errInSanitize.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_ICON) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// get upload address
+	teamDrive := model.NewTeamDrive(controller.Drive)
+	teamDrive.SetTeam(team)
+	presignedURL, errInGetPreSignedURL := teamDrive.GetIconUploadPreSignedURL(sanitizedFileName, contentType)
+	if errInGetPreSignedURL != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CREATE_UPLOAD_URL_FAILED, "get upload URL failed: "+errInGetPreSignedURL.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamIconUploadAddressResponse(presignedURL, sanitizedFileName, contentType))
+	return
+}
+
+func (controller *Controller) UpdateTeamIcon(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateTeamIconRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// sanitize file name
+	sanitizedFileName, _, errInSanitize := model.SanitizeTeamIconFileName(req.FileName)
+	if errInSanitize != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_FILE_NAME_FAILED, "validate file name error: "+errInSanitize.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_ICON) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// make sure the icon was uploaded
+	teamDrive := model.NewTeamDrive(controller.Drive)
+	teamDrive.SetTeam(team)
+	exists, errInCheckIcon := teamDrive.DoesIconExist(sanitizedFileName)
+	if errInCheckIcon != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_UPLOADED_OBJECT_NOT_FOUND, "check uploaded team icon error: "+errInCheckIcon.Error())
+		return
+	}
+	if !exists {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_UPLOADED_OBJECT_NOT_FOUND, "team icon not uploaded, please upload it first.")
+		return
+	}
+
+	// update team icon with revision
+	before := model.NewTeamSettingsByTeam(team)
+	team.SetIcon(teamDrive.GetIconURL(sanitizedFileName))
+	revision := model.NewTeamSettingRevision(model.TEAM_SETTING_REVISION_CATEGORY_CONFIG, before, model.NewTeamSettingsByTeam(team), userID)
+	if errInUpdate := controller.updateTeamSettings(c, team, revision); errInUpdate != nil {
 		return
 	}
 
@@ -141,3 +283,105 @@ func (controller *Controller) UpdateTeamPermission(c *gin.Context) {
 	controller.FeedbackOK(c, nil)
 	return
 }
+
+func (controller *Controller) ArchiveTeam(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// only the owner can archive team
+	if !teamMember.IsOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "only the team owner can archive team.")
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+	if team.IsArchived() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_ARCHIVED, "this team has already been archived.")
+		return
+	}
+
+	// archive
+	team.Archive(userID)
+	if err := controller.Storage.TeamStorage.UpdateArchive(team); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "archive team error: "+err.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_ARCHIVED, teamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team archived event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTeamByTeamIDResponse(team))
+	return
+}
+
+func (controller *Controller) RestoreTeam(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// only the owner can restore team
+	if !teamMember.IsOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "only the team owner can restore team.")
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+	if !team.IsArchived() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "this team is not archived.")
+		return
+	}
+	if !team.IsRestorable() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_RESTORE_EXPIRED, "the retention window of this team has expired.")
+		return
+	}
+
+	// restore
+	team.Restore()
+	if err := controller.Storage.TeamStorage.UpdateArchive(team); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "restore team error: "+err.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_RESTORED, teamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team restored event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTeamByTeamIDResponse(team))
+	return
+}
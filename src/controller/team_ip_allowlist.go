@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetTeamIPAllowlist(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	if _, errInValidate := controller.validateTeamSettingsManager(c, teamMember); errInValidate != nil {
+		return
+	}
+
+	// retrieve
+	allowlist, errInRetrieveAllowlist := controller.Storage.TeamIPAllowlistStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveAllowlist != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_IP_ALLOWLIST, "get team IP allowlist error: "+errInRetrieveAllowlist.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamIPAllowlistResponse(allowlist))
+	return
+}
+
+func (controller *Controller) UpdateTeamIPAllowlist(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateTeamIPAllowlistRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role, owner and admin can edit the allowlist
+	if _, errInValidate := controller.validateTeamSettingsManager(c, teamMember); errInValidate != nil {
+		return
+	}
+
+	// retrieve
+	allowlist, errInRetrieveAllowlist := controller.Storage.TeamIPAllowlistStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveAllowlist != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_IP_ALLOWLIST, "get team IP allowlist error: "+errInRetrieveAllowlist.Error())
+		return
+	}
+	if errInUpdateByRequest := allowlist.UpdateByRequest(req, userID); errInUpdateByRequest != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+errInUpdateByRequest.Error())
+		return
+	}
+
+	// the caller should not lock themselves out by the new allowlist
+	if !allowlist.DoesIPAllowed(c.ClientIP()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_IP_ALLOWLIST_LOCKOUT, "your current IP address "+c.ClientIP()+" is not included in the new allowlist.")
+		return
+	}
+
+	// update
+	if errInUpdate := controller.Storage.TeamIPAllowlistStorage.Upsert(allowlist); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_IP_ALLOWLIST, "update team IP allowlist error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamIPAllowlistResponse(allowlist))
+	return
+}
+
+// validate the end-user IP passed by internal request against the team allowlist, the error will feedback by this method.
+// the request without end-user IP is denied when the allowlist is enabled, and the header is only trusted with a validated request token.
+func (controller *Controller) validateEndUserIP(c *gin.Context, allowlist *model.TeamIPAllowlist) error {
+	if !allowlist.IsEnabled() {
+		return nil
+	}
+	if !c.GetBool(CONTEXT_REQUEST_TOKEN_VALIDATED) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "the end-user IP address is only accepted from internal request.")
+		return errors.New("end-user IP passed without request token.")
+	}
+	if !allowlist.DoesIPAllowed(c.GetHeader(PARAM_END_USER_IP)) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_IP_NOT_ALLOWED, "the end-user IP address is not allowed by this team.")
+		return errors.New("end-user IP not allowed.")
+	}
+	return nil
+}
@@ -0,0 +1,635 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// list team members, filter by user group when the userGroupID query param provided.
+func (controller *Controller) GetAllTeamMembers(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get team members
+	var teamMembers []*model.TeamMember
+	var errInRetrieveTeamMembers error
+	userGroupIDInString, errInGetUserGroupID := controller.TestFirstStringParamValueFromURI(c, PARAM_USER_GROUP_ID)
+	if errInGetUserGroupID == nil {
+		teamMembers, errInRetrieveTeamMembers = controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserGroupID(teamID, idconvertor.ConvertStringToInt(userGroupIDInString))
+	} else {
+		teamMembers, errInRetrieveTeamMembers = controller.Storage.TeamMemberStorage.RetrieveByTeamID(teamID)
+	}
+	if errInRetrieveTeamMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "get team members error: "+errInRetrieveTeamMembers.Error())
+		return
+	}
+
+	// get users of team members
+	teamMembersForExport, errInExport := controller.exportTeamMembersWithUserInfo(c, teamMembers)
+	if errInExport != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllTeamMembersResponse(teamMembersForExport))
+	return
+}
+
+func (controller *Controller) RemoveTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if targetTeamMember.IsOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM, "can not remove team owner from team.")
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanRemove(targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// remove
+	if errInRemove := controller.removeTeamMember(c, targetTeamMember); errInRemove != nil {
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_REMOVED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member removed event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) LeaveTeam(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// owner can not leave team before transfer the owner role
+	if teamMember.IsOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_OWNER_ROLE_MUST_BE_TRANSFERED, "please transfer your team owner role, then you can leave this team.")
+		return
+	}
+
+	// remove
+	if errInRemove := controller.removeTeamMember(c, teamMember); errInRemove != nil {
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_LEFT, teamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member left event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) TransferTeamOwner(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewTransferTeamOwnerRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_TRANSFER_OWNER) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// re-confirm password
+	user, errInRetrieveUser := controller.Storage.UserStorage.RetrieveByID(userID)
+	if errInRetrieveUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+errInRetrieveUser.Error())
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordDigest), []byte(req.Password)); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PASSWORD_INVALIED, "password incorrect")
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, req.ExportTargetTeamMemberIDInInt())
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "you are already the owner of this team.")
+		return
+	}
+	if !targetTeamMember.IsStatusOK() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER, "can not transfer owner to pending user.")
+		return
+	}
+	targetUser, errInRetrieveTargetUser := controller.Storage.UserStorage.RetrieveByID(targetTeamMember.ExportUserID())
+	if errInRetrieveTargetUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get target user error: "+errInRetrieveTargetUser.Error())
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// transfer
+	errInTransfer := controller.Storage.TeamMemberStorage.TransferOwner(teamID, teamMember.ExportID(), targetTeamMember.ExportID())
+	if errInTransfer != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "transfer team owner error: "+errInTransfer.Error())
+		return
+	}
+
+	// notify both parties & other units
+	for _, email := range []string{user.ExportEmail(), targetUser.ExportEmail()} {
+		if errInSendEmail := model.SendTransferOwnerEmail(email, team.Name, user.Nickname, targetUser.Nickname); errInSendEmail != nil {
+			log.Println("send transfer owner email to " + email + " failed: " + errInSendEmail.Error())
+		}
+	}
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_OWNER_CHANGED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team owner changed event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) UpdateTeamMemberRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateTeamMemberRoleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if req.IsTransferOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "please transfer team owner instead.")
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if targetTeamMember.IsOwner() || targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "can not change role of team owner or yourself.")
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) || !attrg.CanModifyRoleFromTo(targetTeamMember.ExportUserRole(), req.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// update, the team capacity is checked with the update
+	targetTeamMember.UpdateByUpdateTeamMemberRoleRequest(req)
+	errInUpdate := controller.Storage.TeamMemberStorage.UpdateUserRole(targetTeamMember)
+	if errors.Is(errInUpdate, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team editor seats are used up.")
+		return
+	}
+	if errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member role error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_ROLE_CHANGED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member role changed event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) SuspendTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewSuspendTeamMemberRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if targetTeamMember.IsOwner() || targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "can not suspend team owner or yourself.")
+		return
+	}
+	if !targetTeamMember.IsStatusOK() && !targetTeamMember.IsStatusSuspended() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "can not suspend pending team member, please revoke the invite instead.")
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_SUSPEND_MEMBER) || !attrg.CanRemove(targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// suspend
+	targetTeamMember.Suspend(req.Reason, req.ExportSuspendedUntil())
+	if errInUpdate := controller.Storage.TeamMemberStorage.UpdateSuspension(targetTeamMember); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "suspend team member error: "+errInUpdate.Error())
+		return
+	}
+
+	// invalidate sessions of target user in this team
+	errInRevokeTokens := controller.Authenticator.RevokeAccessTokensInTeam(teamID, targetTeamMember.ExportUserID())
+	if errInRevokeTokens != nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_CAHCE_JWT_TOKEN_FAILED, "revoke user token failed: "+errInRevokeTokens.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_SUSPENDED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member suspended event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) ReactivateTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if !targetTeamMember.IsStatusSuspended() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "target team member is not suspended.")
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_SUSPEND_MEMBER) || !attrg.CanRemove(targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// reactivate
+	targetTeamMember.Reactivate()
+	if errInUpdate := controller.Storage.TeamMemberStorage.UpdateSuspension(targetTeamMember); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "reactivate team member error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamMemberReactivated(targetTeamMember, userID)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) ApproveTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if !targetTeamMember.IsStatusPendingApproval() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "target team member is not waiting for approval.")
+		return
+	}
+
+	// validate user role, approve the join request works like inviting the role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_INVITE)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanInvite(targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// approve
+	targetTeamMember.Approve()
+	if errInUpdate := controller.Storage.TeamMemberStorage.UpdateStatus(targetTeamMember); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "approve team member error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_JOINED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member joined event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// retrieve team member and make sure the member is avaliable in this team, the error will feedback by this method.
+func (controller *Controller) RetrieveAvaliableTeamMember(c *gin.Context, teamID int, userID int) (*model.TeamMember, error) {
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return nil, errInRetrieveTeamMember
+	}
+	if errInValidateStatus := controller.validateTeamMemberStatus(c, teamMember); errInValidateStatus != nil {
+		return nil, errInValidateStatus
+	}
+	return teamMember, nil
+}
+
+// pending and suspended team member can not access the team, the error will feedback by this method.
+func (controller *Controller) validateTeamMemberStatus(c *gin.Context, teamMember *model.TeamMember) error {
+	// reactivate the team member which suspension expired, and notify other units as the manual reactivation does
+	if teamMember.IsSuspensionExpired() {
+		teamMember.Reactivate()
+		if errInUpdate := controller.Storage.TeamMemberStorage.UpdateSuspension(teamMember); errInUpdate != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "reactivate team member error: "+errInUpdate.Error())
+			return errInUpdate
+		}
+		controller.publishTeamMemberReactivated(teamMember, model.SUPERVISOR_EVENT_OPERATOR_SYSTEM)
+	}
+	if teamMember.IsStatusSuspended() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_MEMBER_SUSPENDED, "you have been suspended in this team.")
+		return errors.New("team member suspended.")
+	}
+	if teamMember.IsStatusPendingApproval() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_MEMBER_NOT_ACTIVATED, "your join request is waiting for approval by team manager.")
+		return errors.New("team member not approved.")
+	}
+	if !teamMember.IsStatusOK() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_MEMBER_NOT_ACTIVATED, "you have not joined this team yet, please accept the invite first.")
+		return errors.New("team member not activated.")
+	}
+	return nil
+}
+
+// the event invalidates the cached access control snapshot of team member in every replica.
+func (controller *Controller) publishTeamMemberReactivated(teamMember *model.TeamMember, operatorID int) {
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_REACTIVATED, teamMember, operatorID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member reactivated event failed: " + errInPublish.Error())
+	}
+}
+
+// remove team member and clean up the related data, the error will feedback by this method.
+func (controller *Controller) removeTeamMember(c *gin.Context, teamMember *model.TeamMember) error {
+	// revoke tokens of target user in this team
+	if teamMember.ExportUserID() != model.PENDING_USER_ID {
+		errInRevokeTokens := controller.Authenticator.RevokeAccessTokensInTeam(teamMember.TeamID, teamMember.ExportUserID())
+		if errInRevokeTokens != nil {
+			controller.FeedbackInternalServerError(c, ERROR_FLAG_CAHCE_JWT_TOKEN_FAILED, "revoke user token failed: "+errInRevokeTokens.Error())
+			return errInRevokeTokens
+		}
+	}
+
+	// delete team member with its pending invites, user groups, custom roles, temporary grants and unit ACL entries
+	errInRemoveTeamMember := controller.Storage.TeamMemberStorage.Remove(teamMember)
+	if errInRemoveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER, "delete team member error: "+errInRemoveTeamMember.Error())
+		return errInRemoveTeamMember
+	}
+	return nil
+}
+
+// export team members with the user info, the error will feedback by this method.
+func (controller *Controller) exportTeamMembersWithUserInfo(c *gin.Context, teamMembers []*model.TeamMember) ([]*model.TeamMemberWithUserInfoForExport, error) {
+	users, errInRetrieveUsers := controller.Storage.UserStorage.RetrieveByIDs(model.PickUpUserIDsInUserMembers(teamMembers))
+	if errInRetrieveUsers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get users error: "+errInRetrieveUsers.Error())
+		return nil, errInRetrieveUsers
+	}
+	usersLT := model.BuildLookUpTableForUserExport(users)
+
+	// get active temporary grants of team members
+	now := time.Now().UTC()
+	temporaryGrantsLT := make(map[int][]*model.TemporaryGrantForExport)
+	if len(teamMembers) > 0 {
+		temporaryGrants, errInRetrieveTemporaryGrants := controller.Storage.TemporaryGrantStorage.RetrieveActiveByTeamIDAndUserIDs(teamMembers[0].TeamID, model.PickUpUserIDsInUserMembers(teamMembers), now)
+		if errInRetrieveTemporaryGrants != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEMPORARY_GRANT, "get temporary grants error: "+errInRetrieveTemporaryGrants.Error())
+			return nil, errInRetrieveTemporaryGrants
+		}
+		temporaryGrantsLT = model.BuildUserIDLookUpTableForActiveTemporaryGrants(temporaryGrants, now)
+	}
+
+	// build export, the pending team member may not have user info
+	teamMembersForExport := make([]*model.TeamMemberWithUserInfoForExport, 0, len(teamMembers))
+	for _, targetTeamMember := range teamMembers {
+		userForExport, hit := usersLT[targetTeamMember.ExportUserID()]
+		if !hit {
+			userForExport = &model.UserForExport{}
+		}
+		userForExport.SetTeamMemberID(targetTeamMember.ExportID())
+		teamMemberForExport := targetTeamMember.ExportWithUserInfo(userForExport)
+		if targetTeamMember.IsStatusPending() {
+			teamMemberForExport = targetTeamMember.ExportWithPendingUserInfo(userForExport)
+		}
+		teamMemberForExport.SetTemporaryGrants(temporaryGrantsLT[targetTeamMember.UserID])
+		teamMembersForExport = append(teamMembersForExport, teamMemberForExport)
+	}
+	return teamMembersForExport, nil
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) ImportTeamMembers(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	dryRunInString, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_DRY_RUN)
+	dryRun := dryRunInString == "true"
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// check team switches first for detailed error message
+	if !team.DoesUserRoleCanInviteMember(teamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CLOSED_THE_PERMISSION, "this team closed the invite permission for your role.")
+		return
+	}
+
+	// validate user role, the invited role will validate in each row
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_INVITE, team)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_INVITE_BY_EMAIL) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get request body
+	rows, errInParse := model.ParseMemberImportCSV(http.MaxBytesReader(c.Writer, c.Request.Body, model.MEMBER_IMPORT_MAX_BODY_SIZE))
+	if errInParse != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+errInParse.Error())
+		return
+	}
+
+	// get team capacity for dry run, the seats taken by the former rows are counted, the import checks capacity again when creating each invite
+	capacity, members, editors, errInRetrieveCapacity := controller.retrieveTeamCapacity(c, teamID)
+	if errInRetrieveCapacity != nil {
+		return
+	}
+
+	// validate & invite each row
+	validate := validator.New()
+	emailsLT := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if errInValidateEmail := validate.Var(row.Email, "required,email"); errInValidateEmail != nil {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_INVALID_EMAIL, "invalid email address.")
+			continue
+		}
+		if emailsLT[row.Email] {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_DUPLICATED, "email duplicated in former rows.")
+			continue
+		}
+		emailsLT[row.Email] = true
+		userRole, avaliable := row.ExportUserRole()
+		if !avaliable || userRole == model.USER_ROLE_OWNER {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_INVALID_ROLE, "invalid user role.")
+			continue
+		}
+		if !attrg.CanInvite(userRole) {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_ACCESS_DENIED, "you can not invite this user role due to access control policy.")
+			continue
+		}
+
+		// check if target email already joined or invited
+		invitedUserID, errInValidateInvitee := controller.validateImportInvitee(teamID, row)
+		if errInValidateInvitee != nil {
+			continue
+		}
+
+		// check team capacity
+		editorIncrement := model.CalculateEditorSeatIncrementForNewMember(userRole)
+		if !capacity.DoesMemberSeatsAvaliable(members, 1) || !capacity.DoesEditorSeatsAvaliable(editors, editorIncrement) {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_CAPACITY_EXCEEDED, "team seats are used up.")
+			continue
+		}
+		members++
+		editors += editorIncrement
+
+		if dryRun {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_VALID, "")
+			continue
+		}
+
+		// create pending team member & invite
+		invite, errInInvite := controller.inviteImportedMember(team, teamMember, invitedUserID, userRole, row.Email)
+		if errors.Is(errInInvite, model.ErrCapacityExceeded) {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_CAPACITY_EXCEEDED, "team seats are used up.")
+			continue
+		}
+		if errInInvite != nil {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_FAILED, errInInvite.Error())
+			continue
+		}
+		row.SetInvited(invite)
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewImportTeamMembersResponse(dryRun, rows))
+	return
+}
+
+func (controller *Controller) ExportTeamMembers(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role, the export includes email of all members, so only the member managers can export it
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get team members
+	teamMembers, errInRetrieveTeamMembers := controller.Storage.TeamMemberStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveTeamMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "get team members error: "+errInRetrieveTeamMembers.Error())
+		return
+	}
+	teamMembersForExport, errInExport := controller.exportTeamMembersWithUserInfo(c, teamMembers)
+	if errInExport != nil {
+		return
+	}
+
+	// feedback, stream the csv to client
+	fileName := "team-members-" + time.Now().UTC().Format("20060102150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=\""+fileName+"\"")
+	c.Status(http.StatusOK)
+	csvWriter := csv.NewWriter(c.Writer)
+	csvWriter.Write(model.TeamMemberCSVHeader)
+	for _, teamMemberForExport := range teamMembersForExport {
+		csvWriter.Write(teamMemberForExport.ExportForCSV())
+	}
+	csvWriter.Flush()
+	return
+}
+
+// check if the imported email already joined or invited, the result will set in the row.
+func (controller *Controller) validateImportInvitee(teamID int, row *model.MemberImportRow) (int, error) {
+	invitedUserID := model.PENDING_USER_ID
+	invitedUser, errInRetrieveInvitedUser := controller.Storage.UserStorage.RetrieveByEmail(row.Email)
+	if errInRetrieveInvitedUser == nil {
+		invitedUserID = invitedUser.ExportID()
+		joined, errInCheckJoined := controller.Storage.TeamMemberStorage.DoesTeamIncludedTargetUser(teamID, invitedUserID)
+		if errInCheckJoined != nil {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_FAILED, "check team member error: "+errInCheckJoined.Error())
+			return 0, errInCheckJoined
+		}
+		if joined {
+			row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_ALREADY_JOINED, "target user already joined this team.")
+			return 0, errors.New("already joined")
+		}
+	}
+	invited, errInCheckInvited := controller.Storage.InviteStorage.DoesEmailHasPendingInvite(teamID, row.Email)
+	if errInCheckInvited != nil {
+		row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_FAILED, "check pending invite error: "+errInCheckInvited.Error())
+		return 0, errInCheckInvited
+	}
+	if invited {
+		row.SetResult(model.MEMBER_IMPORT_ROW_STATUS_ALREADY_INVITED, "target email already invited, please resend the invite.")
+		return 0, errors.New("already invited")
+	}
+	return invitedUserID, nil
+}
+
+// create the pending team member and the invite in one transaction, then send the invite email.
+func (controller *Controller) inviteImportedMember(team *model.Team, teamMember *model.TeamMember, invitedUserID int, userRole int, email string) (*model.Invite, error) {
+	teamID := team.ExportID()
+	targetTeamMember := model.NewPendingTeamMember(teamID, invitedUserID, userRole)
+	invite := model.NewEmailInvite(teamMember, targetTeamMember, email)
+	errInCreateInvite := controller.Storage.InviteStorage.CreateWithTargetTeamMember(invite, targetTeamMember)
+	if errors.Is(errInCreateInvite, model.ErrCapacityExceeded) {
+		return nil, errInCreateInvite
+	}
+	if errInCreateInvite != nil {
+		return nil, errors.New("create invite error: " + errInCreateInvite.Error())
+	}
+
+	// the invite has been created, so the row is invited even the email status is not recorded, the invite can be resent later
+	if errInSend := controller.sendInviteEmail(team, teamMember.ExportUserID(), invite); errInSend != nil {
+		log.Println("record invite email status of " + email + " failed: " + errInSend.Error())
+	}
+	return invite, nil
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetTeamMemberPermission(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, targetTeamMember, errInValidate := controller.validateTeamMemberPermissionOperator(c, teamID, userID, targetTeamMemberID)
+	if errInValidate != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTeamMemberPermissionResponse(targetTeamMember))
+	return
+}
+
+func (controller *Controller) UpdateTeamMemberPermission(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateTeamMemberPermissionRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, targetTeamMember, errInValidate := controller.validateTeamMemberPermissionOperator(c, teamID, userID, targetTeamMemberID)
+	if errInValidate != nil {
+		return
+	}
+	if targetTeamMember.IsOwner() || targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "can not change permission of team owner or yourself.")
+		return
+	}
+
+	// validate granted attributes, the operator can not grant the attribute he does not have
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+	for _, override := range req.ExportOverrides() {
+		if !override.IsAllow() {
+			continue
+		}
+		attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, override.UnitType, team)
+		if errInBuildAttributeGroup != nil {
+			return
+		}
+		attrg.SetUnitID(override.UnitID)
+		if !attrg.CanGrantPermissionOverride(override) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not grant the attribute you do not have.")
+			return
+		}
+	}
+
+	// update
+	permission := targetTeamMember.ExportPermission()
+	permission.SetOverrides(req.ExportOverrides())
+	targetTeamMember.SetPermission(permission)
+	if errInUpdate := controller.Storage.TeamMemberStorage.Update(targetTeamMember); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member permission error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_PERMISSION_CHANGED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member permission changed event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTeamMemberPermissionResponse(targetTeamMember))
+	return
+}
+
+// check if the user can manage permission of the target team member, the error will feedback by this method.
+func (controller *Controller) validateTeamMemberPermissionOperator(c *gin.Context, teamID int, userID int, targetTeamMemberID int) (*model.TeamMember, *model.TeamMember, error) {
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return nil, nil, errInRetrieveTeamMember
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return nil, nil, errInRetrieveTargetTeamMember
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return nil, nil, errInBuildAttributeGroup
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_MANAGE_MEMBER_PERMISSION) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, nil, errors.New("access denied")
+	}
+	if !targetTeamMember.IsOwner() && !attrg.CanRemove(targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, nil, errors.New("access denied")
+	}
+	return teamMember, targetTeamMember, nil
+}
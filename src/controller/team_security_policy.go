@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/authenticator"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetTeamSecurityPolicy(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user, all members should know the policy they need to comply with
+	_, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// retrieve
+	policy, errInRetrievePolicy := controller.Storage.TeamSecurityPolicyStorage.RetrieveByTeamID(teamID)
+	if errInRetrievePolicy != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_SECURITY_POLICY, "get team security policy error: "+errInRetrievePolicy.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamSecurityPolicyResponse(policy))
+	return
+}
+
+func (controller *Controller) UpdateTeamSecurityPolicy(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	accessToken, errInGetAccessToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION)
+	if errInGetAccessToken != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateTeamSecurityPolicyRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// only the owner can set security policy
+	if !teamMember.IsOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "only the team owner can update security policy.")
+		return
+	}
+
+	// retrieve
+	policy, errInRetrievePolicy := controller.Storage.TeamSecurityPolicyStorage.RetrieveByTeamID(teamID)
+	if errInRetrievePolicy != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_SECURITY_POLICY, "get team security policy error: "+errInRetrievePolicy.Error())
+		return
+	}
+	policy.UpdateByRequest(req, userID)
+
+	// the owner should not lock themselves out by the new policy
+	session, errInExtractSession := authenticator.ExtractAccessSessionFromToken(accessToken)
+	if errInExtractSession != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "extract session from access token error: "+errInExtractSession.Error())
+		return
+	}
+	if violation := policy.Check(session, time.Now().UTC()); violation != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_SECURITY_POLICY_LOCKOUT, "your current session does not comply with the new policy: "+violation.ErrorMessage)
+		return
+	}
+
+	// update
+	if errInUpdate := controller.Storage.TeamSecurityPolicyStorage.Upsert(policy); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_SECURITY_POLICY, "update team security policy error: "+errInUpdate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamSecurityPolicyResponse(policy))
+	return
+}
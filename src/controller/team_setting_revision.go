@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetTeamSettingsHistory(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	beforeVersion, limit, errInGetPage := controller.getTeamSettingsHistoryPage(c)
+	if errInGetPage != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role, the history is visible to who can manage team config
+	if _, errInValidate := controller.validateTeamSettingsManager(c, teamMember); errInValidate != nil {
+		return
+	}
+
+	// retrieve one more revision to know if there are more
+	revisions, errInRetrieveRevisions := controller.Storage.TeamSettingRevisionStorage.RetrieveByTeamIDBeforeVersion(teamID, beforeVersion, limit+1)
+	if errInRetrieveRevisions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_SETTING_REVISION, "get team settings history error: "+errInRetrieveRevisions.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllTeamSettingRevisionsResponse(revisions, limit))
+	return
+}
+
+func (controller *Controller) RollbackTeamSettings(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	version, errInGetVersion := controller.GetIntParamFromRequest(c, PARAM_VERSION)
+	if errInGetVersion != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role, rollback changes both team config and team permission
+	attrg, errInValidate := controller.validateTeamSettingsManager(c, teamMember)
+	if errInValidate != nil {
+		return
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve target revision
+	revision, errInRetrieveRevision := controller.Storage.TeamSettingRevisionStorage.RetrieveByTeamIDAndVersion(teamID, version)
+	if errInRetrieveRevision != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_SETTING_REVISION, "get team settings revision error: "+errInRetrieveRevision.Error())
+		return
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// the identifier of revision may be used by other team after the revision
+	settings := revision.ExportSettings()
+	if settings.Identifier != team.Identifier {
+		identifierUsed, errInCheckIdentifier := controller.Storage.TeamStorage.IsIdentifierUsedByOtherTeam(teamID, settings.Identifier)
+		if errInCheckIdentifier != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "check team identifier error: "+errInCheckIdentifier.Error())
+			return
+		}
+		if identifierUsed {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_IDENTIFIER_CONFLICT, "the team identifier of this revision is used by other team.")
+			return
+		}
+	}
+
+	// rollback
+	before := model.NewTeamSettingsByTeam(team)
+	settings.ApplyToTeam(team)
+	rollbackRevision := model.NewTeamSettingRevision(model.TEAM_SETTING_REVISION_CATEGORY_ROLLBACK, before, model.NewTeamSettingsByTeam(team), userID)
+	rollbackRevision.SetRollbackFrom(version)
+	if errInUpdate := controller.updateTeamSettings(c, team, rollbackRevision); errInUpdate != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTeamByTeamIDResponse(team))
+	return
+}
+
+// get the page of history from URI, the before version is 0 for the first page and the limit is capped.
+// the error will feedback by this method.
+func (controller *Controller) getTeamSettingsHistoryPage(c *gin.Context) (int, int, error) {
+	beforeVersion := 0
+	if beforeVersionInString, errInGetBeforeVersion := controller.TestFirstStringParamValueFromURI(c, PARAM_BEFORE_VERSION); errInGetBeforeVersion == nil {
+		parsed, errInParse := strconv.Atoi(beforeVersionInString)
+		if errInParse != nil || parsed <= 0 {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "please input beforeVersion as positive int.")
+			return 0, 0, errors.New("invalid beforeVersion.")
+		}
+		beforeVersion = parsed
+	}
+	limit := model.TEAM_SETTING_REVISION_PAGE_DEFAULT_LIMIT
+	if limitInString, errInGetLimit := controller.TestFirstStringParamValueFromURI(c, PARAM_LIMIT); errInGetLimit == nil {
+		parsed, errInParse := strconv.Atoi(limitInString)
+		if errInParse != nil || parsed <= 0 {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "please input limit as positive int.")
+			return 0, 0, errors.New("invalid limit.")
+		}
+		limit = parsed
+	}
+	if limit > model.TEAM_SETTING_REVISION_PAGE_MAX_LIMIT {
+		limit = model.TEAM_SETTING_REVISION_PAGE_MAX_LIMIT
+	}
+	return beforeVersion, limit, nil
+}
+
+// validate the team member can manage team config, the error will feedback by this method.
+func (controller *Controller) validateTeamSettingsManager(c *gin.Context, teamMember *model.TeamMember) (*accesscontrol.AttributeGroup, error) {
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM)
+	if errInBuildAttributeGroup != nil {
+		return nil, errInBuildAttributeGroup
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_CONFIG) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, errors.New("access denied.")
+	}
+	return attrg, nil
+}
+
+// update team settings with the revision, nothing will be stored when the settings are not changed.
+// the error will feedback by this method.
+func (controller *Controller) updateTeamSettings(c *gin.Context, team *model.Team, revision *model.TeamSettingRevision) error {
+	if !revision.HasChanges() {
+		return nil
+	}
+	if err := controller.Storage.TeamStorage.UpdateSettings(team, revision); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+err.Error())
+		return err
+	}
+
+	// notify other units, the team permission switches may be changed
+	controller.publishTeamAccessControlChanged(team.ExportID(), revision.CreatedBy)
+	return nil
+}
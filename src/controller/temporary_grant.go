@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetTemporaryGrants(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, targetTeamMember, errInValidate := controller.validateTeamMemberPermissionOperator(c, teamID, userID, targetTeamMemberID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get active and scheduled grants
+	now := time.Now().UTC()
+	temporaryGrants, errInRetrieveTemporaryGrants := controller.Storage.TemporaryGrantStorage.RetrieveUnexpiredByTeamIDAndUserID(teamID, targetTeamMember.UserID, now)
+	if errInRetrieveTemporaryGrants != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEMPORARY_GRANT, "get temporary grants error: "+errInRetrieveTemporaryGrants.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetTemporaryGrantsResponse(temporaryGrants, now))
+	return
+}
+
+func (controller *Controller) CreateTemporaryGrant(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewTemporaryGrantRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	now := time.Now().UTC()
+	if errInValidateWindow := req.ValidateWindow(now); errInValidateWindow != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+errInValidateWindow.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, targetTeamMember, errInValidate := controller.validateTeamMemberPermissionOperator(c, teamID, userID, targetTeamMemberID)
+	if errInValidate != nil {
+		return
+	}
+	if targetTeamMember.IsOwner() || targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "can not grant temporary access to team owner or yourself.")
+		return
+	}
+
+	// validate granted attributes, the attributes out of operator permissions can not be granted
+	if errInValidateGrants := controller.validateTemporaryGrantPermissions(c, teamMember, req); errInValidateGrants != nil {
+		return
+	}
+
+	// create
+	temporaryGrant := model.NewTemporaryGrantByRequest(teamID, targetTeamMember.UserID, userID, req, now)
+	if _, errInCreate := controller.Storage.TemporaryGrantStorage.Create(temporaryGrant); errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_TEMPORARY_GRANT, "create temporary grant error: "+errInCreate.Error())
+		return
+	}
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_PERMISSION_CHANGED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member permission changed event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTemporaryGrantResponse(temporaryGrant, now))
+	return
+}
+
+// revoke the grant before its end time.
+func (controller *Controller) RevokeTemporaryGrant(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	temporaryGrantID, errInGetTemporaryGrantID := controller.GetMagicIntParamFromRequest(c, PARAM_TEMPORARY_GRANT_ID)
+	if errInGetTargetTeamMemberID != nil || errInGetTemporaryGrantID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, targetTeamMember, errInValidate := controller.validateTeamMemberPermissionOperator(c, teamID, userID, targetTeamMemberID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get grant
+	temporaryGrant, errInRetrieveTemporaryGrant := controller.Storage.TemporaryGrantStorage.RetrieveByTeamIDAndUserIDAndID(teamID, targetTeamMember.UserID, temporaryGrantID)
+	if errInRetrieveTemporaryGrant != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEMPORARY_GRANT, "get temporary grant error: "+errInRetrieveTemporaryGrant.Error())
+		return
+	}
+
+	// end the grant now, the expired grant needs nothing to do
+	now := time.Now().UTC()
+	if !temporaryGrant.EndAt.After(now) {
+		controller.FeedbackOK(c, model.NewTemporaryGrantResponse(temporaryGrant, now))
+		return
+	}
+	if errInEnd := controller.Storage.TemporaryGrantStorage.EndByTeamIDAndID(teamID, temporaryGrant.ExportID(), now); errInEnd != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEMPORARY_GRANT, "revoke temporary grant error: "+errInEnd.Error())
+		return
+	}
+	temporaryGrant.EndAt = now
+	temporaryGrant.UpdatedAt = now
+
+	// notify other units
+	event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_PERMISSION_CHANGED, targetTeamMember, userID)
+	if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+		log.Println("publish team member permission changed event failed: " + errInPublish.Error())
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTemporaryGrantResponse(temporaryGrant, now))
+	return
+}
+
+// the grant can only carry the attributes which the operator can grant, includes the permissions of granted role.
+// the error will feedback by this method.
+func (controller *Controller) validateTemporaryGrantPermissions(c *gin.Context, teamMember *model.TeamMember, req *model.TemporaryGrantRequest) error {
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamMember.TeamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return errInRetrieveTeam
+	}
+	permissions := req.ExportPermissions()
+	if roleID := req.ExportRoleIDInInt(); roleID != 0 {
+		role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamMember.TeamID, roleID)
+		if errInRetrieveRole != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+			return errInRetrieveRole
+		}
+		permissions = append(permissions, role.ExportPermissions()...)
+	}
+	return controller.validateRoleGrants(c, teamMember, team, permissions)
+}
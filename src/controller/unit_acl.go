@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetUnitACL(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	unitType, errInGetUnitType := controller.GetIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	if errInGetUnitType != nil || errInGetUnitID != nil {
+		return
+	}
+
+	// validate user & user role
+	if errInValidate := controller.validateUnitACLManager(c, teamID, userID); errInValidate != nil {
+		return
+	}
+
+	// retrieve
+	unitACL, errInRetrieveUnitACL := controller.Storage.UnitRoleRelationStorage.RetrieveByTeamIDAndUnit(teamID, unitType, unitID)
+	if errInRetrieveUnitACL != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_ACL, "get unit ACL error: "+errInRetrieveUnitACL.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUnitACLResponse(teamID, unitType, unitID, unitACL))
+	return
+}
+
+func (controller *Controller) UpdateUnitACL(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	unitType, errInGetUnitType := controller.GetIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	if errInGetUnitType != nil || errInGetUnitID != nil {
+		return
+	}
+	if unitID == accesscontrol.DEFAULT_UNIT_ID {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "the unit id is required.")
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateUnitACLRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	unitACL, errInExport := req.ExportUnitRoleRelations(teamID, unitType, unitID)
+	if errInExport != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+errInExport.Error())
+		return
+	}
+
+	// validate user & user role
+	if errInValidate := controller.validateUnitACLManager(c, teamID, userID); errInValidate != nil {
+		return
+	}
+
+	// validate subjects
+	if errInValidateSubjects := controller.validateUnitACLSubjects(c, teamID, unitACL); errInValidateSubjects != nil {
+		return
+	}
+
+	// update
+	if errInUpdate := controller.Storage.UnitRoleRelationStorage.ReplaceByTeamIDAndUnit(teamID, unitType, unitID, unitACL); errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_UNIT_ACL, "update unit ACL error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUnitACLResponse(teamID, unitType, unitID, unitACL))
+	return
+}
+
+// check if the user can manage unit ACL, the error will feedback by this method.
+func (controller *Controller) validateUnitACLManager(c *gin.Context, teamID int, userID int) error {
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return errInRetrieveTeamMember
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_UNIT_ROLE_RELATIONS)
+	if errInBuildAttributeGroup != nil {
+		return errInBuildAttributeGroup
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_MANAGE_UNIT_ACL) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return errors.New("access denied.")
+	}
+	return nil
+}
+
+// the roles, user groups and users in ACL must belong to this team, the error will feedback by this method.
+func (controller *Controller) validateUnitACLSubjects(c *gin.Context, teamID int, unitACL []*model.UnitRoleRelation) error {
+	roleIDs := model.PickUpRoleIDsInUnitRoleRelations(unitACL)
+	if len(roleIDs) > 0 {
+		roles, errInRetrieveRoles := controller.Storage.RoleStorage.RetrieveByTeamIDAndIDs(teamID, roleIDs)
+		if errInRetrieveRoles != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get roles error: "+errInRetrieveRoles.Error())
+			return errInRetrieveRoles
+		}
+		if len(roles) != len(roleIDs) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "target roles not found.")
+			return errors.New("target roles not found.")
+		}
+	}
+	userGroupIDs := model.PickUpUserGroupIDsInUnitRoleRelations(unitACL)
+	if len(userGroupIDs) > 0 {
+		userGroups, errInRetrieveUserGroups := controller.Storage.UserGroupStorage.RetrieveByTeamIDAndIDs(teamID, userGroupIDs)
+		if errInRetrieveUserGroups != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user groups error: "+errInRetrieveUserGroups.Error())
+			return errInRetrieveUserGroups
+		}
+		if len(userGroups) != len(userGroupIDs) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "target user groups not found.")
+			return errors.New("target user groups not found.")
+		}
+	}
+	userIDs := model.PickUpUserIDsInUnitRoleRelations(unitACL)
+	if len(userIDs) > 0 {
+		teamMembers, errInRetrieveTeamMembers := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserIDs(teamID, userIDs)
+		if errInRetrieveTeamMembers != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team members error: "+errInRetrieveTeamMembers.Error())
+			return errInRetrieveTeamMembers
+		}
+		if len(teamMembers) != len(userIDs) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "target team members not found.")
+			return errors.New("target team members not found.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetUnitAncestors(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	unit, errInGetUnit := controller.getUnitReferenceForUnitRelation(c)
+	if errInGetUnit != nil {
+		return
+	}
+
+	// retrieve
+	ancestors, errInRetrieveAncestors := controller.Storage.UnitRelationStorage.RetrieveAncestorsByTeamIDAndUnit(teamID, unit)
+	if errInRetrieveAncestors != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_RELATION, "get unit ancestors error: "+errInRetrieveAncestors.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUnitAncestorsResponse(teamID, unit, ancestors))
+	return
+}
+
+func (controller *Controller) SetUnitParent(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	unit, errInGetUnit := controller.getUnitReferenceForUnitRelation(c)
+	if errInGetUnit != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewSetUnitParentRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	parent := req.ExportParent()
+	if !accesscontrol.CanBeParentUnitType(unit.UnitType, parent.UnitType) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_UNIT_PARENT_NOT_ALLOWED, "the unit type can not be child of parent unit type.")
+		return
+	}
+
+	// update
+	if errInUpsert := controller.Storage.UnitRelationStorage.Upsert(model.NewUnitRelation(teamID, unit, parent)); errInUpsert != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_UNIT_RELATION, "update unit relation error: "+errInUpsert.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, model.SUPERVISOR_EVENT_OPERATOR_SYSTEM)
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUnitAncestorsResponse(teamID, unit, []*model.UnitReference{parent}))
+	return
+}
+
+// detach the unit from its parent, the unit will inherit from team only.
+func (controller *Controller) DeleteUnitParent(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	unit, errInGetUnit := controller.getUnitReferenceForUnitRelation(c)
+	if errInGetUnit != nil {
+		return
+	}
+
+	// delete
+	if errInDelete := controller.Storage.UnitRelationStorage.DeleteByTeamIDAndUnit(teamID, unit); errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_UNIT_RELATION, "delete unit relation error: "+errInDelete.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, model.SUPERVISOR_EVENT_OPERATOR_SYSTEM)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// clean up the relations of a removed unit, its children will not inherit from it any more.
+func (controller *Controller) DeleteUnitRelations(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	unit, errInGetUnit := controller.getUnitReferenceForUnitRelation(c)
+	if errInGetUnit != nil {
+		return
+	}
+
+	// delete
+	if errInDelete := controller.Storage.UnitRelationStorage.DeleteByTeamIDAndUnitOrParentUnit(teamID, unit); errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_UNIT_RELATION, "delete unit relations error: "+errInDelete.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, model.SUPERVISOR_EVENT_OPERATOR_SYSTEM)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// get target unit and validate the request token, the error will feedback by this method.
+func (controller *Controller) getUnitReferenceForUnitRelation(c *gin.Context) (*model.UnitReference, error) {
+	unitType, errInGetUnitType := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	if errInGetUnitType != nil {
+		return nil, errInGetUnitType
+	}
+	if errInGetUnitID != nil {
+		return nil, errInGetUnitID
+	}
+
+	teamIDString, errInGetTeamIDString := controller.GetStringParamFromRequest(c, PARAM_TEAM_ID)
+	unitTypeString, errInGetUnitTypeString := controller.GetStringParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitIDString, errInGetUnitIDString := controller.GetStringParamFromRequest(c, PARAM_UNIT_ID)
+	if errInGetTeamIDString != nil || errInGetUnitTypeString != nil || errInGetUnitIDString != nil {
+		return nil, errors.New("get request param failed.")
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, teamIDString, unitTypeString, unitIDString)
+	if !validated && errInValidate != nil {
+		return nil, errInValidate
+	}
+	if unitID == accesscontrol.DEFAULT_UNIT_ID {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "the unit id is required.")
+		return nil, errors.New("the unit id is required.")
+	}
+	return model.NewUnitReference(unitType, unitID), nil
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -90,6 +91,9 @@ func (controller *Controller) SignIn(c *gin.Context) {
 	}
 	c.Header("illa-token", accessToken)
 
+	// join the teams by verified email domain
+	controller.joinTeamsByEmailDomain(user)
+
 	// ok, feedback
 	controller.FeedbackOK(c, model.NewSignInResponse(user))
 	return
@@ -441,6 +445,13 @@ func (controller *Controller) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	// retrieve the teams joined, they will be notified after deleted
+	teamMembers, errInRetrieveTeamMembers := controller.Storage.TeamMemberStorage.RetrieveByUserID(userID)
+	if errInRetrieveTeamMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "get team members by user id error: "+errInRetrieveTeamMembers.Error())
+		return
+	}
+
 	// delete
 	errInDeleteUser := controller.Storage.UserStorage.DeleteByID(userID)
 	if errInDeleteUser != nil {
@@ -455,6 +466,14 @@ func (controller *Controller) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	// notify other units
+	for _, teamMember := range teamMembers {
+		event := model.NewSupervisorEvent(model.SUPERVISOR_EVENT_TEAM_MEMBER_LEFT, teamMember, userID)
+		if errInPublish := controller.Cache.EventPublisher.Publish(event); errInPublish != nil {
+			log.Println("publish team member left event failed: " + errInPublish.Error())
+		}
+	}
+
 	// ok, feedback
 	controller.FeedbackOK(c, nil)
 	return
@@ -0,0 +1,480 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetAllUserGroups(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get user groups with members
+	userGroups, errInRetrieveUserGroups := controller.Storage.UserGroupStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveUserGroups != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user groups error: "+errInRetrieveUserGroups.Error())
+		return
+	}
+	userGroupMembers, errInRetrieveUserGroupMembers := controller.Storage.UserGroupMemberStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveUserGroupMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user group members error: "+errInRetrieveUserGroupMembers.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllUserGroupsResponse(userGroups, userGroupMembers))
+	return
+}
+
+func (controller *Controller) CreateUserGroup(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUserGroupRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateUserGroupManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateGrants := controller.validateUserGroupGrants(c, teamMember, team, req.UserRole, req.ExportOverrides()); errInValidateGrants != nil {
+		return
+	}
+
+	// check name
+	if errInValidateName := controller.validateUserGroupName(c, teamID, req.Name, 0); errInValidateName != nil {
+		return
+	}
+
+	// create
+	userGroup := model.NewUserGroupByCreateRequest(teamID, req)
+	userGroup.SetEditorSeat(accesscontrol.DoesUserGroupTakeEditorSeat(userGroup))
+	if _, errInCreate := controller.Storage.UserGroupStorage.Create(userGroup); errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_USER_GROUP, "create user group error: "+errInCreate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUserGroupResponse(userGroup, nil))
+	return
+}
+
+func (controller *Controller) GetUserGroup(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userGroupID, errInGetUserGroupID := controller.GetMagicIntParamFromRequest(c, PARAM_USER_GROUP_ID)
+	if errInGetUserGroupID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroup(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInBuildAttributeGroup != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get user group with members
+	userGroup, userGroupMembers, errInRetrieve := controller.retrieveUserGroupWithMembers(c, teamID, userGroupID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUserGroupResponse(userGroup, model.PickUpTeamMemberIDsInUserGroupMembers(userGroupMembers)))
+	return
+}
+
+func (controller *Controller) UpdateUserGroup(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userGroupID, errInGetUserGroupID := controller.GetMagicIntParamFromRequest(c, PARAM_USER_GROUP_ID)
+	if errInGetUserGroupID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUserGroupRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateUserGroupManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+	if errInValidateGrants := controller.validateUserGroupGrants(c, teamMember, team, req.UserRole, req.ExportOverrides()); errInValidateGrants != nil {
+		return
+	}
+
+	// get user group with members
+	userGroup, userGroupMembers, errInRetrieve := controller.retrieveUserGroupWithMembers(c, teamID, userGroupID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// check name
+	if errInValidateName := controller.validateUserGroupName(c, teamID, req.Name, userGroup.ExportID()); errInValidateName != nil {
+		return
+	}
+
+	// update
+	userGroup.UpdateByRequest(req)
+	userGroup.SetEditorSeat(accesscontrol.DoesUserGroupTakeEditorSeat(userGroup))
+	errInUpdate := controller.Storage.UserGroupStorage.UpdateByID(userGroup)
+	if errors.Is(errInUpdate, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team editor seats are used up by the members of this user group.")
+		return
+	}
+	if errInUpdate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER_GROUP, "update user group error: "+errInUpdate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, model.NewUserGroupResponse(userGroup, model.PickUpTeamMemberIDsInUserGroupMembers(userGroupMembers)))
+	return
+}
+
+func (controller *Controller) DeleteUserGroup(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userGroupID, errInGetUserGroupID := controller.GetMagicIntParamFromRequest(c, PARAM_USER_GROUP_ID)
+	if errInGetUserGroupID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateUserGroupManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get user group
+	userGroup, errInRetrieveUserGroup := controller.Storage.UserGroupStorage.RetrieveByTeamIDAndID(teamID, userGroupID)
+	if errInRetrieveUserGroup != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user group error: "+errInRetrieveUserGroup.Error())
+		return
+	}
+
+	// delete user group with members
+	if errInDelete := controller.Storage.UserGroupStorage.DeleteByTeamIDAndID(teamID, userGroup.ExportID()); errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_USER_GROUP, "delete user group error: "+errInDelete.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) AddUserGroupMembers(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userGroupID, errInGetUserGroupID := controller.GetMagicIntParamFromRequest(c, PARAM_USER_GROUP_ID)
+	if errInGetUserGroupID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateUserGroupMembersRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user & user role
+	teamMember, team, errInValidate := controller.validateUserGroupManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// get user group
+	userGroup, errInRetrieveUserGroup := controller.Storage.UserGroupStorage.RetrieveByTeamIDAndID(teamID, userGroupID)
+	if errInRetrieveUserGroup != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user group error: "+errInRetrieveUserGroup.Error())
+		return
+	}
+
+	// the members can only be added when the operator can grant the role and attributes of user group
+	if errInValidateGrants := controller.validateUserGroupGrants(c, teamMember, team, userGroup.UserRole, userGroup.ExportPermissionOverrides()); errInValidateGrants != nil {
+		return
+	}
+
+	// the target team members must be in this team, the owner can not be grouped since he already has all attributes
+	targetTeamMemberIDs := req.ExportTeamMemberIDsInInt()
+	targetTeamMembers, errInRetrieveTargetTeamMembers := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndIDs(teamID, targetTeamMemberIDs)
+	if errInRetrieveTargetTeamMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team members error: "+errInRetrieveTargetTeamMembers.Error())
+		return
+	}
+	if len(targetTeamMembers) != len(targetTeamMemberIDs) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "target team members not found.")
+		return
+	}
+	for _, targetTeamMember := range targetTeamMembers {
+		if targetTeamMember.IsOwner() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER_GROUP, "can not add team owner into user group.")
+			return
+		}
+	}
+
+	// add members
+	errInCreate := controller.Storage.UserGroupMemberStorage.CreateByTeamIDAndUserGroupIDsAndTeamMemberIDs(teamID, []int{userGroup.ExportID()}, model.PickUpTeamMemberIDsInTeamMembers(targetTeamMembers))
+	if errors.Is(errInCreate, model.ErrCapacityExceeded) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_CAPACITY_EXCEEDED, "team editor seats are used up.")
+		return
+	}
+	if errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER_GROUP, "add user group members error: "+errInCreate.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback with latest members
+	_, userGroupMembers, errInRetrieve := controller.retrieveUserGroupWithMembers(c, teamID, userGroupID)
+	if errInRetrieve != nil {
+		return
+	}
+	controller.FeedbackOK(c, model.NewUserGroupResponse(userGroup, model.PickUpTeamMemberIDsInUserGroupMembers(userGroupMembers)))
+	return
+}
+
+func (controller *Controller) RemoveUserGroupMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+	userGroupID, errInGetUserGroupID := controller.GetMagicIntParamFromRequest(c, PARAM_USER_GROUP_ID)
+	if errInGetUserGroupID != nil {
+		return
+	}
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_MEMBER_ID)
+	if errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user & user role
+	_, _, errInValidate := controller.validateUserGroupManager(c, teamID, userID)
+	if errInValidate != nil {
+		return
+	}
+
+	// remove member
+	errInDelete := controller.Storage.UserGroupMemberStorage.DeleteByTeamIDAndUserGroupIDAndTeamMemberID(teamID, userGroupID, targetTeamMemberID)
+	if errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER_GROUP, "remove user group member error: "+errInDelete.Error())
+		return
+	}
+
+	// notify other units
+	controller.publishTeamAccessControlChanged(teamID, userID)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// check if the user can manage user groups, the error will feedback by this method.
+func (controller *Controller) validateUserGroupManager(c *gin.Context, teamID int, userID int) (*model.TeamMember, *model.Team, error) {
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.RetrieveAvaliableTeamMember(c, teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		return nil, nil, errInRetrieveTeamMember
+	}
+
+	// get team by id
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return nil, nil, errInRetrieveTeam
+	}
+
+	// validate user role
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER, team)
+	if errInBuildAttributeGroup != nil {
+		return nil, nil, errInBuildAttributeGroup
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_MANAGE_USER_GROUP) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return nil, nil, errors.New("access denied.")
+	}
+	return teamMember, team, nil
+}
+
+// the user group can only grant the role and attributes which the operator can grant, the error will feedback by this method.
+func (controller *Controller) validateUserGroupGrants(c *gin.Context, teamMember *model.TeamMember, team *model.Team, userRole int, overrides []*model.PermissionOverride) error {
+	if userRole != model.USER_GROUP_NO_ROLE {
+		attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER, team)
+		if errInBuildAttributeGroup != nil {
+			return errInBuildAttributeGroup
+		}
+		if !attrg.CanModifyRoleFromTo(model.USER_ROLE_VIEWER, userRole) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not grant this role to user group.")
+			return errors.New("access denied.")
+		}
+	}
+	for _, override := range overrides {
+		if !override.IsAllow() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "user group can only grant attributes.")
+			return errors.New("user group can only grant attributes.")
+		}
+		attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, override.UnitType, team)
+		if errInBuildAttributeGroup != nil {
+			return errInBuildAttributeGroup
+		}
+		attrg.SetUnitID(override.UnitID)
+		if !attrg.CanGrantPermissionOverride(override) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not grant the attribute you do not have.")
+			return errors.New("access denied.")
+		}
+	}
+	return nil
+}
+
+// the user group name is unique in team, the error will feedback by this method.
+func (controller *Controller) validateUserGroupName(c *gin.Context, teamID int, name string, excludedUserGroupID int) error {
+	nameExists, errInCheckName := controller.Storage.UserGroupStorage.DoesNameExist(teamID, name, excludedUserGroupID)
+	if errInCheckName != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "check user group name error: "+errInCheckName.Error())
+		return errInCheckName
+	}
+	if nameExists {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_USER_GROUP_NAME_ALREADY_EXISTS, "user group name already exists.")
+		return errors.New("user group name already exists.")
+	}
+	return nil
+}
+
+// the error will feedback by this method.
+func (controller *Controller) retrieveUserGroupWithMembers(c *gin.Context, teamID int, userGroupID int) (*model.UserGroup, []*model.UserGroupMember, error) {
+	userGroup, errInRetrieveUserGroup := controller.Storage.UserGroupStorage.RetrieveByTeamIDAndID(teamID, userGroupID)
+	if errInRetrieveUserGroup != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user group error: "+errInRetrieveUserGroup.Error())
+		return nil, nil, errInRetrieveUserGroup
+	}
+	userGroupMembers, errInRetrieveUserGroupMembers := controller.Storage.UserGroupMemberStorage.RetrieveByTeamIDAndUserGroupID(teamID, userGroupID)
+	if errInRetrieveUserGroupMembers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user group members error: "+errInRetrieveUserGroupMembers.Error())
+		return nil, nil, errInRetrieveUserGroupMembers
+	}
+	return userGroup, userGroupMembers, nil
+}
+
+// targeting user groups in invites requires the user group manage attribute, the error will feedback by this method.
+func (controller *Controller) validateInviteUserGroups(c *gin.Context, teamMember *model.TeamMember, team *model.Team, userGroupIDs []int) error {
+	if len(userGroupIDs) == 0 {
+		return nil
+	}
+	attrg, errInBuildAttributeGroup := controller.BuildAttributeGroupInTeam(c, teamMember, accesscontrol.UNIT_TYPE_TEAM_MEMBER, team)
+	if errInBuildAttributeGroup != nil {
+		return errInBuildAttributeGroup
+	}
+	if !attrg.CanManageSpecial(accesscontrol.ACTION_SPECIAL_MANAGE_USER_GROUP) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not invite member into user groups due to access control policy.")
+		return errors.New("access denied.")
+	}
+	userGroups, errInRetrieveUserGroups := controller.Storage.UserGroupStorage.RetrieveByTeamIDAndIDs(teamMember.TeamID, userGroupIDs)
+	if errInRetrieveUserGroups != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "get user groups error: "+errInRetrieveUserGroups.Error())
+		return errInRetrieveUserGroups
+	}
+	if len(userGroups) != len(userGroupIDs) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER_GROUP, "target user groups not found.")
+		return errors.New("target user groups not found.")
+	}
+	return nil
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
 )
 
+const PARAM_AUTHORIZATION = "Authorization"
 const PARAM_AUTHORIZATION_TOKEN = "Authorization-Token"
+const PARAM_END_USER_IP = "End-User-IP"
 const PARAM_REQUEST_TOKEN = "Request-Token"
 const PARAM_TEAM_ID = "teamID"
 const PARAM_USER_ID = "userID"
@@ -23,14 +25,30 @@ const PARAM_INVITE_LINK_HASH = "inviteLinkHash"
 const PARAM_UNIT_TYPE = "unitType"
 const PARAM_UNIT_ID = "unitID"
 const PARAM_ATTRIBUTE_ID = "attributeID"
+const PARAM_CATEGORY = "category"
 const PARAM_FROM_ID = "fromID"
 const PARAM_TO_ID = "toID"
 const PARAM_VERSION = "version"
+const PARAM_BEFORE_VERSION = "beforeVersion"
+const PARAM_LIMIT = "limit"
 const PARAM_TARGET_TEAM_MEMBER_ID = "targetTeamMemberID"
+const PARAM_TEAM_MEMBER_ID = "teamMemberID"
+const PARAM_USER_GROUP_ID = "userGroupID"
+const PARAM_ROLE_ID = "roleID"
+const PARAM_TEMPORARY_GRANT_ID = "temporaryGrantID"
+const PARAM_DRY_RUN = "dryRun"
+const PARAM_EMAIL_DOMAIN_ID = "emailDomainID"
+const PARAM_DOMAIN_ID = "domainID"
+const PARAM_HOST = "host"
+const PARAM_INVITE_ID = "inviteID"
+const PARAM_INVITE_HASH = "inviteHash"
 const PARAM_FILE_NAME = "fileName"
 const PARAM_TARGET_USER_IDS = "targetUserIDs"
 const PARAM_REDIRECT_URL = "redirectURL"
 
+// set when the internal request token is validated, the headers passed by other units are only trusted then.
+const CONTEXT_REQUEST_TOKEN_VALIDATED = "requestTokenValidated"
+
 const DEFAULT_TEAM_ID = 0
 
 const (
@@ -43,6 +61,7 @@ const (
 	ERROR_FLAG_PARSE_REQUEST_BODY_FAILED                = "ERROR_FLAG_PARSE_REQUEST_BODY_FAILED"
 	ERROR_FLAG_PARSE_REQUEST_URI_FAILED                 = "ERROR_FLAG_PARSE_REQUEST_URI_FAILED"
 	ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED            = "ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED"
+	ERROR_FLAG_VALIDATE_FILE_NAME_FAILED                = "ERROR_FLAG_VALIDATE_FILE_NAME_FAILED"
 	ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER   = "ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER"
 	ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM           = "ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM"
 	ERROR_FLAG_SIGN_UP_EMAIL_MISMATCH                   = "ERROR_FLAG_SIGN_UP_EMAIL_MISMATCH"
@@ -50,12 +69,26 @@ const (
 	ERROR_FLAG_PASSWORD_INVALIED                        = "ERROR_FLAG_PASSWORD_INVALIED"
 	ERROR_FLAG_TEAM_MUST_TRANSFERED_BEFORE_USER_SUSPEND = "ERROR_FLAG_TEAM_MUST_TRANSFERED_BEFORE_USER_SUSPEND"
 	ERROR_FLAG_INVITE_EMAIL_MISMATCH                    = "ERROR_FLAG_INVITE_EMAIL_MISMATCH"
+	ERROR_FLAG_USER_GROUP_NAME_ALREADY_EXISTS           = "ERROR_FLAG_USER_GROUP_NAME_ALREADY_EXISTS"
+	ERROR_FLAG_ROLE_NAME_ALREADY_EXISTS                 = "ERROR_FLAG_ROLE_NAME_ALREADY_EXISTS"
+	ERROR_FLAG_UNIT_PARENT_NOT_ALLOWED                  = "ERROR_FLAG_UNIT_PARENT_NOT_ALLOWED"
+	ERROR_FLAG_TEAM_CAPACITY_EXCEEDED                   = "ERROR_FLAG_TEAM_CAPACITY_EXCEEDED"
+	ERROR_FLAG_EMAIL_DOMAIN_ALREADY_EXISTS              = "ERROR_FLAG_EMAIL_DOMAIN_ALREADY_EXISTS"
+	ERROR_FLAG_EMAIL_DOMAIN_VERIFICATION_FAILED         = "ERROR_FLAG_EMAIL_DOMAIN_VERIFICATION_FAILED"
+	ERROR_FLAG_DOMAIN_ALREADY_EXISTS                    = "ERROR_FLAG_DOMAIN_ALREADY_EXISTS"
+	ERROR_FLAG_DOMAIN_RESOLVE_FAILED                    = "ERROR_FLAG_DOMAIN_RESOLVE_FAILED"
+	ERROR_FLAG_DOMAIN_CLAIM_EXPIRED                     = "ERROR_FLAG_DOMAIN_CLAIM_EXPIRED"
+	ERROR_FLAG_IDENTIFIER_CONFLICT                      = "ERROR_FLAG_IDENTIFIER_CONFLICT"
 
 	// can note create
 	ERROR_FLAG_CAN_NOT_CREATE_USER            = "ERROR_FLAG_CAN_NOT_CREATE_USER"
 	ERROR_FLAG_CAN_NOT_CREATE_TEAM            = "ERROR_FLAG_CAN_NOT_CREATE_TEAM"
 	ERROR_FLAG_CAN_NOT_CREATE_TEAM_MEMBER     = "ERROR_FLAG_CAN_NOT_CREATE_TEAM_MEMBER"
 	ERROR_FLAG_CAN_NOT_CREATE_INVITE          = "ERROR_FLAG_CAN_NOT_CREATE_INVITE"
+	ERROR_FLAG_CAN_NOT_CREATE_USER_GROUP      = "ERROR_FLAG_CAN_NOT_CREATE_USER_GROUP"
+	ERROR_FLAG_CAN_NOT_CREATE_ROLE            = "ERROR_FLAG_CAN_NOT_CREATE_ROLE"
+	ERROR_FLAG_CAN_NOT_CREATE_TEMPORARY_GRANT = "ERROR_FLAG_CAN_NOT_CREATE_TEMPORARY_GRANT"
+	ERROR_FLAG_CAN_NOT_CREATE_EMAIL_DOMAIN    = "ERROR_FLAG_CAN_NOT_CREATE_EMAIL_DOMAIN"
 	ERROR_FLAG_CAN_NOT_CREATE_INVITATION_CODE = "ERROR_FLAG_CAN_NOT_CREATE_INVITATION_CODE"
 	ERROR_FLAG_CAN_NOT_CREATE_DOMAIN          = "ERROR_FLAG_CAN_NOT_CREATE_DOMAIN"
 	ERROR_FLAG_CAN_NOT_CREATE_ACTION          = "ERROR_FLAG_CAN_NOT_CREATE_ACTION"
@@ -63,25 +96,44 @@ const (
 	ERROR_FLAG_CAN_NOT_CREATE_APP             = "ERROR_FLAG_CAN_NOT_CREATE_APP"
 
 	// can not get resource
-	ERROR_FLAG_CAN_NOT_GET_USER                = "ERROR_FLAG_CAN_NOT_GET_USER"
-	ERROR_FLAG_CAN_NOT_GET_TEAM                = "ERROR_FLAG_CAN_NOT_GET_TEAM"
-	ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER         = "ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER"
-	ERROR_FLAG_CAN_NOT_GET_INVITE              = "ERROR_FLAG_CAN_NOT_GET_INVITE"
-	ERROR_FLAG_CAN_NOT_GET_INVITATION_CODE     = "ERROR_FLAG_CAN_NOT_GET_INVITATION_CODE"
-	ERROR_FLAG_CAN_NOT_GET_DOMAIN              = "ERROR_FLAG_CAN_NOT_GET_DOMAIN"
-	ERROR_FLAG_CAN_NOT_GET_ACTION              = "ERROR_FLAG_CAN_NOT_GET_ACTION"
-	ERROR_FLAG_CAN_NOT_GET_RESOURCE            = "ERROR_FLAG_CAN_NOT_GET_RESOURCE"
-	ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO  = "ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO"
-	ERROR_FLAG_CAN_NOT_GET_APP                 = "ERROR_FLAG_CAN_NOT_GET_APP"
-	ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION = "ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION"
+	ERROR_FLAG_CAN_NOT_GET_USER                  = "ERROR_FLAG_CAN_NOT_GET_USER"
+	ERROR_FLAG_CAN_NOT_GET_TEAM                  = "ERROR_FLAG_CAN_NOT_GET_TEAM"
+	ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER           = "ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER"
+	ERROR_FLAG_CAN_NOT_GET_INVITE                = "ERROR_FLAG_CAN_NOT_GET_INVITE"
+	ERROR_FLAG_CAN_NOT_GET_USER_GROUP            = "ERROR_FLAG_CAN_NOT_GET_USER_GROUP"
+	ERROR_FLAG_CAN_NOT_GET_ROLE                  = "ERROR_FLAG_CAN_NOT_GET_ROLE"
+	ERROR_FLAG_CAN_NOT_GET_UNIT_ACL              = "ERROR_FLAG_CAN_NOT_GET_UNIT_ACL"
+	ERROR_FLAG_CAN_NOT_GET_UNIT_RELATION         = "ERROR_FLAG_CAN_NOT_GET_UNIT_RELATION"
+	ERROR_FLAG_CAN_NOT_GET_TEMPORARY_GRANT       = "ERROR_FLAG_CAN_NOT_GET_TEMPORARY_GRANT"
+	ERROR_FLAG_CAN_NOT_GET_EMAIL_DOMAIN          = "ERROR_FLAG_CAN_NOT_GET_EMAIL_DOMAIN"
+	ERROR_FLAG_CAN_NOT_GET_CAPACITY              = "ERROR_FLAG_CAN_NOT_GET_CAPACITY"
+	ERROR_FLAG_CAN_NOT_GET_INVITATION_CODE       = "ERROR_FLAG_CAN_NOT_GET_INVITATION_CODE"
+	ERROR_FLAG_CAN_NOT_GET_DOMAIN                = "ERROR_FLAG_CAN_NOT_GET_DOMAIN"
+	ERROR_FLAG_CAN_NOT_GET_TEAM_SETTING_REVISION = "ERROR_FLAG_CAN_NOT_GET_TEAM_SETTING_REVISION"
+	ERROR_FLAG_CAN_NOT_GET_SECURITY_POLICY       = "ERROR_FLAG_CAN_NOT_GET_SECURITY_POLICY"
+	ERROR_FLAG_CAN_NOT_GET_IP_ALLOWLIST          = "ERROR_FLAG_CAN_NOT_GET_IP_ALLOWLIST"
+	ERROR_FLAG_CAN_NOT_GET_ACTION                = "ERROR_FLAG_CAN_NOT_GET_ACTION"
+	ERROR_FLAG_CAN_NOT_GET_RESOURCE              = "ERROR_FLAG_CAN_NOT_GET_RESOURCE"
+	ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO    = "ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO"
+	ERROR_FLAG_CAN_NOT_GET_APP                   = "ERROR_FLAG_CAN_NOT_GET_APP"
+	ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION   = "ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION"
 
 	// can not update resource
 	ERROR_FLAG_CAN_NOT_UPDATE_USER            = "ERROR_FLAG_CAN_NOT_UPDATE_USER"
 	ERROR_FLAG_CAN_NOT_UPDATE_TEAM            = "ERROR_FLAG_CAN_NOT_UPDATE_TEAM"
 	ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER     = "ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER"
 	ERROR_FLAG_CAN_NOT_UPDATE_INVITE          = "ERROR_FLAG_CAN_NOT_UPDATE_INVITE"
+	ERROR_FLAG_CAN_NOT_UPDATE_USER_GROUP      = "ERROR_FLAG_CAN_NOT_UPDATE_USER_GROUP"
+	ERROR_FLAG_CAN_NOT_UPDATE_ROLE            = "ERROR_FLAG_CAN_NOT_UPDATE_ROLE"
+	ERROR_FLAG_CAN_NOT_UPDATE_UNIT_ACL        = "ERROR_FLAG_CAN_NOT_UPDATE_UNIT_ACL"
+	ERROR_FLAG_CAN_NOT_UPDATE_UNIT_RELATION   = "ERROR_FLAG_CAN_NOT_UPDATE_UNIT_RELATION"
+	ERROR_FLAG_CAN_NOT_UPDATE_TEMPORARY_GRANT = "ERROR_FLAG_CAN_NOT_UPDATE_TEMPORARY_GRANT"
+	ERROR_FLAG_CAN_NOT_UPDATE_EMAIL_DOMAIN    = "ERROR_FLAG_CAN_NOT_UPDATE_EMAIL_DOMAIN"
+	ERROR_FLAG_CAN_NOT_UPDATE_CAPACITY        = "ERROR_FLAG_CAN_NOT_UPDATE_CAPACITY"
 	ERROR_FLAG_CAN_NOT_UPDATE_INVITATION_CODE = "ERROR_FLAG_CAN_NOT_UPDATE_INVITATION_CODE"
 	ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN          = "ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN"
+	ERROR_FLAG_CAN_NOT_UPDATE_SECURITY_POLICY = "ERROR_FLAG_CAN_NOT_UPDATE_SECURITY_POLICY"
+	ERROR_FLAG_CAN_NOT_UPDATE_IP_ALLOWLIST    = "ERROR_FLAG_CAN_NOT_UPDATE_IP_ALLOWLIST"
 	ERROR_FLAG_CAN_NOT_UPDATE_ACTION          = "ERROR_FLAG_CAN_NOT_UPDATE_ACTION"
 	ERROR_FLAG_CAN_NOT_UPDATE_RESOURCE        = "ERROR_FLAG_CAN_NOT_UPDATE_RESOURCE"
 	ERROR_FLAG_CAN_NOT_UPDATE_APP             = "ERROR_FLAG_CAN_NOT_UPDATE_APP"
@@ -91,6 +143,10 @@ const (
 	ERROR_FLAG_CAN_NOT_DELETE_TEAM            = "ERROR_FLAG_CAN_NOT_DELETE_TEAM"
 	ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER     = "ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER"
 	ERROR_FLAG_CAN_NOT_DELETE_INVITE          = "ERROR_FLAG_CAN_NOT_DELETE_INVITE"
+	ERROR_FLAG_CAN_NOT_DELETE_USER_GROUP      = "ERROR_FLAG_CAN_NOT_DELETE_USER_GROUP"
+	ERROR_FLAG_CAN_NOT_DELETE_ROLE            = "ERROR_FLAG_CAN_NOT_DELETE_ROLE"
+	ERROR_FLAG_CAN_NOT_DELETE_UNIT_RELATION   = "ERROR_FLAG_CAN_NOT_DELETE_UNIT_RELATION"
+	ERROR_FLAG_CAN_NOT_DELETE_EMAIL_DOMAIN    = "ERROR_FLAG_CAN_NOT_DELETE_EMAIL_DOMAIN"
 	ERROR_FLAG_CAN_NOT_DELETE_INVITATION_CODE = "ERROR_FLAG_CAN_NOT_DELETE_INVITATION_CODE"
 	ERROR_FLAG_CAN_NOT_DELETE_DOMAIN          = "ERROR_FLAG_CAN_NOT_DELETE_DOMAIN"
 	ERROR_FLAG_CAN_NOT_DELETE_ACTION          = "ERROR_FLAG_CAN_NOT_DELETE_ACTION"
@@ -106,12 +162,21 @@ const (
 	// permission failed
 	ERROR_FLAG_ACCESS_DENIED                  = "ERROR_FLAG_ACCESS_DENIED"
 	ERROR_FLAG_TEAM_CLOSED_THE_PERMISSION     = "ERROR_FLAG_TEAM_CLOSED_THE_PERMISSION"
+	ERROR_FLAG_TEAM_MEMBER_SUSPENDED          = "ERROR_FLAG_TEAM_MEMBER_SUSPENDED"
+	ERROR_FLAG_TEAM_MEMBER_NOT_ACTIVATED      = "ERROR_FLAG_TEAM_MEMBER_NOT_ACTIVATED"
+	ERROR_FLAG_TEAM_ARCHIVED                  = "ERROR_FLAG_TEAM_ARCHIVED"
+	ERROR_FLAG_TEAM_RESTORE_EXPIRED           = "ERROR_FLAG_TEAM_RESTORE_EXPIRED"
+	ERROR_FLAG_SECURITY_POLICY_LOCKOUT        = "ERROR_FLAG_SECURITY_POLICY_LOCKOUT"
+	ERROR_FLAG_IP_NOT_ALLOWED                 = "ERROR_FLAG_IP_NOT_ALLOWED"
+	ERROR_FLAG_IP_ALLOWLIST_LOCKOUT           = "ERROR_FLAG_IP_ALLOWLIST_LOCKOUT"
 	ERROR_FLAG_EMAIL_ALREADY_USED             = "ERROR_FLAG_EMAIL_ALREADY_USED"
 	ERROR_FLAG_EMAIL_HAS_BEEN_TAKEN           = "ERROR_FLAG_EMAIL_HAS_BEEN_TAKEN"
 	ERROR_FLAG_INVITATION_CODE_ALREADY_USED   = "ERROR_FLAG_INVITATION_CODE_ALREADY_USED"
 	ERROR_FLAG_INVITATION_LINK_UNAVALIABLE    = "ERROR_FLAG_INVITATION_LINK_UNAVALIABLE"
+	ERROR_FLAG_INVITATION_EXPIRED             = "ERROR_FLAG_INVITATION_EXPIRED"
 	ERROR_FLAG_TEAM_IDENTIFIER_HAS_BEEN_TAKEN = "ERROR_FLAG_TEAM_IDENTIFIER_HAS_BEEN_TAKEN"
 	ERROR_FLAG_USER_ALREADY_JOINED_TEAM       = "ERROR_FLAG_USER_ALREADY_JOINED_TEAM"
+	ERROR_FLAG_EMAIL_ALREADY_INVITED          = "ERROR_FLAG_EMAIL_ALREADY_INVITED"
 	ERROR_FLAG_SIGN_IN_FAILED                 = "ERROR_FLAG_SIGN_IN_FAILED"
 	ERROR_FLAG_NO_SUCH_USER                   = "ERROR_FLAG_NO_SUCH_USER"
 	ERROR_FLAG_REGISTER_BLOCKED               = "ERROR_FLAG_REGISTER_BLOCKED"
@@ -121,6 +186,7 @@ const (
 	ERROR_FLAG_SEND_VERIFICATION_CODE_FAILED = "ERROR_FLAG_SEND_VERIFICATION_CODE_FAILED"
 	ERROR_FLAG_CREATE_LINK_FAILED            = "ERROR_FLAG_CREATE_LINK_FAILED"
 	ERROR_FLAG_CREATE_UPLOAD_URL_FAILED      = "ERROR_FLAG_CREATE_UPLOAD_URL_FAILED"
+	ERROR_FLAG_UPLOADED_OBJECT_NOT_FOUND     = "ERROR_FLAG_UPLOADED_OBJECT_NOT_FOUND"
 	ERROR_FLAG_EXECUTE_ACTION_FAILED         = "ERROR_FLAG_EXECUTE_ACTION_FAILED"
 	ERROR_FLAG_GENERATE_SQL_FAILED           = "ERROR_FLAG_GENERATE_SQL_FAILED"
 
@@ -151,6 +217,7 @@ func (controller *Controller) ValidateRequestTokenFromHeader(c *gin.Context, inp
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "request token mismatch.")
 		return false, errors.New("request token mismatch.")
 	}
+	c.Set(CONTEXT_REQUEST_TOKEN_VALIDATED, true)
 	return true, nil
 }
 
@@ -170,6 +237,7 @@ func (controller *Controller) ValidateRequestTokenFromHeaderByStringMap(c *gin.C
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "request token mismatch.")
 		return false, errors.New("request token mismatch.")
 	}
+	c.Set(CONTEXT_REQUEST_TOKEN_VALIDATED, true)
 	return true, nil
 }
 func (controller *Controller) GetMagicIntParamFromRequest(c *gin.Context, paramName string) (int, error) {
@@ -306,6 +374,12 @@ func (controller *Controller) FeedbackBadRequest(c *gin.Context, errorFlag strin
 	return
 }
 
+// the violation carries the remediation flow for frontend.
+func (controller *Controller) FeedbackSecurityPolicyViolation(c *gin.Context, violation *model.SecurityPolicyViolation) {
+	c.JSON(http.StatusForbidden, violation.ExportForFeedback())
+	return
+}
+
 func (controller *Controller) FeedbackInternalServerError(c *gin.Context, errorFlag string, errorMessage string) {
 	c.JSON(http.StatusInternalServerError, gin.H{
 		"errorCode":    500,
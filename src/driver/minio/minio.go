@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -126,3 +127,32 @@ func (s3Drive *S3Drive) GetPreSignedPutURL(fileName string) (string, error) {
 	}
 	return formatPresignedURLForSelfHostEnv(presignedURL.String()), nil
 }
+
+// the content type is signed into the url, so the uploader must upload the object with the same content type.
+func (s3Drive *S3Drive) GetPreSignedPutURLWithContentType(fileName string, contentType string) (string, error) {
+	ctx := context.Background()
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	presignedURL, err := s3Drive.Instance.PresignHeader(ctx, http.MethodPut, s3Drive.Config.BucketName, fileName, s3Drive.Config.UploadTimeout, nil, headers)
+	if err != nil {
+		return "", err
+	}
+	return formatPresignedURLForSelfHostEnv(presignedURL.String()), nil
+}
+
+func (s3Drive *S3Drive) DoesObjectExist(fileName string) (bool, error) {
+	ctx := context.Background()
+	_, err := s3Drive.Instance.StatObject(ctx, s3Drive.Config.BucketName, fileName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s3Drive *S3Drive) GetObjectURL(fileName string) string {
+	objectURL := s3Drive.Instance.EndpointURL().String() + "/" + s3Drive.Config.BucketName + "/" + fileName
+	return formatPresignedURLForSelfHostEnv(objectURL)
+}
@@ -24,17 +24,33 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	// init user group
 	accessControlRouter := routerGroup.Group("/accessControl")
 	dataControlRouter := routerGroup.Group("/dataControl")
+	capacityControlRouter := routerGroup.Group("/capacityControl")
 
 	// access control routers
+	accessControlRouter.GET("/policy", r.Controller.GetAccessControlPolicy)
 	accessControlRouter.GET("/account/validateResult", r.Controller.ValidateAccount)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canAccess/:attributeID", r.Controller.CanAccess)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canManage/:attributeID", r.Controller.CanManage)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canManageSpecial/:attributeID", r.Controller.CanManageSpecial)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canModify/:attributeID/from/:fromID/to/:toID", r.Controller.CanModify)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canDelete/:attributeID", r.Controller.CanDelete)
+	accessControlRouter.POST("/teams/:teamID/batchCheck", r.Controller.BatchCheck)
+	accessControlRouter.GET("/teams/:teamID/effectivePermissions", r.Controller.GetEffectivePermissions)
+	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/category/:category/attribute/:attributeID/explain", r.Controller.ExplainAccessControl)
+	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/ancestors", r.Controller.GetUnitAncestors)
+	accessControlRouter.PUT("/teams/:teamID/unitType/:unitType/unitID/:unitID/parent", r.Controller.SetUnitParent)
+	accessControlRouter.DELETE("/teams/:teamID/unitType/:unitType/unitID/:unitID/parent", r.Controller.DeleteUnitParent)
+	accessControlRouter.DELETE("/teams/:teamID/unitType/:unitType/unitID/:unitID/relations", r.Controller.DeleteUnitRelations)
 
 	// data control routers
 	dataControlRouter.GET("/users/:targetUserID", r.Controller.GetTargetUserByInternalRequest)
 	dataControlRouter.GET("/users/multi/:targetUserIDs", r.Controller.GetTargetUsersByInternalRequest)
 	dataControlRouter.GET("/teams/byIdentifier/:teamIdentifier", r.Controller.GetTargetTeamByIdentifier)
+	dataControlRouter.GET("/teams/byHost/:host", r.Controller.GetTargetTeamByHost)
+
+	// capacity control routers
+	capacityControlRouter.GET("/teams/:teamID", r.Controller.GetTeamCapacityByInternalRequest)
+	capacityControlRouter.PUT("/teams/:teamID", r.Controller.UpdateTeamCapacityByInternalRequest)
+	capacityControlRouter.POST("/teams/:teamID/reserve", r.Controller.ReserveTeamCapacity)
+	capacityControlRouter.POST("/teams/:teamID/release", r.Controller.ReleaseTeamCapacity)
 }
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+// AccessControlCacheInvalidator listens the supervisor events, and invalidates the access control snapshots changed by them.
+type AccessControlCacheInvalidator struct {
+	cache  *model.Cache
+	logger *zap.SugaredLogger
+}
+
+func NewAccessControlCacheInvalidator(cache *model.Cache, logger *zap.SugaredLogger) *AccessControlCacheInvalidator {
+	return &AccessControlCacheInvalidator{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Run blocks and handles the events, it returns at once when the cache disabled.
+// the events published while redis reconnecting are lost, the snapshots will expire after cache ttl.
+func (i *AccessControlCacheInvalidator) Run() {
+	if !i.cache.AccessControlCache.IsEnabled() {
+		return
+	}
+	pubsub := i.cache.AccessControlCache.SubscribeSupervisorEvents()
+	defer pubsub.Close()
+	for message := range pubsub.Channel() {
+		event, errInParse := model.NewSupervisorEventByPayload(message.Payload)
+		if errInParse != nil {
+			i.logger.Errorw("parse supervisor event failed", "payload", message.Payload, "err", errInParse)
+			continue
+		}
+		if errInInvalidate := i.cache.AccessControlCache.InvalidateByEvent(event); errInInvalidate != nil {
+			i.logger.Errorw("invalidate access control cache failed", "event", event.Event, "teamID", event.TeamID, "err", errInInvalidate)
+		}
+	}
+}
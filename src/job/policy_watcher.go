@@ -0,0 +1,89 @@
+package job
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/accesscontrol"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+	"go.uber.org/zap"
+)
+
+// PolicyWatcher reloads the access control policy file on SIGHUP or file change.
+type PolicyWatcher struct {
+	path     string
+	logger   *zap.SugaredLogger
+	interval time.Duration
+	modTime  time.Time
+}
+
+func NewPolicyWatcher(logger *zap.SugaredLogger) *PolicyWatcher {
+	return &PolicyWatcher{
+		path:     config.GetInstance().GetAccessControlPolicyFile(),
+		logger:   logger,
+		interval: config.GetInstance().GetAccessControlPolicyWatchInterval(),
+	}
+}
+
+// Load the policy file at startup, the invalid policy file should stop the server.
+func (w *PolicyWatcher) Load() error {
+	if w.path == "" {
+		w.logger.Infow("access control policy file not configured, use default policy")
+		return nil
+	}
+	return w.load()
+}
+
+// Run blocks and reloads the policy file, it returns at once when no policy file configured.
+func (w *PolicyWatcher) Run() {
+	if w.path == "" {
+		return
+	}
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hangup:
+			w.Reload()
+		case <-ticker.C:
+			if w.doesFileChanged() {
+				w.Reload()
+			}
+		}
+	}
+}
+
+// the failed reload keeps the policy in use.
+func (w *PolicyWatcher) Reload() {
+	if errInLoad := w.load(); errInLoad != nil {
+		w.logger.Errorw("reload access control policy failed, keep the policy in use", "path", w.path, "err", errInLoad)
+	}
+}
+
+func (w *PolicyWatcher) load() error {
+	fileInfo, errInStat := os.Stat(w.path)
+	if errInStat != nil {
+		return errInStat
+	}
+	// record the modify time first, so the broken file will not be reloaded on every tick
+	w.modTime = fileInfo.ModTime()
+	policy, errInLoad := accesscontrol.LoadPolicyFile(w.path)
+	if errInLoad != nil {
+		return errInLoad
+	}
+	accesscontrol.SetPolicy(policy)
+	w.logger.Infow("access control policy loaded", "path", w.path, "version", policy.Version)
+	return nil
+}
+
+func (w *PolicyWatcher) doesFileChanged() bool {
+	fileInfo, errInStat := os.Stat(w.path)
+	if errInStat != nil {
+		return false
+	}
+	return !fileInfo.ModTime().Equal(w.modTime)
+}
@@ -0,0 +1,60 @@
+package job
+
+import (
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/model"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+	"go.uber.org/zap"
+)
+
+// the purge job is triggered by system, so the operator is empty.
+const TEAM_PURGER_OPERATOR_ID = 0
+
+// TeamPurger purges the archived teams which retention window has expired.
+type TeamPurger struct {
+	storage  *model.Storage
+	cache    *model.Cache
+	logger   *zap.SugaredLogger
+	interval time.Duration
+}
+
+func NewTeamPurger(storage *model.Storage, cache *model.Cache, logger *zap.SugaredLogger) *TeamPurger {
+	return &TeamPurger{
+		storage:  storage,
+		cache:    cache,
+		logger:   logger,
+		interval: config.GetInstance().GetTeamArchivePurgeInterval(),
+	}
+}
+
+// Run blocks and purges the expired teams on every tick.
+func (p *TeamPurger) Run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.Purge()
+	}
+}
+
+func (p *TeamPurger) Purge() {
+	deadline := time.Now().UTC().Add(-config.GetInstance().GetTeamArchiveRetention())
+	teams, errInRetrieveTeams := p.storage.TeamStorage.RetrieveArchivedBefore(deadline)
+	if errInRetrieveTeams != nil {
+		p.logger.Errorw("retrieve archived teams failed", "err", errInRetrieveTeams)
+		return
+	}
+	for _, team := range teams {
+		if errInPurge := p.storage.TeamStorage.PurgeByID(team.ID); errInPurge != nil {
+			p.logger.Errorw("purge archived team failed", "teamID", team.ID, "err", errInPurge)
+			continue
+		}
+		p.logger.Infow("archived team purged", "teamID", team.ID)
+
+		// notify other units
+		event := model.NewSupervisorTeamEvent(model.SUPERVISOR_EVENT_TEAM_PURGED, team, TEAM_PURGER_OPERATOR_ID)
+		if errInPublish := p.cache.EventPublisher.Publish(event); errInPublish != nil {
+			p.logger.Errorw("publish team purged event failed", "teamID", team.ID, "err", errInPublish)
+		}
+	}
+}
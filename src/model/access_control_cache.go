@@ -0,0 +1,267 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/lru"
+)
+
+const (
+	ACCESS_CONTROL_SNAPSHOTS_KEY_TEMPLATE     = "%d_access_control_snapshots"     // hash, the field is user id
+	ACCESS_CONTROL_UNIT_CONTEXTS_KEY_TEMPLATE = "%d_access_control_unit_contexts" // hash, the field is unit type and unit id
+	ACCESS_CONTROL_GENERATION_KEY_TEMPLATE    = "%d_access_control_generation"    // bumped on every invalidation of team
+	ACCESS_CONTROL_UNIT_CONTEXT_FIELD         = "%d_%d"
+	ACCESS_CONTROL_SNAPSHOT_LOCAL_KEY         = "%d_%d"
+	ACCESS_CONTROL_UNIT_CONTEXT_LOCAL_KEY     = "%d_unit_%d_%d"
+	ACCESS_CONTROL_SNAPSHOT_LOCAL_PREFIX      = "%d_"
+	ACCESS_CONTROL_UNIT_CONTEXT_LOCAL_PREFIX  = "%d_unit_"
+)
+
+// the generation outlives the entries cached with it, so an expired generation can not be mistaken for the one read before.
+const ACCESS_CONTROL_GENERATION_EXTRA_TTL = time.Hour
+
+var errAccessControlCacheInvalidated = errors.New("access control cache invalidated.")
+
+// the data loaded from storage to build attribute groups of a user in team.
+type AccessControlSnapshot struct {
+	Team        *Team            `json:"team"`
+	TeamMember  *TeamMember      `json:"teamMember"` // nil for anonymous user
+	UserGroups  []*UserGroup     `json:"userGroups"`
+	Roles       []*Role          `json:"roles"`
+	IPAllowlist *TeamIPAllowlist `json:"ipAllowlist"`
+	CachedAt    time.Time        `json:"cachedAt"`
+
+	// the unexpired grants are cached, the active ones are picked on evaluation.
+	TemporaryGrants     []*TemporaryGrant `json:"temporaryGrants"`
+	TemporaryGrantRoles []*Role           `json:"temporaryGrantRoles"`
+}
+
+func (snapshot *AccessControlSnapshot) ExportCachedAt() time.Time {
+	return snapshot.CachedAt
+}
+
+// the ancestors of unit and the ACL of whole unit chain, they are shared by all users in team.
+type AccessControlUnitContext struct {
+	Ancestors []*UnitReference    `json:"ancestors"`
+	UnitACL   []*UnitRoleRelation `json:"unitACL"`
+	CachedAt  time.Time           `json:"cachedAt"`
+}
+
+func NewAccessControlUnitContext(ancestors []*UnitReference, unitACL []*UnitRoleRelation) *AccessControlUnitContext {
+	return &AccessControlUnitContext{
+		Ancestors: ancestors,
+		UnitACL:   unitACL,
+	}
+}
+
+func (unitContext *AccessControlUnitContext) ExportCachedAt() time.Time {
+	return unitContext.CachedAt
+}
+
+type accessControlCacheEntry interface {
+	ExportCachedAt() time.Time
+}
+
+// the generation of team is read before loading from storage and compared before caching,
+// so the data loaded before an invalidation will not be cached after it.
+type AccessControlGeneration struct {
+	shared int64 // in redis, bumped by every replica
+	local  int64 // in process, bumped with the local entries deleted
+}
+
+// the snapshots are cached in redis and an in-process LRU, both expire after ttl.
+// the snapshots are shared between requests, so the caller should not modify them.
+type AccessControlCache struct {
+	logger           *zap.SugaredLogger
+	cache            *redis.Client
+	context          context.Context
+	local            *lru.Cache
+	ttl              time.Duration
+	lock             sync.Mutex
+	localGenerations map[int]int64
+}
+
+func NewAccessControlCache(cache *redis.Client, logger *zap.SugaredLogger) *AccessControlCache {
+	conf := config.GetInstance()
+	return &AccessControlCache{
+		logger:           logger,
+		cache:            cache,
+		context:          context.Background(),
+		local:            lru.NewCache(conf.GetAccessControlCacheSize(), conf.GetAccessControlCacheTTL()),
+		ttl:              conf.GetAccessControlCacheTTL(),
+		localGenerations: make(map[int]int64),
+	}
+}
+
+func (c *AccessControlCache) IsEnabled() bool {
+	return c.ttl > 0
+}
+
+func (c *AccessControlCache) GetGeneration(teamID int) (*AccessControlGeneration, error) {
+	shared, errInGet := c.cache.Get(c.context, fmt.Sprintf(ACCESS_CONTROL_GENERATION_KEY_TEMPLATE, teamID)).Int64()
+	if errInGet != nil && errInGet != redis.Nil {
+		return nil, errInGet
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return &AccessControlGeneration{
+		shared: shared,
+		local:  c.localGenerations[teamID],
+	}, nil
+}
+
+// nil snapshot means cache missed.
+func (c *AccessControlCache) Get(teamID int, userID int) (*AccessControlSnapshot, error) {
+	entry, errInGet := c.get(fmt.Sprintf(ACCESS_CONTROL_SNAPSHOT_LOCAL_KEY, teamID, userID), fmt.Sprintf(ACCESS_CONTROL_SNAPSHOTS_KEY_TEMPLATE, teamID), strconv.Itoa(userID), &AccessControlSnapshot{})
+	if entry == nil || errInGet != nil {
+		return nil, errInGet
+	}
+	return entry.(*AccessControlSnapshot), nil
+}
+
+// the snapshot is not cached when the team was invalidated after the generation read.
+func (c *AccessControlCache) Set(teamID int, userID int, snapshot *AccessControlSnapshot, generation *AccessControlGeneration) error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	snapshot.CachedAt = time.Now().UTC()
+	return c.set(teamID, generation, fmt.Sprintf(ACCESS_CONTROL_SNAPSHOT_LOCAL_KEY, teamID, userID), fmt.Sprintf(ACCESS_CONTROL_SNAPSHOTS_KEY_TEMPLATE, teamID), strconv.Itoa(userID), snapshot)
+}
+
+// nil unit context means cache missed.
+func (c *AccessControlCache) GetUnitContext(teamID int, unitType int, unitID int) (*AccessControlUnitContext, error) {
+	entry, errInGet := c.get(fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXT_LOCAL_KEY, teamID, unitType, unitID), fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXTS_KEY_TEMPLATE, teamID), fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXT_FIELD, unitType, unitID), &AccessControlUnitContext{})
+	if entry == nil || errInGet != nil {
+		return nil, errInGet
+	}
+	return entry.(*AccessControlUnitContext), nil
+}
+
+// the unit context is not cached when the team was invalidated after the generation read.
+func (c *AccessControlCache) SetUnitContext(teamID int, unitType int, unitID int, unitContext *AccessControlUnitContext, generation *AccessControlGeneration) error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	unitContext.CachedAt = time.Now().UTC()
+	return c.set(teamID, generation, fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXT_LOCAL_KEY, teamID, unitType, unitID), fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXTS_KEY_TEMPLATE, teamID), fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXT_FIELD, unitType, unitID), unitContext)
+}
+
+func (c *AccessControlCache) get(localKey string, key string, field string, entry accessControlCacheEntry) (accessControlCacheEntry, error) {
+	if !c.IsEnabled() {
+		return nil, nil
+	}
+	if cached, hit := c.local.Get(localKey); hit {
+		return cached.(accessControlCacheEntry), nil
+	}
+	payload, errInGet := c.cache.HGet(c.context, key, field).Result()
+	if errInGet == redis.Nil {
+		return nil, nil
+	} else if errInGet != nil {
+		return nil, errInGet
+	}
+	if errInUnmarshal := json.Unmarshal([]byte(payload), entry); errInUnmarshal != nil {
+		return nil, errInUnmarshal
+	}
+	// the hash expires as a whole, so check the entry itself
+	if time.Since(entry.ExportCachedAt()) > c.ttl {
+		return nil, nil
+	}
+	c.local.Set(localKey, entry)
+	return entry, nil
+}
+
+// the generation key is watched, so an invalidation between the comparison and the write aborts the write.
+func (c *AccessControlCache) set(teamID int, generation *AccessControlGeneration, localKey string, key string, field string, entry accessControlCacheEntry) error {
+	payload, errInMarshal := json.Marshal(entry)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	generationKey := fmt.Sprintf(ACCESS_CONTROL_GENERATION_KEY_TEMPLATE, teamID)
+	errInWatch := c.cache.Watch(c.context, func(tx *redis.Tx) error {
+		shared, errInGet := tx.Get(c.context, generationKey).Int64()
+		if errInGet != nil && errInGet != redis.Nil {
+			return errInGet
+		}
+		if shared != generation.shared {
+			return errAccessControlCacheInvalidated
+		}
+		_, errInExec := tx.TxPipelined(c.context, func(pipe redis.Pipeliner) error {
+			pipe.HSet(c.context, key, field, payload)
+			pipe.Expire(c.context, key, c.ttl)
+			return nil
+		})
+		return errInExec
+	}, generationKey)
+	if errors.Is(errInWatch, errAccessControlCacheInvalidated) || errors.Is(errInWatch, redis.TxFailedErr) {
+		return nil
+	}
+	if errInWatch != nil {
+		return errInWatch
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.localGenerations[teamID] == generation.local {
+		c.local.Set(localKey, entry)
+	}
+	return nil
+}
+
+// bump the generations first, so the entries loaded before this invalidation will not be cached.
+func (c *AccessControlCache) DeleteByTeamID(teamID int) error {
+	c.lock.Lock()
+	c.localGenerations[teamID]++
+	c.local.DeleteByPrefix(fmt.Sprintf(ACCESS_CONTROL_SNAPSHOT_LOCAL_PREFIX, teamID))
+	c.lock.Unlock()
+	pipe := c.cache.TxPipeline()
+	c.bumpGeneration(pipe, teamID)
+	pipe.Del(c.context, fmt.Sprintf(ACCESS_CONTROL_SNAPSHOTS_KEY_TEMPLATE, teamID), fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXTS_KEY_TEMPLATE, teamID))
+	_, errInExec := pipe.Exec(c.context)
+	return errInExec
+}
+
+// the unit contexts are invalidated too, since the unit ACL entries of user may be changed with user.
+func (c *AccessControlCache) DeleteByTeamIDAndUserID(teamID int, userID int) error {
+	c.lock.Lock()
+	c.localGenerations[teamID]++
+	c.local.Delete(fmt.Sprintf(ACCESS_CONTROL_SNAPSHOT_LOCAL_KEY, teamID, userID))
+	c.local.DeleteByPrefix(fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXT_LOCAL_PREFIX, teamID))
+	c.lock.Unlock()
+	pipe := c.cache.TxPipeline()
+	c.bumpGeneration(pipe, teamID)
+	pipe.HDel(c.context, fmt.Sprintf(ACCESS_CONTROL_SNAPSHOTS_KEY_TEMPLATE, teamID), strconv.Itoa(userID))
+	pipe.Del(c.context, fmt.Sprintf(ACCESS_CONTROL_UNIT_CONTEXTS_KEY_TEMPLATE, teamID))
+	_, errInExec := pipe.Exec(c.context)
+	return errInExec
+}
+
+// the generation key expires after the entries, so the keys of archived or purged teams are removed too.
+func (c *AccessControlCache) bumpGeneration(pipe redis.Pipeliner, teamID int) {
+	generationKey := fmt.Sprintf(ACCESS_CONTROL_GENERATION_KEY_TEMPLATE, teamID)
+	pipe.Incr(c.context, generationKey)
+	pipe.Expire(c.context, generationKey, c.ttl+ACCESS_CONTROL_GENERATION_EXTRA_TTL)
+}
+
+// invalidate the snapshots changed by the supervisor event, every supervisor replica receives the event and does this.
+func (c *AccessControlCache) InvalidateByEvent(event *SupervisorEvent) error {
+	if event.TeamID == "" {
+		return nil
+	}
+	if event.IsTeamLevel() {
+		return c.DeleteByTeamID(event.ExportTeamIDInInt())
+	}
+	return c.DeleteByTeamIDAndUserID(event.ExportTeamIDInInt(), event.ExportUserIDInInt())
+}
+
+func (c *AccessControlCache) SubscribeSupervisorEvents() *redis.PubSub {
+	return c.cache.Subscribe(c.context, SUPERVISOR_EVENT_CHANNEL)
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"time"
@@ -10,13 +12,52 @@ import (
 	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
 )
 
+const ACCESS_TOKEN_LIFETIME = time.Hour * 24 * 7
+
+const (
+	AUTH_METHOD_PASSWORD = "password"
+	AUTH_METHOD_SSO      = "sso"
+)
+
+// the auth method and two-factor claims are signed by the service which authenticates the user,
+// the password sign-in of supervisor issues password session without two-factor.
 type AuthClaims struct {
-	User   int       `json:"user"`
-	UID    uuid.UUID `json:"uuid"`
-	Random string    `json:"rnd"`
+	User       int       `json:"user"`
+	UID        uuid.UUID `json:"uuid"`
+	Random     string    `json:"rnd"`
+	AuthMethod string    `json:"amr"` // how the session signed in
+	TwoFactor  bool      `json:"tfa"` // the session passed two-factor authentication
 	jwt.RegisteredClaims
 }
 
+// the session facts carried by access token, used for team security policy check.
+type AccessSession struct {
+	AuthMethod   string
+	TwoFactor    bool
+	IssuedAt     time.Time
+	LastActiveAt time.Time // zero value means no activity recorded yet
+}
+
+// the token issued before the session facts were added is treated as a password session,
+// and as issued a full lifetime before it expires.
+func NewAccessSession(authMethod string, twoFactor bool, issuedAt time.Time, expiresAt time.Time) *AccessSession {
+	if authMethod == "" {
+		authMethod = AUTH_METHOD_PASSWORD
+	}
+	if issuedAt.IsZero() {
+		issuedAt = expiresAt.Add(-ACCESS_TOKEN_LIFETIME)
+	}
+	return &AccessSession{
+		AuthMethod: authMethod,
+		TwoFactor:  twoFactor,
+		IssuedAt:   issuedAt,
+	}
+}
+
+func (s *AccessSession) SetLastActiveAt(lastActiveAt time.Time) {
+	s.LastActiveAt = lastActiveAt
+}
+
 func ValidateAccessToken(accessToken string) (bool, error) {
 	_, _, err := ExtractUserIDFromToken(accessToken)
 	if err != nil {
@@ -43,18 +84,34 @@ func ExtractUserIDFromToken(accessToken string) (int, uuid.UUID, error) {
 	return claims.User, claims.UID, nil
 }
 
+// the token hash identifies one token, the tokens of same user issued in the same second are still different.
+func ExportAccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:])
+}
+
 func CreateAccessToken(id int, uid uuid.UUID) (string, error) {
+	return CreateAccessTokenWithAuthMethod(id, uid, AUTH_METHOD_PASSWORD, false)
+}
+
+func CreateAccessTokenWithAuthMethod(id int, uid uuid.UUID, authMethod string, twoFactor bool) (string, error) {
 	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
 	vCode := fmt.Sprintf("%06v", rnd.Int31n(10000))
 
+	now := time.Now()
 	claims := &AuthClaims{
-		User:   id,
-		UID:    uid,
-		Random: vCode,
+		User:       id,
+		UID:        uid,
+		Random:     vCode,
+		AuthMethod: authMethod,
+		TwoFactor:  twoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer: "ILLA",
+			IssuedAt: &jwt.NumericDate{
+				Time: now,
+			},
 			ExpiresAt: &jwt.NumericDate{
-				Time: time.Now().Add(time.Hour * 24 * 7),
+				Time: now.Add(ACCESS_TOKEN_LIFETIME),
 			},
 		},
 	}
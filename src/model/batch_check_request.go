@@ -0,0 +1,43 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// the fromID and toID are only used by the modify check of manage category, leave them 0 for others.
+type BatchCheckItemRequest struct {
+	Category  int    `json:"category" validate:"min=1,max=4"`
+	UnitType  int    `json:"unitType" validate:"required"`
+	UnitID    string `json:"unitID"` // empty means team-wide
+	Attribute int    `json:"attribute" validate:"required"`
+	FromID    int    `json:"fromID" validate:"gte=0"`
+	ToID      int    `json:"toID" validate:"gte=0"`
+}
+
+func (req *BatchCheckItemRequest) ExportUnitIDInInt() int {
+	if req.UnitID == "" {
+		return 0
+	}
+	return idconvertor.ConvertStringToInt(req.UnitID)
+}
+
+func (req *BatchCheckItemRequest) IsModifyCheck() bool {
+	return req.FromID != 0 || req.ToID != 0
+}
+
+type BatchCheckRequest struct {
+	Checks []*BatchCheckItemRequest `json:"checks" validate:"required,min=1,max=200,dive,required"`
+}
+
+func NewBatchCheckRequest() *BatchCheckRequest {
+	return &BatchCheckRequest{}
+}
+
+// the body hash is signed with request token, so a replayed request token can not be used with other checks.
+func ExportBatchCheckRequestBodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
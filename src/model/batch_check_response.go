@@ -0,0 +1,40 @@
+package model
+
+type BatchCheckItemResponse struct {
+	Index     int    `json:"index"`
+	Category  int    `json:"category"`
+	UnitType  int    `json:"unitType"`
+	UnitID    string `json:"unitID"`
+	Attribute int    `json:"attribute"`
+	FromID    int    `json:"fromID,omitempty"`
+	ToID      int    `json:"toID,omitempty"`
+	Allowed   bool   `json:"allowed"`
+}
+
+// the results are in the same order of request checks.
+type BatchCheckResponse struct {
+	Results []*BatchCheckItemResponse `json:"results"`
+}
+
+func NewBatchCheckResponse(size int) *BatchCheckResponse {
+	return &BatchCheckResponse{
+		Results: make([]*BatchCheckItemResponse, 0, size),
+	}
+}
+
+func (resp *BatchCheckResponse) Append(index int, req *BatchCheckItemRequest, allowed bool) {
+	resp.Results = append(resp.Results, &BatchCheckItemResponse{
+		Index:     index,
+		Category:  req.Category,
+		UnitType:  req.UnitType,
+		UnitID:    req.UnitID,
+		Attribute: req.Attribute,
+		FromID:    req.FromID,
+		ToID:      req.ToID,
+		Allowed:   allowed,
+	})
+}
+
+func (resp *BatchCheckResponse) ExportForFeedback() interface{} {
+	return resp
+}
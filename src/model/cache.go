@@ -6,12 +6,18 @@ import (
 )
 
 type Cache struct {
-	JWTCache *JWTCache
+	JWTCache           *JWTCache
+	EventPublisher     *EventPublisher
+	AccessControlCache *AccessControlCache
 }
 
 func NewCache(redisDriver *redis.Client, logger *zap.SugaredLogger) *Cache {
 	jwtCache := NewJWTCache(redisDriver, logger)
+	eventPublisher := NewEventPublisher(redisDriver, logger)
+	accessControlCache := NewAccessControlCache(redisDriver, logger)
 	return &Cache{
-		JWTCache: jwtCache,
+		JWTCache:           jwtCache,
+		EventPublisher:     eventPublisher,
+		AccessControlCache: accessControlCache,
 	}
 }
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// 0 means unlimited for all capacity limits.
+const CAPACITY_UNLIMITED = 0
+
+var ErrCapacityExceeded = errors.New("team capacity exceeded.")
+
+type Capacity struct {
+	ID           int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID       int       `json:"teamID" gorm:"column:team_id;type:bigserial;uniqueIndex:capacities_team_id"`
+	MaxMembers   int       `json:"maxMembers" gorm:"column:max_members;type:integer"`
+	MaxEditors   int       `json:"maxEditors" gorm:"column:max_editors;type:integer"`    // the editor seats are taken by owner, admin, editor and the members granted editor seat
+	StorageQuota int64     `json:"storageQuota" gorm:"column:storage_quota;type:bigint"` // in bytes
+	StorageUsed  int64     `json:"storageUsed" gorm:"column:storage_used;type:bigint"`   // in bytes, reserved by other units
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type CapacityForExport struct {
+	TeamID       string `json:"teamID"`
+	MaxMembers   int    `json:"maxMembers"`
+	MaxEditors   int    `json:"maxEditors"`
+	StorageQuota int64  `json:"storageQuota"`
+	StorageUsed  int64  `json:"storageUsed"`
+	Members      int    `json:"members"`
+	Editors      int    `json:"editors"`
+}
+
+func NewCapacity(teamID int) *Capacity {
+	capacity := &Capacity{
+		TeamID:       teamID,
+		MaxMembers:   CAPACITY_UNLIMITED,
+		MaxEditors:   CAPACITY_UNLIMITED,
+		StorageQuota: CAPACITY_UNLIMITED,
+		StorageUsed:  0,
+	}
+	capacity.InitCreatedAt()
+	capacity.InitUpdatedAt()
+	return capacity
+}
+
+func (c *Capacity) InitCreatedAt() {
+	c.CreatedAt = time.Now().UTC()
+}
+
+func (c *Capacity) InitUpdatedAt() {
+	c.UpdatedAt = time.Now().UTC()
+}
+
+func (c *Capacity) UpdateByRequest(req *UpdateCapacityRequest) {
+	c.MaxMembers = req.MaxMembers
+	c.MaxEditors = req.MaxEditors
+	c.StorageQuota = req.StorageQuota
+	c.InitUpdatedAt()
+}
+
+func (c *Capacity) DoesMemberSeatsAvaliable(members int, increment int) bool {
+	if c.MaxMembers == CAPACITY_UNLIMITED || increment <= 0 {
+		return true
+	}
+	return members+increment <= c.MaxMembers
+}
+
+func (c *Capacity) DoesEditorSeatsAvaliable(editors int, increment int) bool {
+	if c.MaxEditors == CAPACITY_UNLIMITED || increment <= 0 {
+		return true
+	}
+	return editors+increment <= c.MaxEditors
+}
+
+func (c *Capacity) Export(members int, editors int) *CapacityForExport {
+	return &CapacityForExport{
+		TeamID:       idconvertor.ConvertIntToString(c.TeamID),
+		MaxMembers:   c.MaxMembers,
+		MaxEditors:   c.MaxEditors,
+		StorageQuota: c.StorageQuota,
+		StorageUsed:  c.StorageUsed,
+		Members:      members,
+		Editors:      editors,
+	}
+}
+
+// the user roles which take the editor seats.
+var EditorSeatUserRoles = []int{USER_ROLE_OWNER, USER_ROLE_ADMIN, USER_ROLE_EDITOR}
+
+func DoesUserRoleTakeEditorSeat(userRole int) bool {
+	for _, editorSeatUserRole := range EditorSeatUserRoles {
+		if userRole == editorSeatUserRole {
+			return true
+		}
+	}
+	return false
+}
+
+func CalculateEditorSeatIncrementForNewMember(userRole int) int {
+	if DoesUserRoleTakeEditorSeat(userRole) {
+		return 1
+	}
+	return 0
+}
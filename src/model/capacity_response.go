@@ -0,0 +1,15 @@
+package model
+
+type CapacityResponse struct {
+	*CapacityForExport
+}
+
+func NewCapacityResponse(capacity *Capacity, members int, editors int) *CapacityResponse {
+	return &CapacityResponse{
+		CapacityForExport: capacity.Export(members, editors),
+	}
+}
+
+func (resp *CapacityResponse) ExportForFeedback() interface{} {
+	return resp
+}
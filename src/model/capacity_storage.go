@@ -0,0 +1,122 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CapacityStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewCapacityStorage(db *gorm.DB, logger *zap.SugaredLogger) *CapacityStorage {
+	return &CapacityStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// the team without capacity record is unlimited.
+func (d *CapacityStorage) RetrieveByTeamID(teamID int) (*Capacity, error) {
+	return retrieveCapacity(d.db, teamID)
+}
+
+// count the member seats and editor seats taken in team.
+func (d *CapacityStorage) CountSeatsByTeamID(teamID int) (int, int, error) {
+	return countSeats(d.db, teamID)
+}
+
+func (d *CapacityStorage) UpsertLimits(capacity *Capacity) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_members", "max_editors", "storage_quota", "updated_at"}),
+	}).Omit("id").Create(capacity).Error
+}
+
+// reserve storage in one statement, so concurrent reservations can not exceed the quota.
+func (d *CapacityStorage) ReserveStorage(teamID int, amount int64) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("id").Create(NewCapacity(teamID)).Error; err != nil {
+			return err
+		}
+		reserve := tx.Model(&Capacity{}).Where("team_id = ? AND (storage_quota = ? OR storage_used + ? <= storage_quota)", teamID, CAPACITY_UNLIMITED, amount).UpdateColumns(map[string]interface{}{"storage_used": gorm.Expr("storage_used + ?", amount), "updated_at": time.Now().UTC()})
+		if reserve.Error != nil {
+			return reserve.Error
+		}
+		if reserve.RowsAffected != 1 {
+			return ErrCapacityExceeded
+		}
+		return nil
+	})
+}
+
+// the used storage will not go below zero.
+func (d *CapacityStorage) ReleaseStorage(teamID int, amount int64) error {
+	if err := d.db.Model(&Capacity{}).Where("team_id = ?", teamID).UpdateColumns(map[string]interface{}{"storage_used": gorm.Expr("GREATEST(storage_used - ?, 0)", amount), "updated_at": time.Now().UTC()}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func retrieveCapacity(tx *gorm.DB, teamID int) (*Capacity, error) {
+	capacity := &Capacity{}
+	err := tx.Where("team_id = ?", teamID).First(capacity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NewCapacity(teamID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return capacity, nil
+}
+
+// the pending team members take member seats too.
+// the editor seats are taken by the team members with editor seat role, or granted editor seat by user groups and custom roles.
+func countSeats(tx *gorm.DB, teamID int) (int, int, error) {
+	var members int64
+	if err := tx.Model(&TeamMember{}).Where("team_id = ?", teamID).Count(&members).Error; err != nil {
+		return 0, 0, err
+	}
+	var editors int64
+	editorSeatCondition := "team_members.user_role IN ?" +
+		" OR EXISTS (SELECT 1 FROM user_group_members JOIN user_groups ON user_groups.id = user_group_members.user_group_id WHERE user_group_members.team_member_id = team_members.id AND user_groups.editor_seat)" +
+		" OR EXISTS (SELECT 1 FROM user_role_relations JOIN roles ON roles.id = user_role_relations.role_id WHERE user_role_relations.team_id = team_members.team_id AND user_role_relations.user_id = team_members.user_id AND roles.editor_seat)"
+	if err := tx.Model(&TeamMember{}).Where("team_id = ?", teamID).Where(editorSeatCondition, EditorSeatUserRoles).Count(&editors).Error; err != nil {
+		return 0, 0, err
+	}
+	return int(members), int(editors), nil
+}
+
+// run the write in one transaction with the team row locked, so the concurrent seat takers are serialized.
+// the write is rolled back with ErrCapacityExceeded when it takes more seats than the capacity allows, the seats already taken will not be released.
+func takeSeats(db *gorm.DB, teamID int, write func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&Team{}, teamID).Error; err != nil {
+			return err
+		}
+		capacity, err := retrieveCapacity(tx, teamID)
+		if err != nil {
+			return err
+		}
+		membersBefore, editorsBefore, err := countSeats(tx, teamID)
+		if err != nil {
+			return err
+		}
+		if err := write(tx); err != nil {
+			return err
+		}
+		membersAfter, editorsAfter, err := countSeats(tx, teamID)
+		if err != nil {
+			return err
+		}
+		if !capacity.DoesMemberSeatsAvaliable(membersBefore, membersAfter-membersBefore) || !capacity.DoesEditorSeatsAvaliable(editorsBefore, editorsAfter-editorsBefore) {
+			return ErrCapacityExceeded
+		}
+		return nil
+	})
+}
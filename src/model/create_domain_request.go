@@ -0,0 +1,21 @@
+package model
+
+import "strings"
+
+type CreateDomainRequest struct {
+	Category           int    `json:"category" validate:"required,oneof=1 2"`
+	UserDomain         string `json:"userDomain" validate:"required_without=SystemDomainPrefix,omitempty,fqdn,max=255"`
+	SystemDomainPrefix string `json:"systemDomainPrefix" validate:"required_without=UserDomain,omitempty,max=63,hostname_rfc1123,excludesall=."`
+}
+
+func NewCreateDomainRequest() *CreateDomainRequest {
+	return &CreateDomainRequest{}
+}
+
+func (req *CreateDomainRequest) ExportUserDomain() string {
+	return strings.TrimSuffix(strings.ToLower(req.UserDomain), ".")
+}
+
+func (req *CreateDomainRequest) ExportSystemDomainPrefix() string {
+	return strings.ToLower(req.SystemDomainPrefix)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DNS_RECORD_TYPE_CNAME = "CNAME"
+	DNS_RECORD_TYPE_TXT   = "TXT"
+)
+
+// the domain ownership is proven by a TXT record like "_illa-verification.example.com TXT illa-verification=<token>".
+const DNS_VERIFICATION_RECORD_PREFIX = "_illa-verification."
+const DNS_VERIFICATION_VALUE_PREFIX = "illa-verification="
+
+// the DNS record which user should add to prove the domain ownership.
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// check if the expected value included in the TXT records.
+func DoesTXTRecordsInclude(records []string, expected string) bool {
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func NewDNSVerificationToken() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
+}
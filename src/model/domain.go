@@ -0,0 +1,254 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const (
+	DOMAIN_CATEGORY_TEAM = 1 // the domain for team dashboard
+	DOMAIN_CATEGORY_APP  = 2 // the domain for released apps
+)
+
+const (
+	DOMAIN_RESOLVE_STATUS_PENDING  = 1
+	DOMAIN_RESOLVE_STATUS_RESOLVED = 2
+	DOMAIN_RESOLVE_STATUS_FAILED   = 3
+)
+
+// the pending claim which not verified in time expires, it reserves nothing and can not be verified any more.
+const DOMAIN_PENDING_CLAIM_LIFETIME = 72 * time.Hour
+
+// the system domain prefixes kept for the platform itself.
+var ReservedSystemDomainPrefixes = map[string]bool{
+	"www":     true,
+	"api":     true,
+	"admin":   true,
+	"app":     true,
+	"apps":    true,
+	"auth":    true,
+	"builder": true,
+	"cloud":   true,
+	"console": true,
+	"docs":    true,
+	"mail":    true,
+	"status":  true,
+	"support": true,
+}
+
+// the team domain, the system domain prefix is resolved once registered since we own the system domain,
+// and the user domain should be proven by the verification TXT record. the CNAME record only routes the traffic,
+// a CNAME to the system domain proves nothing since any team can point to it.
+// only the resolved domain reserves the user domain and system domain prefix.
+type Domain struct {
+	ID                 int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:domains_ukey"`
+	UID                uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:domains_ukey"`
+	TeamID             int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:domains_team_id"`
+	UserDomain         string    `json:"userDomain" gorm:"column:user_domain;type:varchar;size:255;not null"`
+	SystemDomainPrefix string    `json:"systemDomainPrefix" gorm:"column:system_domain_prefix;type:varchar;size:255;not null"`
+	ResolveStatus      int       `json:"resolveStatus" gorm:"column:resolve_status;type:smallint"`
+	Category           int       `json:"category" gorm:"column:category;type:smallint"`
+	VerificationToken  string    `json:"verificationToken" gorm:"column:verification_token;type:varchar;size:64"`
+	CNAMEMatched       bool      `json:"cnameMatched" gorm:"column:cname_matched;type:boolean"`
+	ResolvedAt         time.Time `gorm:"column:resolved_at;type:timestamp"`
+	CheckedAt          time.Time `gorm:"column:checked_at;type:timestamp"`
+	CreatedBy          int       `json:"createdBy" gorm:"column:created_by;type:bigserial"`
+	CreatedAt          time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type DomainForExport struct {
+	ID                  string       `json:"domainID"`
+	TeamID              string       `json:"teamID"`
+	Category            int          `json:"category"`
+	UserDomain          string       `json:"userDomain"`
+	SystemDomainPrefix  string       `json:"systemDomainPrefix"`
+	SystemDomain        string       `json:"systemDomain"`
+	ResolveStatus       int          `json:"resolveStatus"`
+	CNAMEMatched        bool         `json:"cnameMatched"` // the traffic of user domain can be routed to team
+	VerificationRecords []*DNSRecord `json:"verificationRecords"`
+	ResolvedAt          *time.Time   `json:"resolvedAt"`
+	CheckedAt           *time.Time   `json:"checkedAt"`
+	CreatedAt           time.Time    `json:"createdAt"`
+	UpdatedAt           time.Time    `json:"updatedAt"`
+}
+
+func NewDomainByCreateRequest(teamID int, userID int, req *CreateDomainRequest) *Domain {
+	domain := &Domain{
+		TeamID:             teamID,
+		UserDomain:         req.ExportUserDomain(),
+		SystemDomainPrefix: req.ExportSystemDomainPrefix(),
+		ResolveStatus:      DOMAIN_RESOLVE_STATUS_PENDING,
+		Category:           req.Category,
+		VerificationToken:  NewDNSVerificationToken(),
+		CreatedBy:          userID,
+	}
+	domain.UID = uuid.New()
+	domain.InitCreatedAt()
+	domain.InitUpdatedAt()
+	if !domain.HasUserDomain() {
+		domain.Resolve()
+	}
+	return domain
+}
+
+func (d *Domain) InitCreatedAt() {
+	d.CreatedAt = time.Now().UTC()
+}
+
+func (d *Domain) InitUpdatedAt() {
+	d.UpdatedAt = time.Now().UTC()
+}
+
+func (d *Domain) ExportID() int {
+	return d.ID
+}
+
+func (d *Domain) ExportCategory() int {
+	return d.Category
+}
+
+func (d *Domain) HasUserDomain() bool {
+	return d.UserDomain != ""
+}
+
+func (d *Domain) HasSystemDomainPrefix() bool {
+	return d.SystemDomainPrefix != ""
+}
+
+func (d *Domain) IsResolved() bool {
+	if d.ResolveStatus == DOMAIN_RESOLVE_STATUS_RESOLVED {
+		return true
+	}
+	return false
+}
+
+func (d *Domain) Resolve() {
+	now := time.Now().UTC()
+	d.ResolveStatus = DOMAIN_RESOLVE_STATUS_RESOLVED
+	d.ResolvedAt = now
+	d.CheckedAt = now
+	d.UpdatedAt = now
+}
+
+// the resolved domain keeps its status when the recheck failed, so the temporary DNS failure will not break the team.
+func (d *Domain) FailToResolve() {
+	now := time.Now().UTC()
+	if !d.IsResolved() {
+		d.ResolveStatus = DOMAIN_RESOLVE_STATUS_FAILED
+	}
+	d.CheckedAt = now
+	d.UpdatedAt = now
+}
+
+func (d *Domain) IsClaimExpired(now time.Time) bool {
+	return !d.IsResolved() && now.After(d.CreatedAt.Add(DOMAIN_PENDING_CLAIM_LIFETIME))
+}
+
+func (d *Domain) SetCNAMEMatched(matched bool) {
+	d.CNAMEMatched = matched
+}
+
+// the full system domain like "team-a.illa.ai", return empty string if the prefix not set.
+func (d *Domain) ExportSystemDomain() string {
+	if !d.HasSystemDomainPrefix() {
+		return ""
+	}
+	return d.SystemDomainPrefix + "." + config.GetInstance().GetSystemDomain()
+}
+
+// the user domain should CNAME to the team system domain, or the system domain if the prefix not set.
+func (d *Domain) ExportCNAMETarget() string {
+	if d.HasSystemDomainPrefix() {
+		return d.ExportSystemDomain()
+	}
+	return config.GetInstance().GetSystemDomain()
+}
+
+func (d *Domain) ExportVerificationRecords() []*DNSRecord {
+	if !d.HasUserDomain() {
+		return []*DNSRecord{}
+	}
+	return []*DNSRecord{
+		{
+			Type:  DNS_RECORD_TYPE_CNAME,
+			Name:  d.UserDomain,
+			Value: d.ExportCNAMETarget(),
+		},
+		{
+			Type:  DNS_RECORD_TYPE_TXT,
+			Name:  DNS_VERIFICATION_RECORD_PREFIX + d.UserDomain,
+			Value: DNS_VERIFICATION_VALUE_PREFIX + d.VerificationToken,
+		},
+	}
+}
+
+func (d *Domain) DoesCNAMEMatch(cname string) bool {
+	return strings.EqualFold(strings.TrimSuffix(cname, "."), d.ExportCNAMETarget())
+}
+
+func (d *Domain) DoesTXTRecordsMatch(records []string) bool {
+	return DoesTXTRecordsInclude(records, DNS_VERIFICATION_VALUE_PREFIX+d.VerificationToken)
+}
+
+func (d *Domain) Export() *DomainForExport {
+	ret := &DomainForExport{
+		ID:                  idconvertor.ConvertIntToString(d.ID),
+		TeamID:              idconvertor.ConvertIntToString(d.TeamID),
+		Category:            d.Category,
+		UserDomain:          d.UserDomain,
+		SystemDomainPrefix:  d.SystemDomainPrefix,
+		SystemDomain:        d.ExportSystemDomain(),
+		ResolveStatus:       d.ResolveStatus,
+		CNAMEMatched:        d.CNAMEMatched,
+		VerificationRecords: d.ExportVerificationRecords(),
+		CreatedAt:           d.CreatedAt,
+		UpdatedAt:           d.UpdatedAt,
+	}
+	if !d.ResolvedAt.IsZero() {
+		ret.ResolvedAt = &d.ResolvedAt
+	}
+	if !d.CheckedAt.IsZero() {
+		ret.CheckedAt = &d.CheckedAt
+	}
+	return ret
+}
+
+// the user domain can not be the system domain or its sub domain.
+func IsSystemDomainOrSubDomain(domain string) bool {
+	systemDomain := config.GetInstance().GetSystemDomain()
+	if domain == systemDomain || strings.HasSuffix(domain, "."+systemDomain) {
+		return true
+	}
+	return false
+}
+
+func IsReservedSystemDomainPrefix(prefix string) bool {
+	return ReservedSystemDomainPrefixes[prefix]
+}
+
+// split the host into system domain prefix, return empty string if the host is not a system sub domain.
+func ExtractSystemDomainPrefixFromHost(host string) string {
+	suffix := "." + config.GetInstance().GetSystemDomain()
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	prefix := strings.TrimSuffix(host, suffix)
+	if strings.Contains(prefix, ".") {
+		return ""
+	}
+	return prefix
+}
+
+// normalize the Host header, the port and trailing dot will be removed.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if at := strings.LastIndex(host, ":"); at >= 0 && !strings.Contains(host[at:], "]") {
+		host = host[:at]
+	}
+	return strings.TrimSuffix(host, ".")
+}
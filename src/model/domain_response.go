@@ -0,0 +1,33 @@
+package model
+
+type DomainResponse struct {
+	*DomainForExport
+}
+
+func NewDomainResponse(domain *Domain) *DomainResponse {
+	return &DomainResponse{
+		DomainForExport: domain.Export(),
+	}
+}
+
+func (resp *DomainResponse) ExportForFeedback() interface{} {
+	return resp
+}
+
+type GetAllDomainsResponse struct {
+	Domains []*DomainForExport
+}
+
+func NewGetAllDomainsResponse(domains []*Domain) *GetAllDomainsResponse {
+	resp := &GetAllDomainsResponse{
+		Domains: make([]*DomainForExport, 0, len(domains)),
+	}
+	for _, domain := range domains {
+		resp.Domains = append(resp.Domains, domain.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllDomainsResponse) ExportForFeedback() interface{} {
+	return resp.Domains
+}
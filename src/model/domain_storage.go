@@ -0,0 +1,88 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type DomainStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewDomainStorage(db *gorm.DB, logger *zap.SugaredLogger) *DomainStorage {
+	return &DomainStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *DomainStorage) Create(u *Domain) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *DomainStorage) RetrieveByTeamID(teamID int) ([]*Domain, error) {
+	var domains []*Domain
+	if err := d.db.Where("team_id = ?", teamID).Order("id asc").Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+func (d *DomainStorage) RetrieveByTeamIDAndID(teamID int, id int) (*Domain, error) {
+	u := &Domain{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *DomainStorage) RetrieveResolvedByUserDomain(userDomain string) (*Domain, error) {
+	u := &Domain{}
+	if err := d.db.Where("user_domain = ? AND resolve_status = ?", userDomain, DOMAIN_RESOLVE_STATUS_RESOLVED).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *DomainStorage) RetrieveResolvedBySystemDomainPrefix(systemDomainPrefix string) (*Domain, error) {
+	u := &Domain{}
+	if err := d.db.Where("system_domain_prefix = ? AND resolve_status = ?", systemDomainPrefix, DOMAIN_RESOLVE_STATUS_RESOLVED).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// the user domain and system domain prefix are unique in all resolved domains, the pending claims reserve nothing.
+func (d *DomainStorage) DoesResolvedUserDomainExist(userDomain string, excludedID int) (bool, error) {
+	var count int64
+	if err := d.db.Model(&Domain{}).Where("user_domain = ? AND resolve_status = ? AND id <> ?", userDomain, DOMAIN_RESOLVE_STATUS_RESOLVED, excludedID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d *DomainStorage) DoesResolvedSystemDomainPrefixExist(systemDomainPrefix string, excludedID int) (bool, error) {
+	var count int64
+	if err := d.db.Model(&Domain{}).Where("system_domain_prefix = ? AND resolve_status = ? AND id <> ?", systemDomainPrefix, DOMAIN_RESOLVE_STATUS_RESOLVED, excludedID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d *DomainStorage) UpdateResolveStatus(u *Domain) error {
+	if err := d.db.Model(&Domain{}).Where("id = ?", u.ID).Select("resolve_status", "cname_matched", "resolved_at", "checked_at", "updated_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *DomainStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&Domain{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 
 type S3Instance interface {
 	GetPreSignedPutURL(fileName string) (string, error)
+	GetPreSignedPutURLWithContentType(fileName string, contentType string) (string, error)
+	DoesObjectExist(fileName string) (bool, error)
+	GetObjectURL(fileName string) string
 }
 
 type Drive struct {
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const (
+	EMAIL_DOMAIN_STATUS_UNVERIFIED = 1
+	EMAIL_DOMAIN_STATUS_VERIFIED   = 2
+)
+
+type EmailDomain struct {
+	ID                int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:email_domains_ukey"`
+	UID               uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:email_domains_ukey"`
+	TeamID            int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:email_domains_team_id_and_domain"`
+	Domain            string    `json:"domain" gorm:"column:domain;type:varchar;size:255;index:email_domains_team_id_and_domain"`
+	UserRole          int       `json:"userRole" gorm:"column:user_role;type:smallint"`
+	RequireApproval   bool      `json:"requireApproval" gorm:"column:require_approval;type:boolean"`
+	VerificationToken string    `json:"verificationToken" gorm:"column:verification_token;type:varchar;size:64"`
+	Status            int       `json:"status" gorm:"column:status;type:smallint"`
+	VerifiedAt        time.Time `gorm:"column:verified_at;type:timestamp"`
+	CreatedBy         int       `json:"createdBy" gorm:"column:created_by;type:bigserial"`
+	CreatedAt         time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type EmailDomainForExport struct {
+	ID                 string     `json:"emailDomainID"`
+	TeamID             string     `json:"teamID"`
+	Domain             string     `json:"domain"`
+	UserRole           int        `json:"userRole"`
+	RequireApproval    bool       `json:"requireApproval"`
+	Verified           bool       `json:"verified"`
+	VerifiedAt         *time.Time `json:"verifiedAt"`
+	VerificationRecord *DNSRecord `json:"verificationRecord"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+func NewEmailDomainByCreateRequest(teamID int, userID int, req *CreateEmailDomainRequest) *EmailDomain {
+	emailDomain := &EmailDomain{
+		TeamID:            teamID,
+		Domain:            req.ExportDomain(),
+		UserRole:          req.UserRole,
+		RequireApproval:   req.RequireApproval,
+		VerificationToken: NewDNSVerificationToken(),
+		Status:            EMAIL_DOMAIN_STATUS_UNVERIFIED,
+		CreatedBy:         userID,
+	}
+	emailDomain.UID = uuid.New()
+	emailDomain.InitCreatedAt()
+	emailDomain.InitUpdatedAt()
+	return emailDomain
+}
+
+func (d *EmailDomain) InitCreatedAt() {
+	d.CreatedAt = time.Now().UTC()
+}
+
+func (d *EmailDomain) InitUpdatedAt() {
+	d.UpdatedAt = time.Now().UTC()
+}
+
+func (d *EmailDomain) UpdateByRequest(req *UpdateEmailDomainRequest) {
+	d.UserRole = req.UserRole
+	d.RequireApproval = req.RequireApproval
+	d.InitUpdatedAt()
+}
+
+func (d *EmailDomain) ExportID() int {
+	return d.ID
+}
+
+func (d *EmailDomain) ExportUserRole() int {
+	return d.UserRole
+}
+
+func (d *EmailDomain) IsVerified() bool {
+	if d.Status == EMAIL_DOMAIN_STATUS_VERIFIED {
+		return true
+	}
+	return false
+}
+
+func (d *EmailDomain) IsRequireApproval() bool {
+	return d.RequireApproval
+}
+
+func (d *EmailDomain) Verify() {
+	d.Status = EMAIL_DOMAIN_STATUS_VERIFIED
+	d.VerifiedAt = time.Now().UTC()
+	d.InitUpdatedAt()
+}
+
+func (d *EmailDomain) ExportVerificationRecord() *DNSRecord {
+	return &DNSRecord{
+		Type:  DNS_RECORD_TYPE_TXT,
+		Name:  DNS_VERIFICATION_RECORD_PREFIX + d.Domain,
+		Value: DNS_VERIFICATION_VALUE_PREFIX + d.VerificationToken,
+	}
+}
+
+// check the TXT records resolved from the verification record name.
+func (d *EmailDomain) DoesTXTRecordsMatch(records []string) bool {
+	return DoesTXTRecordsInclude(records, d.ExportVerificationRecord().Value)
+}
+
+func (d *EmailDomain) Export() *EmailDomainForExport {
+	ret := &EmailDomainForExport{
+		ID:                 idconvertor.ConvertIntToString(d.ID),
+		TeamID:             idconvertor.ConvertIntToString(d.TeamID),
+		Domain:             d.Domain,
+		UserRole:           d.UserRole,
+		RequireApproval:    d.RequireApproval,
+		Verified:           d.IsVerified(),
+		VerificationRecord: d.ExportVerificationRecord(),
+		CreatedAt:          d.CreatedAt,
+		UpdatedAt:          d.UpdatedAt,
+	}
+	if d.IsVerified() {
+		ret.VerifiedAt = &d.VerifiedAt
+	}
+	return ret
+}
+
+// the domain part of email address in lower case, return empty string if email is invalid.
+func ExtractDomainFromEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
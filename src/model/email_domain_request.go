@@ -0,0 +1,26 @@
+package model
+
+import "strings"
+
+type CreateEmailDomainRequest struct {
+	Domain          string `json:"domain" validate:"required,fqdn,max=255"`
+	UserRole        int    `json:"userRole" validate:"required,oneof=2 3 4"` // the default role of auto-joined member
+	RequireApproval bool   `json:"requireApproval"`
+}
+
+func NewCreateEmailDomainRequest() *CreateEmailDomainRequest {
+	return &CreateEmailDomainRequest{}
+}
+
+func (req *CreateEmailDomainRequest) ExportDomain() string {
+	return strings.ToLower(req.Domain)
+}
+
+type UpdateEmailDomainRequest struct {
+	UserRole        int  `json:"userRole" validate:"required,oneof=2 3 4"`
+	RequireApproval bool `json:"requireApproval"`
+}
+
+func NewUpdateEmailDomainRequest() *UpdateEmailDomainRequest {
+	return &UpdateEmailDomainRequest{}
+}
@@ -0,0 +1,33 @@
+package model
+
+type EmailDomainResponse struct {
+	*EmailDomainForExport
+}
+
+func NewEmailDomainResponse(emailDomain *EmailDomain) *EmailDomainResponse {
+	return &EmailDomainResponse{
+		EmailDomainForExport: emailDomain.Export(),
+	}
+}
+
+func (resp *EmailDomainResponse) ExportForFeedback() interface{} {
+	return resp
+}
+
+type GetAllEmailDomainsResponse struct {
+	EmailDomains []*EmailDomainForExport
+}
+
+func NewGetAllEmailDomainsResponse(emailDomains []*EmailDomain) *GetAllEmailDomainsResponse {
+	resp := &GetAllEmailDomainsResponse{
+		EmailDomains: make([]*EmailDomainForExport, 0, len(emailDomains)),
+	}
+	for _, emailDomain := range emailDomains {
+		resp.EmailDomains = append(resp.EmailDomains, emailDomain.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllEmailDomainsResponse) ExportForFeedback() interface{} {
+	return resp.EmailDomains
+}
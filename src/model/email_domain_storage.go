@@ -0,0 +1,72 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type EmailDomainStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewEmailDomainStorage(db *gorm.DB, logger *zap.SugaredLogger) *EmailDomainStorage {
+	return &EmailDomainStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *EmailDomainStorage) Create(u *EmailDomain) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *EmailDomainStorage) RetrieveByTeamID(teamID int) ([]*EmailDomain, error) {
+	var emailDomains []*EmailDomain
+	if err := d.db.Where("team_id = ?", teamID).Order("id asc").Find(&emailDomains).Error; err != nil {
+		return nil, err
+	}
+	return emailDomains, nil
+}
+
+func (d *EmailDomainStorage) RetrieveByTeamIDAndID(teamID int, id int) (*EmailDomain, error) {
+	u := &EmailDomain{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// retrieve the verified email domains of all teams for auto-join.
+func (d *EmailDomainStorage) RetrieveVerifiedByDomain(domain string) ([]*EmailDomain, error) {
+	var emailDomains []*EmailDomain
+	if err := d.db.Where("domain = ? AND status = ?", domain, EMAIL_DOMAIN_STATUS_VERIFIED).Find(&emailDomains).Error; err != nil {
+		return nil, err
+	}
+	return emailDomains, nil
+}
+
+func (d *EmailDomainStorage) DoesDomainExist(teamID int, domain string) (bool, error) {
+	var count int64
+	if err := d.db.Model(&EmailDomain{}).Where("team_id = ? AND domain = ?", teamID, domain).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d *EmailDomainStorage) UpdateByID(u *EmailDomain) error {
+	if err := d.db.Model(&EmailDomain{}).Where("id = ?", u.ID).Select("user_role", "require_approval", "status", "verified_at", "updated_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *EmailDomainStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&EmailDomain{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
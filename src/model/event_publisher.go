@@ -0,0 +1,30 @@
+package model
+
+import (
+	"context"
+
+	redis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+type EventPublisher struct {
+	logger  *zap.SugaredLogger
+	cache   *redis.Client
+	context context.Context
+}
+
+func NewEventPublisher(cache *redis.Client, logger *zap.SugaredLogger) *EventPublisher {
+	return &EventPublisher{
+		logger:  logger,
+		cache:   cache,
+		context: context.Background(),
+	}
+}
+
+func (p *EventPublisher) Publish(event *SupervisorEvent) error {
+	payload, errInExport := event.Export()
+	if errInExport != nil {
+		return errInExport
+	}
+	return p.cache.Publish(p.context, SUPERVISOR_EVENT_CHANNEL, payload).Err()
+}
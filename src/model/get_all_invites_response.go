@@ -0,0 +1,19 @@
+package model
+
+type GetAllInvitesResponse struct {
+	AllInvites []*InviteForExport
+}
+
+func NewGetAllInvitesResponse(invites []*Invite) *GetAllInvitesResponse {
+	resp := &GetAllInvitesResponse{
+		AllInvites: make([]*InviteForExport, 0, len(invites)),
+	}
+	for _, invite := range invites {
+		resp.AllInvites = append(resp.AllInvites, invite.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllInvitesResponse) ExportForFeedback() interface{} {
+	return resp.AllInvites
+}
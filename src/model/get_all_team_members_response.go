@@ -21,6 +21,8 @@ type TeamMemberWithUserInfoForExportConverted struct {
 	UserStatus   int                   `json:"userStatus"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
+
+	TemporaryGrants []*TemporaryGrantForExport `json:"temporaryGrants"` // the grants active now
 }
 
 func NewTeamMemberWithUserInfoForExportConverted(i *TeamMemberWithUserInfoForExport) *TeamMemberWithUserInfoForExportConverted {
@@ -38,6 +40,8 @@ func NewTeamMemberWithUserInfoForExportConverted(i *TeamMemberWithUserInfoForExp
 		UserStatus:   i.UserStatus,
 		CreatedAt:    i.CreatedAt,
 		UpdatedAt:    i.UpdatedAt,
+
+		TemporaryGrants: i.TemporaryGrants,
 	}
 }
 
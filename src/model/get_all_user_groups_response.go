@@ -0,0 +1,20 @@
+package model
+
+type GetAllUserGroupsResponse struct {
+	UserGroups []*UserGroupForExport
+}
+
+func NewGetAllUserGroupsResponse(userGroups []*UserGroup, userGroupMembers []*UserGroupMember) *GetAllUserGroupsResponse {
+	lt := BuildUserGroupIDLookUpTableForTeamMemberIDs(userGroupMembers)
+	resp := &GetAllUserGroupsResponse{
+		UserGroups: make([]*UserGroupForExport, 0, len(userGroups)),
+	}
+	for _, userGroup := range userGroups {
+		resp.UserGroups = append(resp.UserGroups, userGroup.Export(lt[userGroup.ID]))
+	}
+	return resp
+}
+
+func (resp *GetAllUserGroupsResponse) ExportForFeedback() interface{} {
+	return resp.UserGroups
+}
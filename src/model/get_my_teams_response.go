@@ -22,6 +22,7 @@ type MyTeamResponse struct {
 	TeamMemberID         string                `json:"teamMemberID"`
 	TeamMemberPermission *TeamMemberPermission `json:"teamMemberPermission"`
 	TeamPermission       *TeamPermission       `json:"permission"`
+	Archive              *TeamArchiveForExport `json:"archive"` // the banner data of archived team
 	JoinedAt             time.Time             `json:"-"`
 }
 
@@ -36,6 +37,7 @@ func NewMyTeamResponse(team *Team, targetTeamMember *TeamMember) *MyTeamResponse
 		TeamMemberID:         idconvertor.ConvertIntToString(targetTeamMember.ID),
 		TeamMemberPermission: targetTeamMember.ExportPermission(),
 		TeamPermission:       team.ExportTeamPermission(),
+		Archive:              team.ExportArchive(),
 		JoinedAt:             targetTeamMember.CreatedAt,
 	}
 }
@@ -59,6 +61,7 @@ func NewGetMyTeamsResponse(teams []*Team, teamMembersLT map[int]*TeamMemberForEx
 			TeamMemberID:         idconvertor.ConvertIntToString(targetTeamMember.ID),
 			TeamMemberPermission: targetTeamMember.Permission,
 			TeamPermission:       team.ExportTeamPermission(),
+			Archive:              team.ExportArchive(),
 			JoinedAt:             targetTeamMember.CreatedAt,
 		}
 		ret.MyTeams = append(ret.MyTeams, myTeam)
@@ -0,0 +1,17 @@
+package model
+
+type GetTargetTeamByHostResponse struct {
+	Team   *GetTargetTeamByInternalRequestResponse `json:"team"`
+	Domain *DomainForExport                        `json:"domain"`
+}
+
+func NewGetTargetTeamByHostResponse(team *Team, domain *Domain) *GetTargetTeamByHostResponse {
+	return &GetTargetTeamByHostResponse{
+		Team:   NewGetTargetTeamByInternalRequestResponse(team),
+		Domain: domain.Export(),
+	}
+}
+
+func (resp *GetTargetTeamByHostResponse) ExportForFeedback() interface{} {
+	return resp
+}
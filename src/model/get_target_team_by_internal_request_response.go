@@ -8,14 +8,15 @@ import (
 )
 
 type GetTargetTeamByInternalRequestResponse struct {
-	ID         string    `json:"id"`
-	UID        uuid.UUID `json:"uid"`
-	Name       string    `json:"name"`
-	Identifier string    `json:"identifier"`
-	Icon       string    `json:"icon"`
-	Permission string    `json:"permission"` // for team permission config
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         string                `json:"id"`
+	UID        uuid.UUID             `json:"uid"`
+	Name       string                `json:"name"`
+	Identifier string                `json:"identifier"`
+	Icon       string                `json:"icon"`
+	Permission string                `json:"permission"` // for team permission config
+	Archive    *TeamArchiveForExport `json:"archive"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
 }
 
 func NewGetTargetTeamByInternalRequestResponse(team *Team) *GetTargetTeamByInternalRequestResponse {
@@ -26,6 +27,7 @@ func NewGetTargetTeamByInternalRequestResponse(team *Team) *GetTargetTeamByInter
 		Identifier: team.Identifier,
 		Icon:       team.Icon,
 		Permission: team.Permission,
+		Archive:    team.ExportArchive(),
 		CreatedAt:  team.CreatedAt,
 		UpdatedAt:  team.UpdatedAt,
 	}
@@ -8,14 +8,15 @@ import (
 )
 
 type GetTeamByTeamIDResponse struct {
-	ID         string          `json:"id"`
-	UID        uuid.UUID       `json:"uid"`
-	Name       string          `json:"name"`
-	Identifier string          `json:"identifier"`
-	Icon       string          `json:"icon"`
-	Permission *TeamPermission `json:"permission"`
-	CreatedAt  time.Time       `json:"createdAt"`
-	UpdatedAt  time.Time       `json:"updatedAt"`
+	ID         string                `json:"id"`
+	UID        uuid.UUID             `json:"uid"`
+	Name       string                `json:"name"`
+	Identifier string                `json:"identifier"`
+	Icon       string                `json:"icon"`
+	Permission *TeamPermission       `json:"permission"`
+	Archive    *TeamArchiveForExport `json:"archive"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	UpdatedAt  time.Time             `json:"updatedAt"`
 }
 
 func NewGetTeamByTeamIDResponse(t *Team) *GetTeamByTeamIDResponse {
@@ -26,6 +27,7 @@ func NewGetTeamByTeamIDResponse(t *Team) *GetTeamByTeamIDResponse {
 		Identifier: t.Identifier,
 		Icon:       t.Icon,
 		Permission: t.ExportTeamPermission(),
+		Archive:    t.ExportArchive(),
 		CreatedAt:  t.CreatedAt,
 		UpdatedAt:  t.UpdatedAt,
 	}
@@ -2,11 +2,15 @@ package model
 
 type TeamIconUploadAddressResponse struct {
 	UploadAddress string `json:"uploadAddress"`
+	FileName      string `json:"fileName"`
+	ContentType   string `json:"contentType"`
 }
 
-func NewTeamIconUploadAddressResponse(presignedURL string) *TeamIconUploadAddressResponse {
+func NewTeamIconUploadAddressResponse(presignedURL string, fileName string, contentType string) *TeamIconUploadAddressResponse {
 	return &TeamIconUploadAddressResponse{
 		UploadAddress: presignedURL,
+		FileName:      fileName,
+		ContentType:   contentType,
 	}
 }
 
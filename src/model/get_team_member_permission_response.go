@@ -0,0 +1,15 @@
+package model
+
+type GetTeamMemberPermissionResponse struct {
+	*TeamMemberPermission
+}
+
+func NewGetTeamMemberPermissionResponse(teamMember *TeamMember) *GetTeamMemberPermissionResponse {
+	return &GetTeamMemberPermissionResponse{
+		TeamMemberPermission: teamMember.ExportPermission(),
+	}
+}
+
+func (resp *GetTeamMemberPermissionResponse) ExportForFeedback() interface{} {
+	return resp
+}
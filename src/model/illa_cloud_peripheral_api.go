@@ -14,6 +14,7 @@ const (
 	EMAIL_DELIVER_USAGE_VERIFICATIONCODE = "code"
 	EMAIL_DELIVER_INVITE_EMAIL           = "invite"
 	EMAIL_DELIVER_SHARE_APP_EMAIL        = "shareApp"
+	EMAIL_DELIVER_TRANSFER_OWNER_EMAIL   = "transferOwner"
 )
 
 func SendSubscriptionEmail(email string) error {
@@ -39,3 +40,25 @@ func SendVerificationEmail(email, code, usage string) error {
 	fmt.Printf("response: %+v, err: %+v", resp, err)
 	return nil
 }
+
+func SendTransferOwnerEmail(email, teamName, formerOwnerNickname, newOwnerNickname string) error {
+	client := resty.New()
+	resp, err := client.R().
+		SetBody(map[string]string{"email": email, "teamName": teamName, "formerOwner": formerOwnerNickname, "newOwner": newOwnerNickname}).
+		Post(EMAIL_DELIVER_BASEURL + EMAIL_DELIVER_TRANSFER_OWNER_EMAIL)
+	if err != nil || resp.StatusCode() != http.StatusOK {
+		return errors.New("failed to send transfer owner email")
+	}
+	return nil
+}
+
+func SendInviteEmail(email, teamName, inviterNickname, inviteLink string, userRole int) error {
+	client := resty.New()
+	resp, err := client.R().
+		SetBody(map[string]interface{}{"email": email, "teamName": teamName, "inviter": inviterNickname, "inviteLink": inviteLink, "userRole": userRole}).
+		Post(EMAIL_DELIVER_BASEURL + EMAIL_DELIVER_INVITE_EMAIL)
+	if err != nil || resp.StatusCode() != http.StatusOK {
+		return errors.New("failed to send invite email")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package model
+
+type ImportTeamMembersResponse struct {
+	DryRun    bool               `json:"dryRun"`
+	Total     int                `json:"total"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Rows      []*MemberImportRow `json:"rows"`
+}
+
+func NewImportTeamMembersResponse(dryRun bool, rows []*MemberImportRow) *ImportTeamMembersResponse {
+	resp := &ImportTeamMembersResponse{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   rows,
+	}
+	for _, row := range rows {
+		if row.IsSucceeded() {
+			resp.Succeeded++
+			continue
+		}
+		resp.Failed++
+	}
+	return resp
+}
+
+func (resp *ImportTeamMembersResponse) ExportForFeedback() interface{} {
+	return resp
+}
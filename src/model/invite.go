@@ -0,0 +1,271 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const (
+	INVITE_CATEGORY_EMAIL = 1
+	INVITE_CATEGORY_LINK  = 2
+)
+
+const (
+	INVITE_STATUS_PENDING  = 1
+	INVITE_STATUS_ACCEPTED = 2
+	INVITE_STATUS_REVOKED  = 3
+)
+
+const INVITE_EMAIL_EXPIRATION = time.Hour * 24 * 7
+const INVITE_ACCEPT_PAGE_PATH = "/invite?inviteHash="
+const INVITE_LINK_JOIN_PAGE_PATH = "/join?inviteLinkHash="
+const INVITE_LINK_UNLIMITED_USES = 0
+
+type Invite struct {
+	ID                 int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:invite_ukey"`
+	UID                uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:invite_ukey"`
+	Category           int       `json:"category" gorm:"column:category;type:smallint"`
+	TeamID             int       `json:"teamID" gorm:"column:team_id;type:bigserial"`
+	TeamMemberID       int       `json:"teamMemberID" gorm:"column:team_member_id;type:bigserial"`              // the team member who issued this invite
+	TargetTeamMemberID int       `json:"targetTeamMemberID" gorm:"column:target_team_member_id;type:bigserial"` // the pending team member which this invite will activate
+	Email              string    `json:"email" gorm:"column:email;type:varchar;size:255"`
+	EmailStatus        bool      `json:"emailStatus" gorm:"column:email_status;type:boolean"`
+	UserRole           int       `json:"userRole" gorm:"column:user_role;type:smallint"`
+	Status             int       `json:"status" gorm:"column:status;type:smallint"`
+	UserGroupIDs       string    `json:"userGroupIDs" gorm:"column:user_group_ids;type:jsonb"` // the user groups which invitee will join
+	MaxUses            int       `json:"maxUses" gorm:"column:max_uses;type:integer"`          // for invite link, 0 means unlimited
+	UsedCount          int       `json:"usedCount" gorm:"column:used_count;type:integer"`
+	ExpiredAt          time.Time `gorm:"column:expired_at;type:timestamp"` // zero value means never expire
+	CreatedAt          time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type InviteForExport struct {
+	ID                 string    `json:"inviteID"`
+	Category           int       `json:"category"`
+	TeamID             string    `json:"teamID"`
+	TeamMemberID       string    `json:"teamMemberID"`
+	TargetTeamMemberID string    `json:"targetTeamMemberID"`
+	Email              string    `json:"email"`
+	EmailStatus        bool      `json:"emailStatus"`
+	UserRole           int       `json:"userRole"`
+	UserGroupIDs       []string  `json:"userGroupIDs"`
+	Status             int       `json:"status"`
+	Expired            bool      `json:"expired"`
+	ExpiredAt          time.Time `json:"expiredAt"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+type InviteLinkForExport struct {
+	ID           string     `json:"inviteLinkID"`
+	TeamID       string     `json:"teamID"`
+	UserRole     int        `json:"userRole"`
+	UserGroupIDs []string   `json:"userGroupIDs"`
+	InviteLink   string     `json:"inviteLink"`
+	MaxUses      int        `json:"maxUses"`
+	UsedCount    int        `json:"usedCount"`
+	Expired      bool       `json:"expired"`
+	ExpiredAt    *time.Time `json:"expiredAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+func NewInvite() *Invite {
+	return &Invite{}
+}
+
+func NewEmailInvite(teamMember *TeamMember, targetTeamMember *TeamMember, email string) *Invite {
+	invite := &Invite{
+		Category:           INVITE_CATEGORY_EMAIL,
+		TeamID:             teamMember.TeamID,
+		TeamMemberID:       teamMember.ExportID(),
+		TargetTeamMemberID: targetTeamMember.ExportID(),
+		Email:              email,
+		EmailStatus:        false,
+		UserRole:           targetTeamMember.ExportUserRole(),
+		Status:             INVITE_STATUS_PENDING,
+	}
+	invite.InitUID()
+	invite.SetUserGroupIDs([]int{})
+	invite.InitExpiredAt(INVITE_EMAIL_EXPIRATION)
+	invite.InitCreatedAt()
+	invite.InitUpdatedAt()
+	return invite
+}
+
+func NewLinkInvite(teamMember *TeamMember, userRole int, maxUses int, expiration time.Duration) *Invite {
+	invite := &Invite{
+		Category:     INVITE_CATEGORY_LINK,
+		TeamID:       teamMember.TeamID,
+		TeamMemberID: teamMember.ExportID(),
+		UserRole:     userRole,
+		Status:       INVITE_STATUS_PENDING,
+		MaxUses:      maxUses,
+		UsedCount:    0,
+	}
+	invite.InitUID()
+	invite.SetUserGroupIDs([]int{})
+	if expiration > 0 {
+		invite.InitExpiredAt(expiration)
+	}
+	invite.InitCreatedAt()
+	invite.InitUpdatedAt()
+	return invite
+}
+
+func (i *Invite) ExportForInviteLink() *InviteLinkForExport {
+	ret := &InviteLinkForExport{
+		ID:           idconvertor.ConvertIntToString(i.ID),
+		TeamID:       idconvertor.ConvertIntToString(i.TeamID),
+		UserRole:     i.UserRole,
+		UserGroupIDs: i.ExportUserGroupIDsInString(),
+		InviteLink:   i.ExportInviteLink(),
+		MaxUses:      i.MaxUses,
+		UsedCount:    i.UsedCount,
+		Expired:      i.IsExpired(),
+		CreatedAt:    i.CreatedAt,
+	}
+	if !i.ExpiredAt.IsZero() {
+		ret.ExpiredAt = &i.ExpiredAt
+	}
+	return ret
+}
+
+func (i *Invite) Export() *InviteForExport {
+	return &InviteForExport{
+		ID:                 idconvertor.ConvertIntToString(i.ID),
+		Category:           i.Category,
+		TeamID:             idconvertor.ConvertIntToString(i.TeamID),
+		TeamMemberID:       idconvertor.ConvertIntToString(i.TeamMemberID),
+		TargetTeamMemberID: idconvertor.ConvertIntToString(i.TargetTeamMemberID),
+		Email:              i.Email,
+		EmailStatus:        i.EmailStatus,
+		UserRole:           i.UserRole,
+		UserGroupIDs:       i.ExportUserGroupIDsInString(),
+		Status:             i.Status,
+		Expired:            i.IsExpired(),
+		ExpiredAt:          i.ExpiredAt,
+		CreatedAt:          i.CreatedAt,
+		UpdatedAt:          i.UpdatedAt,
+	}
+}
+
+func (i *Invite) InitUID() {
+	i.UID = uuid.New()
+}
+
+func (i *Invite) InitCreatedAt() {
+	i.CreatedAt = time.Now().UTC()
+}
+
+func (i *Invite) InitUpdatedAt() {
+	i.UpdatedAt = time.Now().UTC()
+}
+
+func (i *Invite) InitExpiredAt(expiration time.Duration) {
+	i.ExpiredAt = time.Now().UTC().Add(expiration)
+}
+
+func (i *Invite) SetEmailStatus(emailStatus bool) {
+	i.EmailStatus = emailStatus
+	i.InitUpdatedAt()
+}
+
+func (i *Invite) Accept() {
+	i.Status = INVITE_STATUS_ACCEPTED
+	i.InitUpdatedAt()
+}
+
+func (i *Invite) Revoke() {
+	i.Status = INVITE_STATUS_REVOKED
+	i.InitUpdatedAt()
+}
+
+func (i *Invite) SetUserGroupIDs(userGroupIDs []int) {
+	r, _ := json.Marshal(userGroupIDs)
+	i.UserGroupIDs = string(r)
+}
+
+func (i *Invite) ExportUserGroupIDs() []int {
+	userGroupIDs := []int{}
+	json.Unmarshal([]byte(i.UserGroupIDs), &userGroupIDs)
+	return userGroupIDs
+}
+
+func (i *Invite) ExportUserGroupIDsInString() []string {
+	userGroupIDs := i.ExportUserGroupIDs()
+	ret := make([]string, 0, len(userGroupIDs))
+	for _, userGroupID := range userGroupIDs {
+		ret = append(ret, idconvertor.ConvertIntToString(userGroupID))
+	}
+	return ret
+}
+
+func (i *Invite) ExportID() int {
+	return i.ID
+}
+
+func (i *Invite) ExportUserRole() int {
+	return i.UserRole
+}
+
+// the invite hash is the uid in hex format, it is delivered to invitee via invite link.
+func (i *Invite) ExportHash() string {
+	return strings.Replace(i.UID.String(), "-", "", -1)
+}
+
+func (i *Invite) ExportInviteLink() string {
+	conf := config.GetInstance()
+	if i.IsLinkInvite() {
+		return conf.GetServeHTTPAddress() + INVITE_LINK_JOIN_PAGE_PATH + i.ExportHash()
+	}
+	return conf.GetServeHTTPAddress() + INVITE_ACCEPT_PAGE_PATH + i.ExportHash()
+}
+
+func (i *Invite) IsEmailInvite() bool {
+	if i.Category == INVITE_CATEGORY_EMAIL {
+		return true
+	}
+	return false
+}
+
+func (i *Invite) IsLinkInvite() bool {
+	if i.Category == INVITE_CATEGORY_LINK {
+		return true
+	}
+	return false
+}
+
+func (i *Invite) IsExpired() bool {
+	if i.ExpiredAt.IsZero() {
+		return false
+	}
+	return time.Now().UTC().After(i.ExpiredAt)
+}
+
+func (i *Invite) IsUsedUp() bool {
+	if i.MaxUses == INVITE_LINK_UNLIMITED_USES {
+		return false
+	}
+	return i.UsedCount >= i.MaxUses
+}
+
+func (i *Invite) DoesEmailMatch(email string) bool {
+	return strings.EqualFold(strings.TrimSpace(i.Email), strings.TrimSpace(email))
+}
+
+func ParseInviteHash(hash string) (uuid.UUID, error) {
+	return uuid.Parse(hash)
+}
+
+func (i *Invite) IsStatusPending() bool {
+	if i.Status == INVITE_STATUS_PENDING {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package model
+
+type InviteByEmailRequest struct {
+	Email        string   `json:"email" validate:"required,email"`
+	UserRole     int      `json:"userRole" validate:"required"`
+	UserGroupIDs []string `json:"userGroupIDs" validate:"max=50,dive,required"` // the user groups which invitee will join
+}
+
+func NewInviteByEmailRequest() *InviteByEmailRequest {
+	return &InviteByEmailRequest{}
+}
+
+func (req *InviteByEmailRequest) ExportUserRole() int {
+	return req.UserRole
+}
+
+func (req *InviteByEmailRequest) ExportUserGroupIDsInInt() []int {
+	return ConvertStringIDsToUniqueIntIDs(req.UserGroupIDs)
+}
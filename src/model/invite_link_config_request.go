@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type InviteLinkConfigRequest struct {
+	MaxUses      int      `json:"maxUses" validate:"gte=0"`                     // 0 means unlimited
+	ExpiresIn    int      `json:"expiresIn" validate:"gte=0"`                   // in seconds, 0 means never expire
+	UserGroupIDs []string `json:"userGroupIDs" validate:"max=50,dive,required"` // the user groups which joined member will join
+}
+
+func NewInviteLinkConfigRequest() *InviteLinkConfigRequest {
+	return &InviteLinkConfigRequest{}
+}
+
+func (req *InviteLinkConfigRequest) ExportMaxUses() int {
+	return req.MaxUses
+}
+
+func (req *InviteLinkConfigRequest) ExportExpiration() time.Duration {
+	return time.Duration(req.ExpiresIn) * time.Second
+}
+
+func (req *InviteLinkConfigRequest) ExportUserGroupIDsInInt() []int {
+	return ConvertStringIDsToUniqueIntIDs(req.UserGroupIDs)
+}
@@ -0,0 +1,21 @@
+package model
+
+type InviteLinkPreviewResponse struct {
+	TeamName       string `json:"teamName"`
+	TeamIdentifier string `json:"teamIdentifier"`
+	TeamIcon       string `json:"teamIcon"`
+	UserRole       int    `json:"userRole"`
+}
+
+func NewInviteLinkPreviewResponse(team *Team, invite *Invite) *InviteLinkPreviewResponse {
+	return &InviteLinkPreviewResponse{
+		TeamName:       team.Name,
+		TeamIdentifier: team.GetIdentifier(),
+		TeamIcon:       team.Icon,
+		UserRole:       invite.ExportUserRole(),
+	}
+}
+
+func (resp *InviteLinkPreviewResponse) ExportForFeedback() interface{} {
+	return resp
+}
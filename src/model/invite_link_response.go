@@ -0,0 +1,15 @@
+package model
+
+type InviteLinkResponse struct {
+	*InviteLinkForExport
+}
+
+func NewInviteLinkResponse(invite *Invite) *InviteLinkResponse {
+	return &InviteLinkResponse{
+		InviteLinkForExport: invite.ExportForInviteLink(),
+	}
+}
+
+func (resp *InviteLinkResponse) ExportForFeedback() interface{} {
+	return resp
+}
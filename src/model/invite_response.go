@@ -0,0 +1,15 @@
+package model
+
+type InviteResponse struct {
+	*InviteForExport
+}
+
+func NewInviteResponse(invite *Invite) *InviteResponse {
+	return &InviteResponse{
+		InviteForExport: invite.Export(),
+	}
+}
+
+func (resp *InviteResponse) ExportForFeedback() interface{} {
+	return resp
+}
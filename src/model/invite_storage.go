@@ -0,0 +1,182 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type InviteStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewInviteStorage(db *gorm.DB, logger *zap.SugaredLogger) *InviteStorage {
+	return &InviteStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *InviteStorage) Create(u *Invite) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *InviteStorage) RetrieveByID(id int) (*Invite, error) {
+	u := &Invite{}
+	if err := d.db.First(u, id).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *InviteStorage) RetrieveByUID(uid uuid.UUID) (*Invite, error) {
+	u := &Invite{}
+	if err := d.db.Where("uid = ?", uid).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *InviteStorage) RetrieveByTeamIDAndID(teamID int, id int) (*Invite, error) {
+	u := &Invite{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *InviteStorage) RetrieveByTeamIDAndCategoryAndStatus(teamID int, category int, status int) ([]*Invite, error) {
+	var invites []*Invite
+	if err := d.db.Where("team_id = ? AND category = ? AND status = ?", teamID, category, status).Order("id desc").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (d *InviteStorage) RetrieveActiveLinkByTeamIDAndUserRole(teamID int, userRole int) (*Invite, error) {
+	u := &Invite{}
+	if err := d.db.Where("team_id = ? AND category = ? AND status = ? AND user_role = ?", teamID, INVITE_CATEGORY_LINK, INVITE_STATUS_PENDING, userRole).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *InviteStorage) DoesEmailHasPendingInvite(teamID int, email string) (bool, error) {
+	var count int64
+	if err := d.db.Model(&Invite{}).Where("team_id = ? AND category = ? AND status = ? AND lower(email) = lower(?)", teamID, INVITE_CATEGORY_EMAIL, INVITE_STATUS_PENDING, email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// create the pending team member with the user groups of invite and the invite in one transaction, the pending team member takes seat once invited.
+func (d *InviteStorage) CreateWithTargetTeamMember(invite *Invite, targetTeamMember *TeamMember) error {
+	return takeSeats(d.db, invite.TeamID, func(tx *gorm.DB) error {
+		if err := tx.Create(targetTeamMember).Error; err != nil {
+			return err
+		}
+		invite.TargetTeamMemberID = targetTeamMember.ID
+		if err := createUserGroupMembers(tx, invite.TeamID, invite.ExportUserGroupIDs(), []int{targetTeamMember.ID}); err != nil {
+			return err
+		}
+		return tx.Create(invite).Error
+	})
+}
+
+// update all columns, since the email status may be updated to false.
+func (d *InviteStorage) UpdateByID(u *Invite) error {
+	if err := d.db.Model(&Invite{}).Where("id = ?", u.ID).Select("*").Omit("id").UpdateColumns(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// accept the invite and activate the target pending team member with the accepted user.
+// the target pending team member is reused, the other pending records of the user in team are removed, so the user only has one team member record in team.
+func (d *InviteStorage) AcceptByUser(invite *Invite, userID int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		var joined int64
+		if err := tx.Model(&TeamMember{}).Where("team_id = ? AND user_id = ? AND id <> ? AND status IN ?", invite.TeamID, userID, invite.TargetTeamMemberID, []int{TEAM_MEMBER_STATUS_OK, TEAM_MEMBER_STATUS_SUSPENDED}).Count(&joined).Error; err != nil {
+			return err
+		}
+		if joined > 0 {
+			return ErrTeamMemberAlreadyJoined
+		}
+		var pendingTeamMemberIDs []int
+		if err := tx.Model(&TeamMember{}).Where("team_id = ? AND user_id = ? AND id <> ?", invite.TeamID, userID, invite.TargetTeamMemberID).Pluck("id", &pendingTeamMemberIDs).Error; err != nil {
+			return err
+		}
+		if len(pendingTeamMemberIDs) > 0 {
+			if err := tx.Where("team_id = ? AND team_member_id IN ?", invite.TeamID, pendingTeamMemberIDs).Delete(&UserGroupMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("team_id = ? AND id IN ?", invite.TeamID, pendingTeamMemberIDs).Delete(&TeamMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&Invite{}).Where("team_id = ? AND target_team_member_id IN ? AND status = ?", invite.TeamID, pendingTeamMemberIDs, INVITE_STATUS_PENDING).UpdateColumns(map[string]interface{}{"status": INVITE_STATUS_REVOKED, "updated_at": now}).Error; err != nil {
+				return err
+			}
+		}
+		activate := tx.Model(&TeamMember{}).Where("team_id = ? AND id = ? AND status = ?", invite.TeamID, invite.TargetTeamMemberID, TEAM_MEMBER_STATUS_PENDING).UpdateColumns(map[string]interface{}{"user_id": userID, "status": TEAM_MEMBER_STATUS_OK, "updated_at": now})
+		if activate.Error != nil {
+			return activate.Error
+		}
+		if activate.RowsAffected != 1 {
+			return errors.New("pending team member of this invite not found.")
+		}
+		invite.Accept()
+		if err := tx.Model(&Invite{}).Where("id = ? AND status = ?", invite.ID, INVITE_STATUS_PENDING).UpdateColumns(map[string]interface{}{"status": invite.Status, "updated_at": invite.UpdatedAt}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// revoke the active invite link of the role and create a new one, so the old hash will be invalidated.
+func (d *InviteStorage) RenewLink(invite *Invite) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		if err := tx.Model(&Invite{}).Where("team_id = ? AND category = ? AND status = ? AND user_role = ?", invite.TeamID, INVITE_CATEGORY_LINK, INVITE_STATUS_PENDING, invite.UserRole).UpdateColumns(map[string]interface{}{"status": INVITE_STATUS_REVOKED, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(invite).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (d *InviteStorage) RevokeLinkByTeamIDAndUserRole(teamID int, userRole int) error {
+	if err := d.db.Model(&Invite{}).Where("team_id = ? AND category = ? AND status = ? AND user_role = ?", teamID, INVITE_CATEGORY_LINK, INVITE_STATUS_PENDING, userRole).UpdateColumns(map[string]interface{}{"status": INVITE_STATUS_REVOKED, "updated_at": time.Now().UTC()}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// consume one use of the invite link and create the team member with user groups of the invite link, the use count check is done in database for concurrent joins.
+func (d *InviteStorage) JoinByLink(invite *Invite, teamMember *TeamMember) error {
+	return takeSeats(d.db, invite.TeamID, func(tx *gorm.DB) error {
+		consume := tx.Model(&Invite{}).Where("id = ? AND status = ? AND (max_uses = ? OR used_count < max_uses)", invite.ID, INVITE_STATUS_PENDING, INVITE_LINK_UNLIMITED_USES).UpdateColumns(map[string]interface{}{"used_count": gorm.Expr("used_count + 1"), "updated_at": time.Now().UTC()})
+		if consume.Error != nil {
+			return consume.Error
+		}
+		if consume.RowsAffected != 1 {
+			return errors.New("invite link is unavaliable.")
+		}
+		if err := tx.Create(teamMember).Error; err != nil {
+			return err
+		}
+		return createUserGroupMembers(tx, invite.TeamID, invite.ExportUserGroupIDs(), []int{teamMember.ID})
+	})
+}
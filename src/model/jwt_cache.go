@@ -13,6 +13,8 @@ import (
 
 const (
 	USER_JWT_TOKEN_KEY_TEMPLATE         = "%d_jwt_expired_at"
+	USER_TEAM_JWT_TOKEN_KEY_TEMPLATE    = "%d_%d_team_jwt_revoked_at"
+	USER_JWT_TOKEN_ACTIVE_KEY_TEMPLATE  = "%d_%s_jwt_last_active_at"
 	EMAIL_JWT_TOKEN_KEY_PREFIX          = "email_%s"
 	DEFAULT_EMAIL_REGISTER_TOKEN_EXPIRE = 15 * time.Minute
 )
@@ -55,6 +57,46 @@ func (c *JWTCache) DoesUserJWTTokenAvaliable(user *User, jwtTokenExpireAt string
 	return jwtTokenExpireAt >= expireAtInCache, nil
 }
 
+// tokens issued before revokedAt will not avaliable for the target team, the record lives as long as the tokens.
+func (c *JWTCache) RevokeUserJWTTokenInTeam(teamID int, userID int, revokedAt time.Time) error {
+	key := fmt.Sprintf(USER_TEAM_JWT_TOKEN_KEY_TEMPLATE, teamID, userID)
+	return c.cache.Set(c.context, key, revokedAt.UTC().Unix(), ACCESS_TOKEN_LIFETIME).Err()
+}
+
+// the issued at of token is in seconds, so the token issued in the same second of revocation is avaliable,
+// this keeps the member removed and added again at once from being locked out with the new token.
+func (c *JWTCache) DoesUserJWTTokenAvaliableInTeam(teamID int, userID int, jwtTokenIssuedAt time.Time) (bool, error) {
+	key := fmt.Sprintf(USER_TEAM_JWT_TOKEN_KEY_TEMPLATE, teamID, userID)
+	revokedAtInCache, errInGet := c.cache.Get(c.context, key).Int64()
+
+	// check error, no revoke record means all tokens are avaliable
+	if errInGet == redis.Nil {
+		return true, nil
+	} else if errInGet != nil {
+		return false, errInGet
+	}
+	// check revoked
+	return jwtTokenIssuedAt.UTC().Unix() >= revokedAtInCache, nil
+}
+
+// the token is identified by its hash, the activity record lives as long as the token.
+func (c *JWTCache) TouchUserJWTTokenActiveAt(userID int, jwtTokenHash string, activeAt time.Time) error {
+	key := fmt.Sprintf(USER_JWT_TOKEN_ACTIVE_KEY_TEMPLATE, userID, jwtTokenHash)
+	return c.cache.Set(c.context, key, activeAt.UTC().Unix(), ACCESS_TOKEN_LIFETIME).Err()
+}
+
+// zero value means no activity recorded for the token.
+func (c *JWTCache) GetUserJWTTokenLastActiveAt(userID int, jwtTokenHash string) (time.Time, error) {
+	key := fmt.Sprintf(USER_JWT_TOKEN_ACTIVE_KEY_TEMPLATE, userID, jwtTokenHash)
+	lastActiveAt, errInGet := c.cache.Get(c.context, key).Int64()
+	if errInGet == redis.Nil {
+		return time.Time{}, nil
+	} else if errInGet != nil {
+		return time.Time{}, errInGet
+	}
+	return time.Unix(lastActiveAt, 0).UTC(), nil
+}
+
 func (c *JWTCache) SetTokenForEmail(email string, jwtToken string) error {
 	key := fmt.Sprintf(EMAIL_JWT_TOKEN_KEY_PREFIX, email)
 	return c.cache.Set(c.context, key, jwtToken, DEFAULT_EMAIL_REGISTER_TOKEN_EXPIRE).Err()
@@ -0,0 +1,31 @@
+package model
+
+const (
+	PERMISSION_EFFECT_ALLOW = "allow"
+	PERMISSION_EFFECT_DENY  = "deny"
+)
+
+const PERMISSION_OVERRIDE_ALL_UNITS = 0
+
+// grant or deny an attribute on a unit, the UnitID 0 means all units of the unit type.
+type PermissionOverride struct {
+	Effect    string `json:"effect" validate:"oneof=allow deny"`
+	Category  int    `json:"category" validate:"min=1,max=4"`
+	UnitType  int    `json:"unitType" validate:"required"`
+	UnitID    int    `json:"unitID"`
+	Attribute int    `json:"attribute" validate:"required"`
+}
+
+func (o *PermissionOverride) IsAllow() bool {
+	if o.Effect == PERMISSION_EFFECT_ALLOW {
+		return true
+	}
+	return false
+}
+
+func (o *PermissionOverride) DoesMatchAllUnits() bool {
+	if o.UnitID == PERMISSION_OVERRIDE_ALL_UNITS {
+		return true
+	}
+	return false
+}
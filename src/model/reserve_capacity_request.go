@@ -0,0 +1,13 @@
+package model
+
+type ReserveCapacityRequest struct {
+	Storage int64 `json:"storage" validate:"required,gt=0"` // in bytes
+}
+
+func NewReserveCapacityRequest() *ReserveCapacityRequest {
+	return &ReserveCapacityRequest{}
+}
+
+func (req *ReserveCapacityRequest) ExportStorage() int64 {
+	return req.Storage
+}
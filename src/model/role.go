@@ -0,0 +1,156 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// the custom role grants attributes on top of the built-in user role.
+type Role struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:roles_id_team_id"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar;size:255;not null"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:roles_id_team_id"`
+	Permissions string    `json:"permissions" gorm:"column:permissions;type:jsonb"`
+	EditorSeat  bool      `json:"editorSeat" gorm:"column:editor_seat;type:boolean"` // the role grants attributes beyond viewer, so its users take editor seats
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+// the role permission applies to all units of the unit type.
+type RolePermission struct {
+	Category  int `json:"category" validate:"min=1,max=4"`
+	UnitType  int `json:"unitType" validate:"required"`
+	Attribute int `json:"attribute" validate:"required"`
+}
+
+type RoleForExport struct {
+	ID          string            `json:"roleID"`
+	TeamID      string            `json:"teamID"`
+	Name        string            `json:"name"`
+	Permissions []*RolePermission `json:"permissions"`
+	UserIDs     []string          `json:"userIDs"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+func NewRoleByCreateRequest(teamID int, req *RoleRequest) *Role {
+	role := &Role{
+		TeamID: teamID,
+	}
+	role.InitUID()
+	role.InitCreatedAt()
+	role.UpdateByRequest(req)
+	return role
+}
+
+func (r *Role) InitUID() {
+	r.UID = uuid.New()
+}
+
+func (r *Role) InitCreatedAt() {
+	r.CreatedAt = time.Now().UTC()
+}
+
+func (r *Role) InitUpdatedAt() {
+	r.UpdatedAt = time.Now().UTC()
+}
+
+func (r *Role) UpdateByRequest(req *RoleRequest) {
+	r.Name = req.Name
+	permissions, _ := json.Marshal(req.ExportPermissions())
+	r.Permissions = string(permissions)
+	r.InitUpdatedAt()
+}
+
+func (r *Role) SetEditorSeat(editorSeat bool) {
+	r.EditorSeat = editorSeat
+}
+
+func (r *Role) ExportID() int {
+	return r.ID
+}
+
+func (r *Role) ExportPermissions() []*RolePermission {
+	permissions := make([]*RolePermission, 0)
+	json.Unmarshal([]byte(r.Permissions), &permissions)
+	return permissions
+}
+
+// the role permissions are evaluated as allow overrides on all units.
+func (r *Role) ExportPermissionOverrides() []*PermissionOverride {
+	permissions := r.ExportPermissions()
+	overrides := make([]*PermissionOverride, 0, len(permissions))
+	for _, permission := range permissions {
+		overrides = append(overrides, permission.ExportPermissionOverride())
+	}
+	return overrides
+}
+
+func (r *Role) Export(userIDs []int) *RoleForExport {
+	ret := &RoleForExport{
+		ID:          idconvertor.ConvertIntToString(r.ID),
+		TeamID:      idconvertor.ConvertIntToString(r.TeamID),
+		Name:        r.Name,
+		Permissions: r.ExportPermissions(),
+		UserIDs:     make([]string, 0, len(userIDs)),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+	for _, userID := range userIDs {
+		ret.UserIDs = append(ret.UserIDs, idconvertor.ConvertIntToString(userID))
+	}
+	return ret
+}
+
+func (p *RolePermission) ExportPermissionOverride() *PermissionOverride {
+	return &PermissionOverride{
+		Effect:    PERMISSION_EFFECT_ALLOW,
+		Category:  p.Category,
+		UnitType:  p.UnitType,
+		UnitID:    PERMISSION_OVERRIDE_ALL_UNITS,
+		Attribute: p.Attribute,
+	}
+}
+
+type UserRoleRelation struct {
+	ID        int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID       uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	TeamID    int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:user_role_relations_team_role_user_id"`
+	RoleID    int       `json:"roleID" gorm:"column:role_id;type:bigserial;index:user_role_relations_team_role_user_id"`
+	UserID    int       `json:"userID" gorm:"column:user_id;type:bigserial;index:user_role_relations_team_role_user_id"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+func NewUserRoleRelation(teamID int, roleID int, userID int) *UserRoleRelation {
+	now := time.Now().UTC()
+	return &UserRoleRelation{
+		UID:       uuid.New(),
+		TeamID:    teamID,
+		RoleID:    roleID,
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func PickUpUserIDsInUserRoleRelations(userRoleRelations []*UserRoleRelation) []int {
+	ids := make([]int, len(userRoleRelations))
+	for serial, userRoleRelation := range userRoleRelations {
+		ids[serial] = userRoleRelation.UserID
+	}
+	return ids
+}
+
+// build lookup table of role id to user ids.
+func BuildRoleIDLookUpTableForUserIDs(userRoleRelations []*UserRoleRelation) map[int][]int {
+	lt := make(map[int][]int)
+	for _, userRoleRelation := range userRoleRelations {
+		lt[userRoleRelation.RoleID] = append(lt[userRoleRelation.RoleID], userRoleRelation.UserID)
+	}
+	return lt
+}
@@ -0,0 +1,29 @@
+package model
+
+type RoleRequest struct {
+	Name        string            `json:"name" validate:"required,max=255"`
+	Permissions []*RolePermission `json:"permissions" validate:"max=100,dive,required"`
+}
+
+func NewRoleRequest() *RoleRequest {
+	return &RoleRequest{}
+}
+
+func (req *RoleRequest) ExportPermissions() []*RolePermission {
+	if req.Permissions == nil {
+		return []*RolePermission{}
+	}
+	return req.Permissions
+}
+
+type UpdateRoleUsersRequest struct {
+	UserIDs []string `json:"userIDs" validate:"required,min=1,max=100,unique"`
+}
+
+func NewUpdateRoleUsersRequest() *UpdateRoleUsersRequest {
+	return &UpdateRoleUsersRequest{}
+}
+
+func (req *UpdateRoleUsersRequest) ExportUserIDsInInt() []int {
+	return ConvertStringIDsToUniqueIntIDs(req.UserIDs)
+}
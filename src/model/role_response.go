@@ -0,0 +1,34 @@
+package model
+
+type RoleResponse struct {
+	*RoleForExport
+}
+
+func NewRoleResponse(role *Role, userIDs []int) *RoleResponse {
+	return &RoleResponse{
+		RoleForExport: role.Export(userIDs),
+	}
+}
+
+func (resp *RoleResponse) ExportForFeedback() interface{} {
+	return resp
+}
+
+type GetAllRolesResponse struct {
+	Roles []*RoleForExport
+}
+
+func NewGetAllRolesResponse(roles []*Role, userRoleRelations []*UserRoleRelation) *GetAllRolesResponse {
+	lt := BuildRoleIDLookUpTableForUserIDs(userRoleRelations)
+	resp := &GetAllRolesResponse{
+		Roles: make([]*RoleForExport, 0, len(roles)),
+	}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, role.Export(lt[role.ID]))
+	}
+	return resp
+}
+
+func (resp *GetAllRolesResponse) ExportForFeedback() interface{} {
+	return resp.Roles
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RoleStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewRoleStorage(db *gorm.DB, logger *zap.SugaredLogger) *RoleStorage {
+	return &RoleStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *RoleStorage) Create(r *Role) (int, error) {
+	if err := d.db.Create(r).Error; err != nil {
+		return 0, err
+	}
+	return r.ID, nil
+}
+
+func (d *RoleStorage) RetrieveByTeamID(teamID int) ([]*Role, error) {
+	var roles []*Role
+	if err := d.db.Where("team_id = ?", teamID).Order("id asc").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (d *RoleStorage) RetrieveByTeamIDAndID(teamID int, id int) (*Role, error) {
+	r := &Role{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (d *RoleStorage) RetrieveByTeamIDAndIDs(teamID int, ids []int) ([]*Role, error) {
+	var roles []*Role
+	if err := d.db.Where("team_id = ? AND id IN ?", teamID, ids).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// retrieve the custom roles which assigned to the user.
+func (d *RoleStorage) RetrieveByTeamIDAndUserID(teamID int, userID int) ([]*Role, error) {
+	var roles []*Role
+	subQuery := d.db.Model(&UserRoleRelation{}).Select("role_id").Where("team_id = ? AND user_id = ?", teamID, userID)
+	if err := d.db.Where("team_id = ? AND id IN (?)", teamID, subQuery).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (d *RoleStorage) DoesNameExist(teamID int, name string, excludedID int) (bool, error) {
+	var count int64
+	if err := d.db.Model(&Role{}).Where("team_id = ? AND name = ? AND id <> ?", teamID, name, excludedID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// the editor seats will be taken by the role users when the role grants editor seat.
+func (d *RoleStorage) UpdateByID(r *Role) error {
+	return takeSeats(d.db, r.TeamID, func(tx *gorm.DB) error {
+		return tx.Model(r).Where("id = ?", r.ID).Select("*").Omit("id").Updates(r).Error
+	})
+}
+
+// delete the role with all its user relations and unit ACL entries.
+func (d *RoleStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND role_id = ?", teamID, id).Delete(&UserRoleRelation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ? AND role_id = ?", teamID, id).Delete(&UnitRoleRelation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ? AND id = ?", teamID, id).Delete(&Role{}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
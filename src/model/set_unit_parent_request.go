@@ -0,0 +1,16 @@
+package model
+
+import "github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+
+type SetUnitParentRequest struct {
+	ParentUnitType int    `json:"parentUnitType" validate:"required"`
+	ParentUnitID   string `json:"parentUnitID" validate:"required"`
+}
+
+func NewSetUnitParentRequest() *SetUnitParentRequest {
+	return &SetUnitParentRequest{}
+}
+
+func (req *SetUnitParentRequest) ExportParent() *UnitReference {
+	return NewUnitReference(req.ParentUnitType, idconvertor.ConvertStringToInt(req.ParentUnitID))
+}
@@ -6,18 +6,60 @@ import (
 )
 
 type Storage struct {
-	UserStorage       *UserStorage
-	TeamStorage       *TeamStorage
-	TeamMemberStorage *TeamMemberStorage
+	UserStorage                *UserStorage
+	TeamStorage                *TeamStorage
+	TeamMemberStorage          *TeamMemberStorage
+	InviteStorage              *InviteStorage
+	UserGroupStorage           *UserGroupStorage
+	UserGroupMemberStorage     *UserGroupMemberStorage
+	CapacityStorage            *CapacityStorage
+	EmailDomainStorage         *EmailDomainStorage
+	DomainStorage              *DomainStorage
+	TeamSettingRevisionStorage *TeamSettingRevisionStorage
+	TeamSecurityPolicyStorage  *TeamSecurityPolicyStorage
+	TeamIPAllowlistStorage     *TeamIPAllowlistStorage
+	RoleStorage                *RoleStorage
+	UserRoleRelationStorage    *UserRoleRelationStorage
+	UnitRoleRelationStorage    *UnitRoleRelationStorage
+	UnitRelationStorage        *UnitRelationStorage
+	TemporaryGrantStorage      *TemporaryGrantStorage
 }
 
 func NewStorage(postgresDriver *gorm.DB, logger *zap.SugaredLogger) *Storage {
 	userStorage := NewUserStorage(postgresDriver, logger)
 	teamStorage := NewTeamStorage(postgresDriver, logger)
 	teamMemberStorage := NewTeamMemberStorage(postgresDriver, logger)
+	inviteStorage := NewInviteStorage(postgresDriver, logger)
+	userGroupStorage := NewUserGroupStorage(postgresDriver, logger)
+	userGroupMemberStorage := NewUserGroupMemberStorage(postgresDriver, logger)
+	capacityStorage := NewCapacityStorage(postgresDriver, logger)
+	emailDomainStorage := NewEmailDomainStorage(postgresDriver, logger)
+	domainStorage := NewDomainStorage(postgresDriver, logger)
+	teamSettingRevisionStorage := NewTeamSettingRevisionStorage(postgresDriver, logger)
+	teamSecurityPolicyStorage := NewTeamSecurityPolicyStorage(postgresDriver, logger)
+	teamIPAllowlistStorage := NewTeamIPAllowlistStorage(postgresDriver, logger)
+	roleStorage := NewRoleStorage(postgresDriver, logger)
+	userRoleRelationStorage := NewUserRoleRelationStorage(postgresDriver, logger)
+	unitRoleRelationStorage := NewUnitRoleRelationStorage(postgresDriver, logger)
+	unitRelationStorage := NewUnitRelationStorage(postgresDriver, logger)
+	temporaryGrantStorage := NewTemporaryGrantStorage(postgresDriver, logger)
 	return &Storage{
-		UserStorage:       userStorage,
-		TeamStorage:       teamStorage,
-		TeamMemberStorage: teamMemberStorage,
+		UserStorage:                userStorage,
+		TeamStorage:                teamStorage,
+		TeamMemberStorage:          teamMemberStorage,
+		InviteStorage:              inviteStorage,
+		UserGroupStorage:           userGroupStorage,
+		UserGroupMemberStorage:     userGroupMemberStorage,
+		CapacityStorage:            capacityStorage,
+		EmailDomainStorage:         emailDomainStorage,
+		DomainStorage:              domainStorage,
+		TeamSettingRevisionStorage: teamSettingRevisionStorage,
+		TeamSecurityPolicyStorage:  teamSecurityPolicyStorage,
+		TeamIPAllowlistStorage:     teamIPAllowlistStorage,
+		RoleStorage:                roleStorage,
+		UserRoleRelationStorage:    userRoleRelationStorage,
+		UnitRoleRelationStorage:    unitRoleRelationStorage,
+		UnitRelationStorage:        unitRelationStorage,
+		TemporaryGrantStorage:      temporaryGrantStorage,
 	}
 }
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// supervisor events are published for other illa units (builder, drive etc.),
+// so they can react to the team changes, e.g. reassign the apps owned by a removed user.
+const SUPERVISOR_EVENT_CHANNEL = "illa_supervisor_event"
+
+const (
+	SUPERVISOR_EVENT_TEAM_MEMBER_REMOVED            = "teamMemberRemoved"
+	SUPERVISOR_EVENT_TEAM_MEMBER_LEFT               = "teamMemberLeft"
+	SUPERVISOR_EVENT_TEAM_OWNER_CHANGED             = "teamOwnerChanged"
+	SUPERVISOR_EVENT_TEAM_MEMBER_SUSPENDED          = "teamMemberSuspended"
+	SUPERVISOR_EVENT_TEAM_MEMBER_REACTIVATED        = "teamMemberReactivated"
+	SUPERVISOR_EVENT_TEAM_MEMBER_PERMISSION_CHANGED = "teamMemberPermissionChanged"
+	SUPERVISOR_EVENT_TEAM_MEMBER_ROLE_CHANGED       = "teamMemberRoleChanged"
+	SUPERVISOR_EVENT_TEAM_MEMBER_JOINED             = "teamMemberJoined"
+	SUPERVISOR_EVENT_TEAM_ARCHIVED                  = "teamArchived"
+	SUPERVISOR_EVENT_TEAM_RESTORED                  = "teamRestored"
+	SUPERVISOR_EVENT_TEAM_PURGED                    = "teamPurged"
+	SUPERVISOR_EVENT_TEAM_ACCESS_CONTROL_CHANGED    = "teamAccessControlChanged" // team permission, user groups or roles changed
+)
+
+// the operator of events triggered by other illa units, e.g. the unit relations changed by builder.
+const SUPERVISOR_EVENT_OPERATOR_SYSTEM = 0
+
+// these events change the access control of whole team, the others only change the target team member.
+var SupervisorTeamLevelEvents = map[string]bool{
+	SUPERVISOR_EVENT_TEAM_OWNER_CHANGED:          true,
+	SUPERVISOR_EVENT_TEAM_ARCHIVED:               true,
+	SUPERVISOR_EVENT_TEAM_RESTORED:               true,
+	SUPERVISOR_EVENT_TEAM_PURGED:                 true,
+	SUPERVISOR_EVENT_TEAM_ACCESS_CONTROL_CHANGED: true,
+}
+
+type SupervisorEvent struct {
+	Event        string    `json:"event"`
+	TeamID       string    `json:"teamID"`
+	UserID       string    `json:"userID"`
+	TeamMemberID string    `json:"teamMemberID"`
+	OperatorID   string    `json:"operatorID"` // the user who triggered this event
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func NewSupervisorEvent(event string, teamMember *TeamMember, operatorID int) *SupervisorEvent {
+	return &SupervisorEvent{
+		Event:        event,
+		TeamID:       idconvertor.ConvertIntToString(teamMember.TeamID),
+		UserID:       idconvertor.ConvertIntToString(teamMember.UserID),
+		TeamMemberID: idconvertor.ConvertIntToString(teamMember.ID),
+		OperatorID:   idconvertor.ConvertIntToString(operatorID),
+		CreatedAt:    time.Now().UTC(),
+	}
+}
+
+// the team level event, the user and team member fields are left empty.
+func NewSupervisorTeamEvent(event string, team *Team, operatorID int) *SupervisorEvent {
+	return NewSupervisorTeamEventByTeamID(event, team.ID, operatorID)
+}
+
+func NewSupervisorTeamEventByTeamID(event string, teamID int, operatorID int) *SupervisorEvent {
+	return &SupervisorEvent{
+		Event:      event,
+		TeamID:     idconvertor.ConvertIntToString(teamID),
+		OperatorID: idconvertor.ConvertIntToString(operatorID),
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+func NewSupervisorEventByPayload(payload string) (*SupervisorEvent, error) {
+	event := &SupervisorEvent{}
+	if err := json.Unmarshal([]byte(payload), event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (e *SupervisorEvent) IsTeamLevel() bool {
+	return SupervisorTeamLevelEvents[e.Event] || e.UserID == ""
+}
+
+func (e *SupervisorEvent) ExportTeamIDInInt() int {
+	return idconvertor.ConvertStringToInt(e.TeamID)
+}
+
+func (e *SupervisorEvent) ExportUserIDInInt() int {
+	return idconvertor.ConvertStringToInt(e.UserID)
+}
+
+func (e *SupervisorEvent) Export() (string, error) {
+	r, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return string(r), nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type SuspendTeamMemberRequest struct {
+	Reason         string    `json:"reason" validate:"max=255"`
+	SuspendedUntil time.Time `json:"suspendedUntil"` // optional, the member will be reactivated automatically after this time
+}
+
+func NewSuspendTeamMemberRequest() *SuspendTeamMemberRequest {
+	return &SuspendTeamMemberRequest{}
+}
+
+func (req *SuspendTeamMemberRequest) ExportSuspendedUntil() time.Time {
+	if req.SuspendedUntil.IsZero() {
+		return req.SuspendedUntil
+	}
+	return req.SuspendedUntil.UTC()
+}
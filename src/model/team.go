@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
 )
 
 const TEAM_DEFAULT_ID = 0
@@ -27,20 +29,30 @@ type Team struct {
 	Name       string    `json:"name" gorm:"column:name;type:varchar;size:255;not null"`
 	Identifier string    `json:"identifier" gorm:"column:identifier;type:varchar;size:255;not null"`
 	Icon       string    `json:"icon" gorm:"column:icon;type:varchar;size:255;not null"`
-	Permission string    `json:"permission" gorm:"column:permission;type:jsonb"` // for team permission config
+	Permission string    `json:"permission" gorm:"column:permission;type:jsonb"`      // for team permission config
+	ArchivedAt time.Time `json:"archivedAt" gorm:"column:archived_at;type:timestamp"` // zero value means the team is not archived
+	ArchivedBy int       `json:"archivedBy" gorm:"column:archived_by;type:bigint"`
 	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp"`
 	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp"`
 }
 
+// the archive banner data for team members.
+type TeamArchiveForExport struct {
+	ArchivedAt      time.Time `json:"archivedAt"`
+	ArchivedBy      string    `json:"archivedBy"`
+	RestorableUntil time.Time `json:"restorableUntil"`
+}
+
 type TeamForExport struct {
-	ID         int             `json:"id"`
-	UID        uuid.UUID       `json:"uid"`
-	Name       string          `json:"name"`
-	Identifier string          `json:"identifier"`
-	Icon       string          `json:"icon"`
-	Permission *TeamPermission `json:"permission"`
-	CreatedAt  time.Time       `json:"createdAt"`
-	UpdatedAt  time.Time       `json:"updatedAt"`
+	ID         int                   `json:"id"`
+	UID        uuid.UUID             `json:"uid"`
+	Name       string                `json:"name"`
+	Identifier string                `json:"identifier"`
+	Icon       string                `json:"icon"`
+	Permission *TeamPermission       `json:"permission"`
+	Archive    *TeamArchiveForExport `json:"archive"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	UpdatedAt  time.Time             `json:"updatedAt"`
 }
 
 type TeamsForExport []*TeamForExport
@@ -60,6 +72,7 @@ func (t *Team) Export() *TeamForExport {
 		Identifier: t.Identifier,
 		Icon:       t.Icon,
 		Permission: t.ExportTeamPermission(),
+		Archive:    t.ExportArchive(),
 		CreatedAt:  t.CreatedAt,
 		UpdatedAt:  t.UpdatedAt,
 	}
@@ -100,6 +113,42 @@ func (u *Team) GetIdentifier() string {
 	return u.Identifier
 }
 
+func (u *Team) IsArchived() bool {
+	return !u.ArchivedAt.IsZero()
+}
+
+func (u *Team) Archive(userID int) {
+	u.ArchivedAt = time.Now().UTC()
+	u.ArchivedBy = userID
+	u.InitUpdatedAt()
+}
+
+func (u *Team) Restore() {
+	u.ArchivedAt = time.Time{}
+	u.ArchivedBy = 0
+	u.InitUpdatedAt()
+}
+
+// the archived team can be restored before the retention window passed, then it will be purged.
+func (u *Team) ExportRestorableUntil() time.Time {
+	return u.ArchivedAt.Add(config.GetInstance().GetTeamArchiveRetention())
+}
+
+func (u *Team) IsRestorable() bool {
+	return u.IsArchived() && time.Now().UTC().Before(u.ExportRestorableUntil())
+}
+
+func (u *Team) ExportArchive() *TeamArchiveForExport {
+	if !u.IsArchived() {
+		return nil
+	}
+	return &TeamArchiveForExport{
+		ArchivedAt:      u.ArchivedAt,
+		ArchivedBy:      idconvertor.ConvertIntToString(u.ArchivedBy),
+		RestorableUntil: u.ExportRestorableUntil(),
+	}
+}
+
 func (u *Team) UpdateByUpdateTeamConfigRawRequest(rawReq map[string]interface{}) error {
 	var assertPass bool
 	for key, value := range rawReq {
@@ -169,6 +218,11 @@ func (u *Team) UpdateByUpdateTeamPermissionRawRequest(rawReq map[string]interfac
 	return nil
 }
 
+func (u *Team) SetIcon(icon string) {
+	u.Icon = icon
+	u.InitUpdatedAt()
+}
+
 func (u *Team) SetTeamPermission(tp *TeamPermission) {
 	u.Permission = tp.ExportForTeam()
 	u.InitUpdatedAt()
@@ -184,6 +238,10 @@ func (u *Team) ExportID() int {
 	return u.ID
 }
 
+func (u *Team) DoesInviteLinkEnabled() bool {
+	return u.ExportTeamPermission().DoesInviteLinkEnabled()
+}
+
 func (u *Team) DoesEditorOrViewerCanInviteMember() bool {
 	tp := u.ExportTeamPermission()
 	if tp.AllowEditorInvite && tp.AllowViewerInvite {
@@ -192,6 +250,18 @@ func (u *Team) DoesEditorOrViewerCanInviteMember() bool {
 	return false
 }
 
+// editor and viewer can only invite member when the team permission switch is on.
+func (u *Team) DoesUserRoleCanInviteMember(userRole int) bool {
+	tp := u.ExportTeamPermission()
+	switch userRole {
+	case USER_ROLE_EDITOR:
+		return tp.AllowEditorInvite
+	case USER_ROLE_VIEWER:
+		return tp.AllowViewerInvite
+	}
+	return true
+}
+
 type TeamPermission struct {
 	AllowEditorInvite           bool `json:"allowEditorInvite"`
 	AllowViewerInvite           bool `json:"allowViewerInvite"`
@@ -232,13 +302,21 @@ func (tp *TeamPermission) EnableInviteLink() {
 }
 
 func (tp *TeamPermission) DisableInviteLink() {
-	tp.InviteLinkEnabled = true
+	tp.InviteLinkEnabled = false
 }
 
 func (tp *TeamPermission) DoesInviteLinkEnabled() bool {
 	return tp.InviteLinkEnabled
 }
 
+func (tp *TeamPermission) DoesEditorCanInvite() bool {
+	return tp.AllowEditorInvite
+}
+
+func (tp *TeamPermission) DoesViewerCanInvite() bool {
+	return tp.AllowViewerInvite
+}
+
 func (tp *TeamPermission) DoesEditorCanManageTeamMember() bool {
 	return tp.AllowEditorManageTeamMember
 }
@@ -1,6 +1,11 @@
 package model
 
 import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strings"
+
 	"github.com/google/uuid"
 )
 
@@ -19,6 +24,19 @@ const TEAM_SYSTEM_FOLDER = "/system"
 const TEAM_ICON_FOLDER = "/icon"
 const TEAM_SPACE_FOLDER = "/team"
 
+const TEAM_ICON_FILE_NAME_MAX_LENGTH = 128
+
+// allowed team icon extensions and their content types, svg is not allowed since it can carry scripts.
+var TeamIconContentTypeMap = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+var fileNameIllegalCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
 type TeamDrive struct {
 	UID              uuid.UUID  `json:"uid"`
 	Drive            S3Instance `json:"-"`
@@ -38,7 +56,37 @@ func (d *TeamDrive) SetTeam(team *Team) {
 	d.TeamSpaceFolder = TEAM_FOLDER_PREFIX + team.GetUIDInString() + TEAM_SPACE_FOLDER
 }
 
-func (d *TeamDrive) GetIconUploadPreSignedURL(fileName string) (string, error) {
-	path := d.TeamSystemFolder + TEAM_ICON_FOLDER + "/" + fileName
-	return d.Drive.GetPreSignedPutURL(path)
+func (d *TeamDrive) GetIconUploadPreSignedURL(fileName string, contentType string) (string, error) {
+	return d.Drive.GetPreSignedPutURLWithContentType(d.getIconPath(fileName), contentType)
+}
+
+func (d *TeamDrive) DoesIconExist(fileName string) (bool, error) {
+	return d.Drive.DoesObjectExist(d.getIconPath(fileName))
+}
+
+func (d *TeamDrive) GetIconURL(fileName string) string {
+	return d.Drive.GetObjectURL(d.getIconPath(fileName))
+}
+
+func (d *TeamDrive) getIconPath(fileName string) string {
+	return d.TeamSystemFolder + TEAM_ICON_FOLDER + "/" + fileName
+}
+
+// sanitize the team icon file name, and return the sanitized file name with it's content type.
+func SanitizeTeamIconFileName(fileName string) (string, string, error) {
+	baseName := filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	ext := strings.ToLower(filepath.Ext(baseName))
+	contentType, hit := TeamIconContentTypeMap[ext]
+	if !hit {
+		return "", "", errors.New("team icon file extension not supported.")
+	}
+	name := fileNameIllegalCharsRegexp.ReplaceAllString(strings.TrimSuffix(baseName, filepath.Ext(baseName)), "_")
+	name = strings.Trim(name, ".")
+	if len(name) == 0 {
+		return "", "", errors.New("team icon file name is empty.")
+	}
+	if len(name)+len(ext) > TEAM_ICON_FILE_NAME_MAX_LENGTH {
+		name = name[:TEAM_ICON_FILE_NAME_MAX_LENGTH-len(ext)]
+	}
+	return name + ext, contentType, nil
 }
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const IP_NOT_ALLOWED_ERROR_FLAG = "ERROR_FLAG_IP_NOT_ALLOWED"
+
+type IPAllowlistEntry struct {
+	CIDR        string `json:"cidr"`
+	Description string `json:"description"`
+}
+
+// the empty allowlist means all IPs are allowed.
+type TeamIPAllowlist struct {
+	ID        int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID    int       `json:"teamID" gorm:"column:team_id;type:bigserial;uniqueIndex:team_ip_allowlists_team_id"`
+	Entries   string    `json:"entries" gorm:"column:entries;type:jsonb"`
+	UpdatedBy int       `json:"updatedBy" gorm:"column:updated_by;type:bigint"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type TeamIPAllowlistForExport struct {
+	TeamID    string              `json:"teamID"`
+	Entries   []*IPAllowlistEntry `json:"entries"`
+	UpdatedBy string              `json:"updatedBy"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+func NewTeamIPAllowlist(teamID int) *TeamIPAllowlist {
+	allowlist := &TeamIPAllowlist{
+		TeamID: teamID,
+	}
+	allowlist.SetEntries([]*IPAllowlistEntry{})
+	allowlist.InitCreatedAt()
+	allowlist.InitUpdatedAt()
+	return allowlist
+}
+
+func (a *TeamIPAllowlist) InitCreatedAt() {
+	a.CreatedAt = time.Now().UTC()
+}
+
+func (a *TeamIPAllowlist) InitUpdatedAt() {
+	a.UpdatedAt = time.Now().UTC()
+}
+
+// the bare IP in request will be normalized to single host CIDR.
+func (a *TeamIPAllowlist) UpdateByRequest(req *UpdateTeamIPAllowlistRequest, userID int) error {
+	entries := make([]*IPAllowlistEntry, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		cidr, errInNormalize := NormalizeCIDR(entry.CIDR)
+		if errInNormalize != nil {
+			return errInNormalize
+		}
+		entries = append(entries, &IPAllowlistEntry{CIDR: cidr, Description: entry.Description})
+	}
+	a.SetEntries(entries)
+	a.UpdatedBy = userID
+	a.InitUpdatedAt()
+	return nil
+}
+
+func (a *TeamIPAllowlist) SetEntries(entries []*IPAllowlistEntry) {
+	r, _ := json.Marshal(entries)
+	a.Entries = string(r)
+}
+
+func (a *TeamIPAllowlist) ExportEntries() []*IPAllowlistEntry {
+	entries := make([]*IPAllowlistEntry, 0)
+	json.Unmarshal([]byte(a.Entries), &entries)
+	return entries
+}
+
+func (a *TeamIPAllowlist) IsEnabled() bool {
+	return len(a.ExportEntries()) > 0
+}
+
+// the invalid IP is never allowed by an enabled allowlist.
+func (a *TeamIPAllowlist) DoesIPAllowed(rawIP string) bool {
+	entries := a.ExportEntries()
+	if len(entries) == 0 {
+		return true
+	}
+	ip := net.ParseIP(strings.TrimSpace(rawIP))
+	if ip == nil {
+		return false
+	}
+	for _, entry := range entries {
+		_, ipNet, errInParse := net.ParseCIDR(entry.CIDR)
+		if errInParse != nil {
+			continue
+		}
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *TeamIPAllowlist) Export() *TeamIPAllowlistForExport {
+	return &TeamIPAllowlistForExport{
+		TeamID:    idconvertor.ConvertIntToString(a.TeamID),
+		Entries:   a.ExportEntries(),
+		UpdatedBy: idconvertor.ConvertIntToString(a.UpdatedBy),
+		UpdatedAt: a.UpdatedAt,
+	}
+}
+
+func NormalizeCIDR(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "/") {
+		ip := net.ParseIP(raw)
+		if ip == nil {
+			return "", errors.New("invalid IP address: " + raw)
+		}
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+	_, ipNet, errInParse := net.ParseCIDR(raw)
+	if errInParse != nil {
+		return "", errors.New("invalid CIDR: " + raw)
+	}
+	return ipNet.String(), nil
+}
@@ -0,0 +1,15 @@
+package model
+
+type TeamIPAllowlistResponse struct {
+	*TeamIPAllowlistForExport
+}
+
+func NewTeamIPAllowlistResponse(allowlist *TeamIPAllowlist) *TeamIPAllowlistResponse {
+	return &TeamIPAllowlistResponse{
+		TeamIPAllowlistForExport: allowlist.Export(),
+	}
+}
+
+func (resp *TeamIPAllowlistResponse) ExportForFeedback() interface{} {
+	return resp
+}
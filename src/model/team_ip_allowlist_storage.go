@@ -0,0 +1,41 @@
+package model
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TeamIPAllowlistStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTeamIPAllowlistStorage(db *gorm.DB, logger *zap.SugaredLogger) *TeamIPAllowlistStorage {
+	return &TeamIPAllowlistStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// the team without allowlist record allows all IPs.
+func (d *TeamIPAllowlistStorage) RetrieveByTeamID(teamID int) (*TeamIPAllowlist, error) {
+	allowlist := &TeamIPAllowlist{}
+	err := d.db.Where("team_id = ?", teamID).First(allowlist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NewTeamIPAllowlist(teamID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return allowlist, nil
+}
+
+func (d *TeamIPAllowlistStorage) Upsert(allowlist *TeamIPAllowlist) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"entries", "updated_by", "updated_at"}),
+	}).Omit("id").Create(allowlist).Error
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,16 +20,22 @@ const (
 
 const TEAM_MEMBER_STATUS_OK = 1
 const TEAM_MEMBER_STATUS_PENDING = 2
+const TEAM_MEMBER_STATUS_SUSPENDED = 3
+const TEAM_MEMBER_STATUS_PENDING_APPROVAL = 4 // joined by email domain, waiting for approval
+
+var ErrTeamMemberAlreadyJoined = errors.New("user already joined this team.")
 
 type TeamMember struct {
-	ID         int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:team_members_ukey"`
-	TeamID     int       `json:"team_id" gorm:"column:team_id;type:bigserial;index:team_members_team_and_user_id"`
-	UserID     int       `json:"user_id" gorm:"column:user_id;type:bigserial;index:team_members_team_and_user_id"`
-	UserRole   int       `json:"user_role" gorm:"column:user_role;type:smallint"`
-	Permission string    `json:"permission" gorm:"column:permission;type:jsonb"` // for user permission config
-	Status     int       `json:"status" gorm:"column:status;type:smallint"`
-	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp"`
-	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp"`
+	ID              int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:team_members_ukey"`
+	TeamID          int       `json:"team_id" gorm:"column:team_id;type:bigserial;index:team_members_team_and_user_id"`
+	UserID          int       `json:"user_id" gorm:"column:user_id;type:bigserial;index:team_members_team_and_user_id"`
+	UserRole        int       `json:"user_role" gorm:"column:user_role;type:smallint"`
+	Permission      string    `json:"permission" gorm:"column:permission;type:jsonb"` // for user permission config
+	Status          int       `json:"status" gorm:"column:status;type:smallint"`
+	SuspendedReason string    `json:"suspended_reason" gorm:"column:suspended_reason;type:varchar;size:255"`
+	SuspendedUntil  time.Time `json:"suspended_until" gorm:"column:suspended_until;type:timestamp"` // zero value means suspended until reactivated manually
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;type:timestamp"`
 }
 
 type TeamMemberWithUserInfoForExport struct {
@@ -43,8 +50,11 @@ type TeamMemberWithUserInfoForExport struct {
 	UserRole     int                   `json:"userRole"`
 	Permission   *TeamMemberPermission `json:"permission"` // for user permission config
 	UserStatus   int                   `json:"userStatus"`
+	Suspension   *TeamMemberSuspension `json:"suspension,omitempty"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
+
+	TemporaryGrants []*TemporaryGrantForExport `json:"temporaryGrants"` // the grants active now
 }
 
 type TeamMemberForExport struct {
@@ -58,10 +68,57 @@ type TeamMemberForExport struct {
 	UpdatedAt  time.Time             `json:"updatedAt"`
 }
 
+type TeamMemberSuspension struct {
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspendedUntil"`
+}
+
 func NewTeamMember() *TeamMember {
 	return &TeamMember{}
 }
 
+func NewPendingTeamMember(teamID int, userID int, userRole int) *TeamMember {
+	teamMember := &TeamMember{
+		TeamID:     teamID,
+		UserID:     userID,
+		UserRole:   userRole,
+		Permission: NewTeamMemberPermission().ExportForTeam(),
+		Status:     TEAM_MEMBER_STATUS_PENDING,
+	}
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	return teamMember
+}
+
+func NewTeamMemberByInviteLink(invite *Invite, userID int) *TeamMember {
+	teamMember := &TeamMember{
+		TeamID:     invite.TeamID,
+		UserID:     userID,
+		UserRole:   invite.ExportUserRole(),
+		Permission: NewTeamMemberPermission().ExportForTeam(),
+		Status:     TEAM_MEMBER_STATUS_OK,
+	}
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	return teamMember
+}
+
+func NewTeamMemberByEmailDomain(emailDomain *EmailDomain, userID int) *TeamMember {
+	teamMember := &TeamMember{
+		TeamID:     emailDomain.TeamID,
+		UserID:     userID,
+		UserRole:   emailDomain.ExportUserRole(),
+		Permission: NewTeamMemberPermission().ExportForTeam(),
+		Status:     TEAM_MEMBER_STATUS_OK,
+	}
+	if emailDomain.IsRequireApproval() {
+		teamMember.Status = TEAM_MEMBER_STATUS_PENDING_APPROVAL
+	}
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	return teamMember
+}
+
 func (u *TeamMember) ConstructByJSON(TeamMemberJSON []byte) error {
 	if err := json.Unmarshal(TeamMemberJSON, u); err != nil {
 		return err
@@ -114,6 +171,7 @@ func (u *TeamMember) ExportWithUserInfo(userForExport *UserForExport) *TeamMembe
 		UserRole:     u.UserRole,
 		Permission:   u.ExportPermission(),
 		UserStatus:   u.Status,
+		Suspension:   u.ExportSuspension(),
 		CreatedAt:    u.CreatedAt,
 		UpdatedAt:    u.UpdatedAt,
 	}
@@ -132,11 +190,19 @@ func (u *TeamMember) ExportWithPendingUserInfo(userForExport *UserForExport) *Te
 		UserRole:     u.UserRole,
 		Permission:   u.ExportPermission(),
 		UserStatus:   u.Status,
+		Suspension:   u.ExportSuspension(),
 		CreatedAt:    u.CreatedAt,
 		UpdatedAt:    u.UpdatedAt,
 	}
 }
 
+func (i *TeamMemberWithUserInfoForExport) SetTemporaryGrants(temporaryGrants []*TemporaryGrantForExport) {
+	if temporaryGrants == nil {
+		temporaryGrants = []*TemporaryGrantForExport{}
+	}
+	i.TemporaryGrants = temporaryGrants
+}
+
 func (u *TeamMember) Export() *TeamMemberForExport {
 	return &TeamMemberForExport{
 		ID:         u.ID,
@@ -192,6 +258,11 @@ func (u *TeamMember) IsViewer() bool {
 	return false
 }
 
+func (u *TeamMember) SetPermission(tmp *TeamMemberPermission) {
+	u.Permission = tmp.ExportForTeam()
+	u.InitUpdatedAt()
+}
+
 func (u *TeamMember) ExportPermission() *TeamMemberPermission {
 	tmp := NewTeamMemberPermission()
 	json.Unmarshal([]byte(u.Permission), tmp)
@@ -205,6 +276,60 @@ func (u *TeamMember) IsStatusPending() bool {
 	return false
 }
 
+func (u *TeamMember) IsStatusPendingApproval() bool {
+	if u.Status == TEAM_MEMBER_STATUS_PENDING_APPROVAL {
+		return true
+	}
+	return false
+}
+
+func (u *TeamMember) IsStatusSuspended() bool {
+	if u.Status == TEAM_MEMBER_STATUS_SUSPENDED {
+		return true
+	}
+	return false
+}
+
+// the suspended member will be reactivated automatically when the suspended until time passed.
+func (u *TeamMember) IsSuspensionExpired() bool {
+	if !u.IsStatusSuspended() || u.SuspendedUntil.IsZero() {
+		return false
+	}
+	return time.Now().UTC().After(u.SuspendedUntil)
+}
+
+func (u *TeamMember) Approve() {
+	u.Status = TEAM_MEMBER_STATUS_OK
+	u.InitUpdatedAt()
+}
+
+func (u *TeamMember) Suspend(reason string, suspendedUntil time.Time) {
+	u.Status = TEAM_MEMBER_STATUS_SUSPENDED
+	u.SuspendedReason = reason
+	u.SuspendedUntil = suspendedUntil
+	u.InitUpdatedAt()
+}
+
+func (u *TeamMember) Reactivate() {
+	u.Status = TEAM_MEMBER_STATUS_OK
+	u.SuspendedReason = ""
+	u.SuspendedUntil = time.Time{}
+	u.InitUpdatedAt()
+}
+
+func (u *TeamMember) ExportSuspension() *TeamMemberSuspension {
+	if !u.IsStatusSuspended() {
+		return nil
+	}
+	suspension := &TeamMemberSuspension{
+		Reason: u.SuspendedReason,
+	}
+	if !u.SuspendedUntil.IsZero() {
+		suspension.SuspendedUntil = &u.SuspendedUntil
+	}
+	return suspension
+}
+
 func (u *TeamMember) IsStatusOK() bool {
 	if u.Status == TEAM_MEMBER_STATUS_OK {
 		return true
@@ -243,7 +368,8 @@ func PickUpUserIDsInUserMembers(teamMembers []*TeamMember) []int {
 }
 
 type TeamMemberPermission struct {
-	Config int `json:"config"`
+	Config    int                   `json:"config"`
+	Overrides []*PermissionOverride `json:"overrides"` // per member grants and denies on top of the role
 }
 
 func NewTeamMemberPermission() *TeamMemberPermission {
@@ -254,6 +380,14 @@ func (tmp *TeamMemberPermission) ExportForTeam() string {
 	return string(r)
 }
 
+func (tmp *TeamMemberPermission) ExportOverrides() []*PermissionOverride {
+	return tmp.Overrides
+}
+
+func (tmp *TeamMemberPermission) SetOverrides(overrides []*PermissionOverride) {
+	tmp.Overrides = overrides
+}
+
 func BuildTeamIDLookUpTableForTeamMemberExport(teamMembers []*TeamMember) map[int]*TeamMemberForExport {
 	teamMembersNum := len(teamMembers)
 	lt := make(map[int]*TeamMemberForExport, teamMembersNum)
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const MEMBER_IMPORT_MAX_ROWS = 500
+const MEMBER_IMPORT_MAX_BODY_SIZE = 1 << 20 // 1MB
+
+// the result status of each row in member import.
+const (
+	MEMBER_IMPORT_ROW_STATUS_VALID             = "valid" // only for dry run
+	MEMBER_IMPORT_ROW_STATUS_INVITED           = "invited"
+	MEMBER_IMPORT_ROW_STATUS_INVALID_EMAIL     = "invalidEmail"
+	MEMBER_IMPORT_ROW_STATUS_INVALID_ROLE      = "invalidRole"
+	MEMBER_IMPORT_ROW_STATUS_ACCESS_DENIED     = "accessDenied"
+	MEMBER_IMPORT_ROW_STATUS_DUPLICATED        = "duplicated"
+	MEMBER_IMPORT_ROW_STATUS_ALREADY_JOINED    = "alreadyJoined"
+	MEMBER_IMPORT_ROW_STATUS_ALREADY_INVITED   = "alreadyInvited"
+	MEMBER_IMPORT_ROW_STATUS_CAPACITY_EXCEEDED = "capacityExceeded"
+	MEMBER_IMPORT_ROW_STATUS_FAILED            = "failed"
+)
+
+var UserRoleNameMap = map[string]int{
+	"owner":  USER_ROLE_OWNER,
+	"admin":  USER_ROLE_ADMIN,
+	"editor": USER_ROLE_EDITOR,
+	"viewer": USER_ROLE_VIEWER,
+}
+
+const CSV_FORMULA_TRIGGERS = "=+-@\t\r"
+
+var TeamMemberCSVHeader = []string{"teamMemberID", "userID", "nickname", "email", "userRole", "status", "createdAt"}
+
+type MemberImportRow struct {
+	Row      int    `json:"row"` // start from 1, the header is not counted
+	Email    string `json:"email"`
+	UserRole string `json:"userRole"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	InviteID string `json:"inviteID,omitempty"`
+}
+
+// parse member import csv with "email,userRole" columns, the header line is optional.
+func ParseMemberImportCSV(reader io.Reader) ([]*MemberImportRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	records, errInRead := csvReader.ReadAll()
+	if errInRead != nil {
+		return nil, errInRead
+	}
+	if len(records) > 0 && len(records[0]) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "email") {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no member found in csv.")
+	}
+	if len(records) > MEMBER_IMPORT_MAX_ROWS {
+		return nil, errors.New("too many members in csv, the limit is " + strconv.Itoa(MEMBER_IMPORT_MAX_ROWS) + ".")
+	}
+	rows := make([]*MemberImportRow, 0, len(records))
+	for serial, record := range records {
+		row := &MemberImportRow{Row: serial + 1}
+		if len(record) > 0 {
+			row.Email = strings.ToLower(strings.TrimSpace(record[0]))
+		}
+		if len(record) > 1 {
+			row.UserRole = strings.TrimSpace(record[1])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (row *MemberImportRow) ExportUserRole() (int, bool) {
+	return ParseUserRoleName(row.UserRole)
+}
+
+func (row *MemberImportRow) SetResult(status string, message string) {
+	row.Status = status
+	row.Message = message
+}
+
+func (row *MemberImportRow) SetInvited(invite *Invite) {
+	row.Status = MEMBER_IMPORT_ROW_STATUS_INVITED
+	row.InviteID = idconvertor.ConvertIntToString(invite.ExportID())
+}
+
+// the valid row of dry run and the invited row are counted as succeeded.
+func (row *MemberImportRow) IsSucceeded() bool {
+	if row.Status == MEMBER_IMPORT_ROW_STATUS_VALID || row.Status == MEMBER_IMPORT_ROW_STATUS_INVITED {
+		return true
+	}
+	return false
+}
+
+// the user role accepts both role name and role id.
+func ParseUserRoleName(userRoleName string) (int, bool) {
+	if userRole, hit := UserRoleNameMap[strings.ToLower(userRoleName)]; hit {
+		return userRole, true
+	}
+	userRole, errInConvert := strconv.Atoi(userRoleName)
+	if errInConvert != nil {
+		return 0, false
+	}
+	for _, avaliableUserRole := range UserRoleNameMap {
+		if userRole == avaliableUserRole {
+			return userRole, true
+		}
+	}
+	return 0, false
+}
+
+func ExportUserRoleName(userRole int) string {
+	for userRoleName, avaliableUserRole := range UserRoleNameMap {
+		if userRole == avaliableUserRole {
+			return userRoleName
+		}
+	}
+	return strconv.Itoa(userRole)
+}
+
+func (i *TeamMemberWithUserInfoForExport) ExportForCSV() []string {
+	return []string{
+		idconvertor.ConvertIntToString(i.TeamMemberID),
+		idconvertor.ConvertIntToString(i.ID),
+		EscapeCSVCell(i.Nickname),
+		EscapeCSVCell(i.Email),
+		ExportUserRoleName(i.UserRole),
+		ExportTeamMemberStatusName(i.UserStatus),
+		i.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// the cell starts with formula trigger will be prefixed with quote, so spreadsheet will not evaluate it.
+func EscapeCSVCell(cell string) string {
+	if len(cell) > 0 && strings.ContainsRune(CSV_FORMULA_TRIGGERS, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func ExportTeamMemberStatusName(status int) string {
+	switch status {
+	case TEAM_MEMBER_STATUS_OK:
+		return "active"
+	case TEAM_MEMBER_STATUS_PENDING:
+		return "pending"
+	case TEAM_MEMBER_STATUS_SUSPENDED:
+		return "suspended"
+	case TEAM_MEMBER_STATUS_PENDING_APPROVAL:
+		return "pendingApproval"
+	}
+	return strconv.Itoa(status)
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"errors"
+	"time"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -24,6 +27,17 @@ func (d *TeamMemberStorage) Create(u *TeamMember) (int, error) {
 	return u.ID, nil
 }
 
+// create the team member who joins the team without invite, the team capacity is checked in the same transaction.
+func (d *TeamMemberStorage) CreateWithinCapacity(u *TeamMember) (int, error) {
+	errInTakeSeats := takeSeats(d.db, u.TeamID, func(tx *gorm.DB) error {
+		return tx.Create(u).Error
+	})
+	if errInTakeSeats != nil {
+		return 0, errInTakeSeats
+	}
+	return u.ID, nil
+}
+
 func (d *TeamMemberStorage) RetrieveByID(id int) (*TeamMember, error) {
 	u := &TeamMember{}
 	if err := d.db.First(u, id).Error; err != nil {
@@ -56,6 +70,32 @@ func (d *TeamMemberStorage) RetrieveByTeamIDAndID(team_id int, id int) (*TeamMem
 	return teamMember, nil
 }
 
+func (d *TeamMemberStorage) RetrieveByTeamIDAndIDs(teamID int, ids []int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("team_id = ? AND id IN ?", teamID, ids).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+func (d *TeamMemberStorage) RetrieveByTeamIDAndUserIDs(teamID int, userIDs []int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("team_id = ? AND user_id IN ?", teamID, userIDs).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+// retrieve the team members which belongs to the user group.
+func (d *TeamMemberStorage) RetrieveByTeamIDAndUserGroupID(teamID int, userGroupID int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	subQuery := d.db.Model(&UserGroupMember{}).Select("team_member_id").Where("team_id = ? AND user_group_id = ?", teamID, userGroupID)
+	if err := d.db.Where("team_id = ? AND id IN (?)", teamID, subQuery).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
 func (d *TeamMemberStorage) RetrieveByUserID(user_id int) ([]*TeamMember, error) {
 	var teamMembers []*TeamMember
 	if err := d.db.Where("user_id = ?", user_id).Find(&teamMembers).Error; err != nil {
@@ -64,6 +104,14 @@ func (d *TeamMemberStorage) RetrieveByUserID(user_id int) ([]*TeamMember, error)
 	return teamMembers, nil
 }
 
+func (d *TeamMemberStorage) RetrieveByUserIDAndStatus(userID int, status int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("user_id = ? AND status = ?", userID, status).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
 func (d *TeamMemberStorage) RetrieveByTeamIDAndUserID(teamID int, userID int) (*TeamMember, error) {
 	var teamMember *TeamMember
 	if err := d.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&teamMember).Error; err != nil {
@@ -92,6 +140,15 @@ func (d *TeamMemberStorage) DoesTeamIncludedTargetUser(teamID int, userID int) (
 	return true, nil
 }
 
+// count team members include pending ones, the pending team member takes seat once invited.
+func (d *TeamMemberStorage) CountByTeamID(teamID int) (int, error) {
+	var count int64
+	if err := d.db.Model(&TeamMember{}).Where("team_id = ?", teamID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
 func (d *TeamMemberStorage) IsNowUserIsTeamOwner(userID int) (bool, error) {
 	var teamMember *TeamMember
 	var count int64
@@ -111,6 +168,35 @@ func (d *TeamMemberStorage) Update(u *TeamMember) error {
 	return nil
 }
 
+// update the user role of team member, the editor seat will be taken when promoted from viewer.
+func (d *TeamMemberStorage) UpdateUserRole(u *TeamMember) error {
+	return takeSeats(d.db, u.TeamID, func(tx *gorm.DB) error {
+		return tx.Model(&TeamMember{}).Where("id = ?", u.ID).UpdateColumns(map[string]interface{}{"user_role": u.UserRole, "updated_at": u.UpdatedAt}).Error
+	})
+}
+
+// transfer team owner role, the former owner will be demoted to admin.
+func (d *TeamMemberStorage) TransferOwner(teamID int, fromTeamMemberID int, toTeamMemberID int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		demote := tx.Model(&TeamMember{}).Where("team_id = ? AND id = ? AND user_role = ?", teamID, fromTeamMemberID, USER_ROLE_OWNER).UpdateColumns(map[string]interface{}{"user_role": USER_ROLE_ADMIN, "updated_at": now})
+		if demote.Error != nil {
+			return demote.Error
+		}
+		if demote.RowsAffected != 1 {
+			return errors.New("former owner not found in team.")
+		}
+		promote := tx.Model(&TeamMember{}).Where("team_id = ? AND id = ? AND status = ?", teamID, toTeamMemberID, TEAM_MEMBER_STATUS_OK).UpdateColumns(map[string]interface{}{"user_role": USER_ROLE_OWNER, "updated_at": now})
+		if promote.Error != nil {
+			return promote.Error
+		}
+		if promote.RowsAffected != 1 {
+			return errors.New("target team member not found in team.")
+		}
+		return nil
+	})
+}
+
 func (d *TeamMemberStorage) DeleteByID(id int) error {
 	if err := d.db.Delete(&TeamMember{}, id).Error; err != nil {
 		return err
@@ -146,9 +232,49 @@ func (d *TeamMemberStorage) DeleteByTeamIDAndUserID(teamID int, userID int) erro
 	return nil
 }
 
+func (d *TeamMemberStorage) UpdateStatus(u *TeamMember) error {
+	if err := d.db.Model(&TeamMember{}).Where("id = ? AND team_id = ?", u.ID, u.TeamID).UpdateColumns(map[string]interface{}{"status": u.Status, "updated_at": u.UpdatedAt}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// update suspension columns only, the zero value columns will be reset.
+func (d *TeamMemberStorage) UpdateSuspension(u *TeamMember) error {
+	var suspendedUntil interface{}
+	if !u.SuspendedUntil.IsZero() {
+		suspendedUntil = u.SuspendedUntil
+	}
+	if err := d.db.Model(&TeamMember{}).Where("id = ? AND team_id = ?", u.ID, u.TeamID).UpdateColumns(map[string]interface{}{"status": u.Status, "suspended_reason": u.SuspendedReason, "suspended_until": suspendedUntil, "updated_at": u.UpdatedAt}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func (d *TeamMemberStorage) DeleteByIDAndTeamID(id int, teamID int) error {
 	if err := d.db.Where("id = ? AND team_id = ?", id, teamID).Delete(&TeamMember{}).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+// remove the team member with its pending invites, user group memberships, custom roles, temporary grants and unit ACL entries in one transaction.
+// the pending team member has no user yet, so the records keyed by user id are left untouched.
+func (d *TeamMemberStorage) Remove(teamMember *TeamMember) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND (team_member_id = ? OR target_team_member_id = ?) AND status = ?", teamMember.TeamID, teamMember.ID, teamMember.ID, INVITE_STATUS_PENDING).Delete(&Invite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ? AND team_member_id = ?", teamMember.TeamID, teamMember.ID).Delete(&UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		if teamMember.UserID != PENDING_USER_ID {
+			for _, unit := range []interface{}{&UserRoleRelation{}, &TemporaryGrant{}, &UnitRoleRelation{}} {
+				if err := tx.Where("team_id = ? AND user_id = ?", teamMember.TeamID, teamMember.UserID).Delete(unit).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Where("id = ? AND team_id = ?", teamMember.ID, teamMember.TeamID).Delete(&TeamMember{}).Error
+	})
+}
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// 0 means no limit for session age and idle timeout.
+const SECURITY_POLICY_UNLIMITED = 0
+
+const SECURITY_POLICY_VIOLATION_ERROR_FLAG = "ERROR_FLAG_SECURITY_POLICY_VIOLATED"
+
+const (
+	SECURITY_POLICY_TWO_FACTOR       = "requireTwoFactor"
+	SECURITY_POLICY_MAX_SESSION_AGE  = "maxSessionAge"
+	SECURITY_POLICY_IDLE_TIMEOUT     = "idleTimeout"
+	SECURITY_POLICY_SSO_ONLY         = "ssoOnly"
+	SECURITY_POLICY_ALLOWED_METHODS  = "allowedAuthMethods"
+	SECURITY_REMEDIATION_TWO_FACTOR  = "enableTwoFactor"
+	SECURITY_REMEDIATION_SSO         = "signInWithSSO"
+	SECURITY_REMEDIATION_AUTH_METHOD = "signInWithAllowedAuthMethod"
+	SECURITY_REMEDIATION_REAUTH      = "reauthenticate"
+)
+
+type TeamSecurityPolicy struct {
+	ID                 int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID             int       `json:"teamID" gorm:"column:team_id;type:bigserial;uniqueIndex:team_security_policies_team_id"`
+	RequireTwoFactor   bool      `json:"requireTwoFactor" gorm:"column:require_two_factor;type:boolean"`
+	MaxSessionAge      int       `json:"maxSessionAge" gorm:"column:max_session_age;type:integer"` // in seconds
+	IdleTimeout        int       `json:"idleTimeout" gorm:"column:idle_timeout;type:integer"`      // in seconds
+	SSOOnly            bool      `json:"ssoOnly" gorm:"column:sso_only;type:boolean"`
+	AllowedAuthMethods string    `json:"allowedAuthMethods" gorm:"column:allowed_auth_methods;type:jsonb"` // empty means all auth methods are allowed
+	UpdatedBy          int       `json:"updatedBy" gorm:"column:updated_by;type:bigint"`
+	CreatedAt          time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type TeamSecurityPolicyForExport struct {
+	TeamID             string    `json:"teamID"`
+	RequireTwoFactor   bool      `json:"requireTwoFactor"`
+	MaxSessionAge      int       `json:"maxSessionAge"`
+	IdleTimeout        int       `json:"idleTimeout"`
+	SSOOnly            bool      `json:"ssoOnly"`
+	AllowedAuthMethods []string  `json:"allowedAuthMethods"`
+	UpdatedBy          string    `json:"updatedBy"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// the violation tells frontend which remediation flow should be started.
+type SecurityPolicyViolation struct {
+	Policy       string `json:"policy"`
+	Remediation  string `json:"remediation"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func NewTeamSecurityPolicy(teamID int) *TeamSecurityPolicy {
+	policy := &TeamSecurityPolicy{
+		TeamID:        teamID,
+		MaxSessionAge: SECURITY_POLICY_UNLIMITED,
+		IdleTimeout:   SECURITY_POLICY_UNLIMITED,
+	}
+	policy.SetAllowedAuthMethods([]string{})
+	policy.InitCreatedAt()
+	policy.InitUpdatedAt()
+	return policy
+}
+
+func (p *TeamSecurityPolicy) InitCreatedAt() {
+	p.CreatedAt = time.Now().UTC()
+}
+
+func (p *TeamSecurityPolicy) InitUpdatedAt() {
+	p.UpdatedAt = time.Now().UTC()
+}
+
+func (p *TeamSecurityPolicy) UpdateByRequest(req *UpdateTeamSecurityPolicyRequest, userID int) {
+	p.RequireTwoFactor = req.RequireTwoFactor
+	p.MaxSessionAge = req.MaxSessionAge
+	p.IdleTimeout = req.IdleTimeout
+	p.SSOOnly = req.SSOOnly
+	p.SetAllowedAuthMethods(req.AllowedAuthMethods)
+	p.UpdatedBy = userID
+	p.InitUpdatedAt()
+}
+
+func (p *TeamSecurityPolicy) SetAllowedAuthMethods(authMethods []string) {
+	if authMethods == nil {
+		authMethods = []string{}
+	}
+	r, _ := json.Marshal(authMethods)
+	p.AllowedAuthMethods = string(r)
+}
+
+func (p *TeamSecurityPolicy) ExportAllowedAuthMethods() []string {
+	authMethods := make([]string, 0)
+	json.Unmarshal([]byte(p.AllowedAuthMethods), &authMethods)
+	return authMethods
+}
+
+func (p *TeamSecurityPolicy) ExportMaxSessionAge() time.Duration {
+	return time.Duration(p.MaxSessionAge) * time.Second
+}
+
+func (p *TeamSecurityPolicy) ExportIdleTimeout() time.Duration {
+	return time.Duration(p.IdleTimeout) * time.Second
+}
+
+func (p *TeamSecurityPolicy) IsEnabled() bool {
+	return p.RequireTwoFactor || p.SSOOnly || p.MaxSessionAge != SECURITY_POLICY_UNLIMITED || p.IdleTimeout != SECURITY_POLICY_UNLIMITED || len(p.ExportAllowedAuthMethods()) > 0
+}
+
+func (p *TeamSecurityPolicy) HasIdleTimeout() bool {
+	return p.IdleTimeout != SECURITY_POLICY_UNLIMITED
+}
+
+func (p *TeamSecurityPolicy) DoesAuthMethodAllowed(authMethod string) bool {
+	if p.SSOOnly {
+		return authMethod == AUTH_METHOD_SSO
+	}
+	allowedAuthMethods := p.ExportAllowedAuthMethods()
+	if len(allowedAuthMethods) == 0 {
+		return true
+	}
+	for _, allowedAuthMethod := range allowedAuthMethods {
+		if allowedAuthMethod == authMethod {
+			return true
+		}
+	}
+	return false
+}
+
+// check the session against the policy, nil means the session is compliant.
+// the auth method is checked first, since signing in again with the right method resolves the other violations too.
+func (p *TeamSecurityPolicy) Check(session *AccessSession, now time.Time) *SecurityPolicyViolation {
+	if !p.DoesAuthMethodAllowed(session.AuthMethod) {
+		if p.SSOOnly {
+			return NewSecurityPolicyViolation(SECURITY_POLICY_SSO_ONLY, SECURITY_REMEDIATION_SSO, "this team only allows signing in with SSO.")
+		}
+		return NewSecurityPolicyViolation(SECURITY_POLICY_ALLOWED_METHODS, SECURITY_REMEDIATION_AUTH_METHOD, "the auth method "+session.AuthMethod+" is not allowed in this team.")
+	}
+	if p.RequireTwoFactor && !session.TwoFactor {
+		return NewSecurityPolicyViolation(SECURITY_POLICY_TWO_FACTOR, SECURITY_REMEDIATION_TWO_FACTOR, "this team requires two-factor authentication.")
+	}
+	if p.MaxSessionAge != SECURITY_POLICY_UNLIMITED && now.Sub(session.IssuedAt) > p.ExportMaxSessionAge() {
+		return NewSecurityPolicyViolation(SECURITY_POLICY_MAX_SESSION_AGE, SECURITY_REMEDIATION_REAUTH, "the session has exceeded the max session age of this team.")
+	}
+	if p.HasIdleTimeout() && !session.LastActiveAt.IsZero() && now.Sub(session.LastActiveAt) > p.ExportIdleTimeout() {
+		return NewSecurityPolicyViolation(SECURITY_POLICY_IDLE_TIMEOUT, SECURITY_REMEDIATION_REAUTH, "the session has been idle for too long.")
+	}
+	return nil
+}
+
+func (p *TeamSecurityPolicy) Export() *TeamSecurityPolicyForExport {
+	return &TeamSecurityPolicyForExport{
+		TeamID:             idconvertor.ConvertIntToString(p.TeamID),
+		RequireTwoFactor:   p.RequireTwoFactor,
+		MaxSessionAge:      p.MaxSessionAge,
+		IdleTimeout:        p.IdleTimeout,
+		SSOOnly:            p.SSOOnly,
+		AllowedAuthMethods: p.ExportAllowedAuthMethods(),
+		UpdatedBy:          idconvertor.ConvertIntToString(p.UpdatedBy),
+		UpdatedAt:          p.UpdatedAt,
+	}
+}
+
+func NewSecurityPolicyViolation(policy string, remediation string, errorMessage string) *SecurityPolicyViolation {
+	return &SecurityPolicyViolation{
+		Policy:       policy,
+		Remediation:  remediation,
+		ErrorMessage: errorMessage,
+	}
+}
+
+func (v *SecurityPolicyViolation) ExportForFeedback() interface{} {
+	return map[string]interface{}{
+		"errorCode":    403,
+		"errorFlag":    SECURITY_POLICY_VIOLATION_ERROR_FLAG,
+		"errorMessage": v.ErrorMessage,
+		"policy":       v.Policy,
+		"remediation":  v.Remediation,
+	}
+}
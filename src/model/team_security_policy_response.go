@@ -0,0 +1,15 @@
+package model
+
+type TeamSecurityPolicyResponse struct {
+	*TeamSecurityPolicyForExport
+}
+
+func NewTeamSecurityPolicyResponse(policy *TeamSecurityPolicy) *TeamSecurityPolicyResponse {
+	return &TeamSecurityPolicyResponse{
+		TeamSecurityPolicyForExport: policy.Export(),
+	}
+}
+
+func (resp *TeamSecurityPolicyResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TeamSecurityPolicyStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTeamSecurityPolicyStorage(db *gorm.DB, logger *zap.SugaredLogger) *TeamSecurityPolicyStorage {
+	return &TeamSecurityPolicyStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// the team without security policy record has no restriction.
+func (d *TeamSecurityPolicyStorage) RetrieveByTeamID(teamID int) (*TeamSecurityPolicy, error) {
+	policy := &TeamSecurityPolicy{}
+	err := d.db.Where("team_id = ?", teamID).First(policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NewTeamSecurityPolicy(teamID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (d *TeamSecurityPolicyStorage) Upsert(policy *TeamSecurityPolicy) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"require_two_factor", "max_session_age", "idle_timeout", "sso_only", "allowed_auth_methods", "updated_by", "updated_at"}),
+	}).Omit("id").Create(policy).Error
+}
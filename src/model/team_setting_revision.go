@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const (
+	TEAM_SETTING_REVISION_CATEGORY_CONFIG     = 1
+	TEAM_SETTING_REVISION_CATEGORY_PERMISSION = 2
+	TEAM_SETTING_REVISION_CATEGORY_ROLLBACK   = 3
+	TEAM_SETTING_REVISION_CATEGORY_BASELINE   = 4 // the settings before the first change, so the first change can be rolled back
+)
+
+const TEAM_SETTING_REVISION_CREATED_BY_SYSTEM = 0
+
+const (
+	TEAM_SETTING_REVISION_PAGE_DEFAULT_LIMIT = 20
+	TEAM_SETTING_REVISION_PAGE_MAX_LIMIT     = 100
+)
+
+// the team settings snapshot, includes team config and team permission.
+type TeamSettings struct {
+	Name       string          `json:"name"`
+	Identifier string          `json:"identifier"`
+	Icon       string          `json:"icon"`
+	Permission *TeamPermission `json:"permission"`
+}
+
+type TeamSettingChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// every change of team settings is stored as a revision, the version increases in team.
+type TeamSettingRevision struct {
+	ID           int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID       int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:team_setting_revisions_team_id_and_version"`
+	Version      int       `json:"version" gorm:"column:version;type:integer;index:team_setting_revisions_team_id_and_version"`
+	Category     int       `json:"category" gorm:"column:category;type:smallint"`
+	Settings     string    `json:"settings" gorm:"column:settings;type:jsonb"`
+	Diff         string    `json:"diff" gorm:"column:diff;type:jsonb"`
+	RollbackFrom int       `json:"rollbackFrom" gorm:"column:rollback_from;type:integer"` // the version rolled back to, 0 means not a rollback
+	CreatedBy    int       `json:"createdBy" gorm:"column:created_by;type:bigserial"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp"`
+	before       *TeamSettings
+}
+
+type TeamSettingRevisionForExport struct {
+	Version      int                  `json:"version"`
+	Category     int                  `json:"category"`
+	Settings     *TeamSettings        `json:"settings"`
+	Diff         []*TeamSettingChange `json:"diff"`
+	RollbackFrom int                  `json:"rollbackFrom"`
+	CreatedBy    string               `json:"createdBy"`
+	CreatedAt    time.Time            `json:"createdAt"`
+}
+
+func NewTeamSettingsByTeam(team *Team) *TeamSettings {
+	return &TeamSettings{
+		Name:       team.Name,
+		Identifier: team.Identifier,
+		Icon:       team.Icon,
+		Permission: team.ExportTeamPermission(),
+	}
+}
+
+// build revision by the team settings before and after change, the version is assigned by storage.
+func NewTeamSettingRevision(category int, before *TeamSettings, after *TeamSettings, userID int) *TeamSettingRevision {
+	settings, _ := json.Marshal(after)
+	diff, _ := json.Marshal(DiffTeamSettings(before, after))
+	return &TeamSettingRevision{
+		Category:  category,
+		Settings:  string(settings),
+		Diff:      string(diff),
+		CreatedBy: userID,
+		CreatedAt: time.Now().UTC(),
+		before:    before,
+	}
+}
+
+// export the baseline revision with the settings before this revision, it has no diff and is created by system.
+func (r *TeamSettingRevision) ExportBaseline() *TeamSettingRevision {
+	settings, _ := json.Marshal(r.before)
+	return &TeamSettingRevision{
+		Category:  TEAM_SETTING_REVISION_CATEGORY_BASELINE,
+		Settings:  string(settings),
+		Diff:      "[]",
+		CreatedBy: TEAM_SETTING_REVISION_CREATED_BY_SYSTEM,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func (s *TeamSettings) ExportFields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	if s.Permission != nil {
+		rawPermission, _ := json.Marshal(s.Permission)
+		json.Unmarshal(rawPermission, &fields)
+	}
+	fields[TEAM_FIELD_NAME] = s.Name
+	fields[TEAM_FIELD_IDENTIFIER] = s.Identifier
+	fields[TEAM_FIELD_ICON] = s.Icon
+	return fields
+}
+
+// apply the snapshot to team, the team updated_at will be refreshed.
+func (s *TeamSettings) ApplyToTeam(team *Team) {
+	team.Name = s.Name
+	team.Identifier = s.Identifier
+	team.Icon = s.Icon
+	if s.Permission != nil {
+		team.Permission = s.Permission.ExportForTeam()
+	}
+	team.InitUpdatedAt()
+}
+
+// the changes are sorted by field name.
+func DiffTeamSettings(before *TeamSettings, after *TeamSettings) []*TeamSettingChange {
+	beforeFields := before.ExportFields()
+	afterFields := after.ExportFields()
+	changes := make([]*TeamSettingChange, 0)
+	for field, to := range afterFields {
+		from := beforeFields[field]
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		changes = append(changes, &TeamSettingChange{Field: field, From: from, To: to})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func (r *TeamSettingRevision) HasChanges() bool {
+	return len(r.ExportDiff()) > 0
+}
+
+func (r *TeamSettingRevision) SetRollbackFrom(version int) {
+	r.RollbackFrom = version
+}
+
+func (r *TeamSettingRevision) ExportSettings() *TeamSettings {
+	settings := &TeamSettings{}
+	json.Unmarshal([]byte(r.Settings), settings)
+	return settings
+}
+
+func (r *TeamSettingRevision) ExportDiff() []*TeamSettingChange {
+	diff := make([]*TeamSettingChange, 0)
+	json.Unmarshal([]byte(r.Diff), &diff)
+	return diff
+}
+
+func (r *TeamSettingRevision) Export() *TeamSettingRevisionForExport {
+	return &TeamSettingRevisionForExport{
+		Version:      r.Version,
+		Category:     r.Category,
+		Settings:     r.ExportSettings(),
+		Diff:         r.ExportDiff(),
+		RollbackFrom: r.RollbackFrom,
+		CreatedBy:    idconvertor.ConvertIntToString(r.CreatedBy),
+		CreatedAt:    r.CreatedAt,
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+type TeamSettingRevisionResponse struct {
+	*TeamSettingRevisionForExport
+}
+
+func NewTeamSettingRevisionResponse(revision *TeamSettingRevision) *TeamSettingRevisionResponse {
+	return &TeamSettingRevisionResponse{
+		TeamSettingRevisionForExport: revision.Export(),
+	}
+}
+
+func (resp *TeamSettingRevisionResponse) ExportForFeedback() interface{} {
+	return resp
+}
+
+type GetAllTeamSettingRevisionsResponse struct {
+	Revisions         []*TeamSettingRevisionForExport `json:"revisions"`
+	NextBeforeVersion int                             `json:"nextBeforeVersion"` // 0 means no more revisions
+}
+
+// the revisions should be retrieved with one more than limit, the extra one tells there are more revisions.
+func NewGetAllTeamSettingRevisionsResponse(revisions []*TeamSettingRevision, limit int) *GetAllTeamSettingRevisionsResponse {
+	resp := &GetAllTeamSettingRevisionsResponse{
+		Revisions: make([]*TeamSettingRevisionForExport, 0, len(revisions)),
+	}
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+		resp.NextBeforeVersion = revisions[limit-1].Version
+	}
+	for _, revision := range revisions {
+		resp.Revisions = append(resp.Revisions, revision.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllTeamSettingRevisionsResponse) ExportForFeedback() interface{} {
+	return resp
+}
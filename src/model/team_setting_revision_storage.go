@@ -0,0 +1,39 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TeamSettingRevisionStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTeamSettingRevisionStorage(db *gorm.DB, logger *zap.SugaredLogger) *TeamSettingRevisionStorage {
+	return &TeamSettingRevisionStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// the latest revision comes first, the revisions before the version are returned when the before version is not 0.
+func (d *TeamSettingRevisionStorage) RetrieveByTeamIDBeforeVersion(teamID int, beforeVersion int, limit int) ([]*TeamSettingRevision, error) {
+	var revisions []*TeamSettingRevision
+	query := d.db.Where("team_id = ?", teamID)
+	if beforeVersion != 0 {
+		query = query.Where("version < ?", beforeVersion)
+	}
+	if err := query.Order("version desc").Limit(limit).Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (d *TeamSettingRevisionStorage) RetrieveByTeamIDAndVersion(teamID int, version int) (*TeamSettingRevision, error) {
+	u := &TeamSettingRevision{}
+	if err := d.db.Where("team_id = ? AND version = ?", teamID, version).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
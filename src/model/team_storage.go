@@ -1,8 +1,11 @@
 package model
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TeamStorage struct {
@@ -56,6 +59,14 @@ func (d *TeamStorage) RetrieveByIdentifier(identifier string) (*Team, error) {
 	return u, nil
 }
 
+func (d *TeamStorage) IsIdentifierUsedByOtherTeam(teamID int, identifier string) (bool, error) {
+	var count int64
+	if err := d.db.Model(&Team{}).Where("identifier = ? AND id <> ?", identifier, teamID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (d *TeamStorage) IsIdentifierExists(identifier string) bool {
 	var count int64
 	d.db.Where("identifier = ?", identifier).Count(&count)
@@ -72,6 +83,68 @@ func (d *TeamStorage) UpdateByID(u *Team) error {
 	return nil
 }
 
+// update team settings and append the revision in one transaction, the team row is locked to assign the next version.
+func (d *TeamStorage) UpdateSettings(u *Team, revision *TeamSettingRevision) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&Team{}, u.ID).Error; err != nil {
+			return err
+		}
+		var latestVersion int
+		if err := tx.Model(&TeamSettingRevision{}).Where("team_id = ?", u.ID).Select("COALESCE(MAX(version), 0)").Scan(&latestVersion).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Team{}).Where("id = ?", u.ID).UpdateColumns(map[string]interface{}{"name": u.Name, "identifier": u.Identifier, "icon": u.Icon, "permission": u.Permission, "updated_at": u.UpdatedAt}).Error; err != nil {
+			return err
+		}
+		// the team has no revision before the first change, store the settings before it as baseline.
+		if latestVersion == 0 {
+			baseline := revision.ExportBaseline()
+			baseline.TeamID = u.ID
+			baseline.Version = 1
+			if err := tx.Create(baseline).Error; err != nil {
+				return err
+			}
+			latestVersion = baseline.Version
+		}
+		revision.TeamID = u.ID
+		revision.Version = latestVersion + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// update archive columns only, the restored team will reset them to null.
+func (d *TeamStorage) UpdateArchive(u *Team) error {
+	var archivedAt interface{}
+	if u.IsArchived() {
+		archivedAt = u.ArchivedAt
+	}
+	if err := d.db.Model(&Team{}).Where("id = ?", u.ID).UpdateColumns(map[string]interface{}{"archived_at": archivedAt, "archived_by": u.ArchivedBy, "updated_at": u.UpdatedAt}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// the team created with zero archived at is stored as "0001-01-01", so it should be excluded too.
+func (d *TeamStorage) RetrieveArchivedBefore(deadline time.Time) ([]*Team, error) {
+	teams := []*Team{}
+	if err := d.db.Where("archived_at IS NOT NULL AND archived_at > ? AND archived_at < ?", time.Time{}, deadline).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// purge the team and all its data in one transaction.
+func (d *TeamStorage) PurgeByID(id int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		for _, unit := range []interface{}{&UserGroupMember{}, &UserGroup{}, &UserRoleRelation{}, &UnitRoleRelation{}, &UnitRelation{}, &TemporaryGrant{}, &Role{}, &Invite{}, &TeamMember{}, &Capacity{}, &EmailDomain{}, &Domain{}, &TeamSettingRevision{}, &TeamSecurityPolicy{}, &TeamIPAllowlist{}} {
+			if err := tx.Where("team_id = ?", id).Delete(unit).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&Team{}, id).Error
+	})
+}
+
 func (d *TeamStorage) DeleteByID(id int) error {
 	if err := d.db.Delete(&Team{}, id).Error; err != nil {
 		return err
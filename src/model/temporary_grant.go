@@ -0,0 +1,135 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+const TEMPORARY_GRANT_MAX_DURATION = 30 * 24 * time.Hour
+
+// the temporary grant elevates a team member with a custom role or attributes in [StartAt, EndAt).
+// it expires by itself, the expired grants are kept for audit.
+type TemporaryGrant struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:temporary_grants_team_user_end_at"`
+	UserID      int       `json:"userID" gorm:"column:user_id;type:bigserial;index:temporary_grants_team_user_end_at"`
+	RoleID      int       `json:"roleID" gorm:"column:role_id;type:bigint"` // 0 means no custom role granted
+	Permissions string    `json:"permissions" gorm:"column:permissions;type:jsonb"`
+	Reason      string    `json:"reason" gorm:"column:reason;type:varchar;size:255"`
+	StartAt     time.Time `json:"startAt" gorm:"column:start_at;type:timestamp"`
+	EndAt       time.Time `json:"endAt" gorm:"column:end_at;type:timestamp;index:temporary_grants_team_user_end_at"`
+	CreatedBy   int       `json:"createdBy" gorm:"column:created_by;type:bigint"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at;type:timestamp"`
+}
+
+type TemporaryGrantForExport struct {
+	ID          string            `json:"temporaryGrantID"`
+	TeamID      string            `json:"teamID"`
+	UserID      string            `json:"userID"`
+	RoleID      string            `json:"roleID"`
+	Permissions []*RolePermission `json:"permissions"`
+	Reason      string            `json:"reason"`
+	StartAt     time.Time         `json:"startAt"`
+	EndAt       time.Time         `json:"endAt"`
+	Active      bool              `json:"active"`
+	CreatedBy   string            `json:"createdBy"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+func NewTemporaryGrantByRequest(teamID int, userID int, operatorID int, req *TemporaryGrantRequest, now time.Time) *TemporaryGrant {
+	permissions, _ := json.Marshal(req.ExportPermissions())
+	return &TemporaryGrant{
+		UID:         uuid.New(),
+		TeamID:      teamID,
+		UserID:      userID,
+		RoleID:      req.ExportRoleIDInInt(),
+		Permissions: string(permissions),
+		Reason:      req.Reason,
+		StartAt:     req.ExportStartAt(now),
+		EndAt:       req.ExportEndAt(),
+		CreatedBy:   operatorID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func (g *TemporaryGrant) ExportID() int {
+	return g.ID
+}
+
+func (g *TemporaryGrant) DoesGrantRole() bool {
+	return g.RoleID != 0
+}
+
+func (g *TemporaryGrant) IsActiveAt(now time.Time) bool {
+	return !now.Before(g.StartAt) && now.Before(g.EndAt)
+}
+
+func (g *TemporaryGrant) ExportPermissions() []*RolePermission {
+	permissions := make([]*RolePermission, 0)
+	json.Unmarshal([]byte(g.Permissions), &permissions)
+	return permissions
+}
+
+// the granted attributes are evaluated as allow overrides on all units, same as custom role.
+func (g *TemporaryGrant) ExportPermissionOverrides() []*PermissionOverride {
+	permissions := g.ExportPermissions()
+	overrides := make([]*PermissionOverride, 0, len(permissions))
+	for _, permission := range permissions {
+		overrides = append(overrides, permission.ExportPermissionOverride())
+	}
+	return overrides
+}
+
+func (g *TemporaryGrant) Export(now time.Time) *TemporaryGrantForExport {
+	ret := &TemporaryGrantForExport{
+		ID:          idconvertor.ConvertIntToString(g.ID),
+		TeamID:      idconvertor.ConvertIntToString(g.TeamID),
+		UserID:      idconvertor.ConvertIntToString(g.UserID),
+		Permissions: g.ExportPermissions(),
+		Reason:      g.Reason,
+		StartAt:     g.StartAt,
+		EndAt:       g.EndAt,
+		Active:      g.IsActiveAt(now),
+		CreatedBy:   idconvertor.ConvertIntToString(g.CreatedBy),
+		CreatedAt:   g.CreatedAt,
+	}
+	if g.DoesGrantRole() {
+		ret.RoleID = idconvertor.ConvertIntToString(g.RoleID)
+	}
+	return ret
+}
+
+func ExportTemporaryGrants(temporaryGrants []*TemporaryGrant, now time.Time) []*TemporaryGrantForExport {
+	ret := make([]*TemporaryGrantForExport, 0, len(temporaryGrants))
+	for _, temporaryGrant := range temporaryGrants {
+		ret = append(ret, temporaryGrant.Export(now))
+	}
+	return ret
+}
+
+func PickUpRoleIDsInTemporaryGrants(temporaryGrants []*TemporaryGrant) []int {
+	ids := make([]int, 0, len(temporaryGrants))
+	for _, temporaryGrant := range temporaryGrants {
+		if temporaryGrant.DoesGrantRole() {
+			ids = append(ids, temporaryGrant.RoleID)
+		}
+	}
+	return ids
+}
+
+// build lookup table of user id to the grants active now.
+func BuildUserIDLookUpTableForActiveTemporaryGrants(temporaryGrants []*TemporaryGrant, now time.Time) map[int][]*TemporaryGrantForExport {
+	lt := make(map[int][]*TemporaryGrantForExport)
+	for _, temporaryGrant := range temporaryGrants {
+		if temporaryGrant.IsActiveAt(now) {
+			lt[temporaryGrant.UserID] = append(lt[temporaryGrant.UserID], temporaryGrant.Export(now))
+		}
+	}
+	return lt
+}
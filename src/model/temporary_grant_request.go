@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// grant a custom role, attributes or both, the zero start time means now.
+type TemporaryGrantRequest struct {
+	RoleID      string            `json:"roleID"`
+	Permissions []*RolePermission `json:"permissions" validate:"max=100,dive,required"`
+	Reason      string            `json:"reason" validate:"required,max=255"`
+	StartAt     time.Time         `json:"startAt"`
+	EndAt       time.Time         `json:"endAt" validate:"required"`
+}
+
+func NewTemporaryGrantRequest() *TemporaryGrantRequest {
+	return &TemporaryGrantRequest{}
+}
+
+func (req *TemporaryGrantRequest) ExportRoleIDInInt() int {
+	if req.RoleID == "" {
+		return 0
+	}
+	return idconvertor.ConvertStringToInt(req.RoleID)
+}
+
+func (req *TemporaryGrantRequest) ExportPermissions() []*RolePermission {
+	if req.Permissions == nil {
+		return []*RolePermission{}
+	}
+	return req.Permissions
+}
+
+func (req *TemporaryGrantRequest) ExportStartAt(now time.Time) time.Time {
+	if req.StartAt.IsZero() {
+		return now
+	}
+	return req.StartAt.UTC()
+}
+
+func (req *TemporaryGrantRequest) ExportEndAt() time.Time {
+	return req.EndAt.UTC()
+}
+
+func (req *TemporaryGrantRequest) ValidateWindow(now time.Time) error {
+	if req.ExportRoleIDInInt() == 0 && len(req.ExportPermissions()) == 0 {
+		return errors.New("the role or permissions is required.")
+	}
+	startAt := req.ExportStartAt(now)
+	endAt := req.ExportEndAt()
+	if !endAt.After(startAt) || !endAt.After(now) {
+		return errors.New("the end time should be after the start time and now.")
+	}
+	if endAt.Sub(startAt) > TEMPORARY_GRANT_MAX_DURATION {
+		return errors.New("the grant window is too long.")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type TemporaryGrantResponse struct {
+	*TemporaryGrantForExport
+}
+
+func NewTemporaryGrantResponse(temporaryGrant *TemporaryGrant, now time.Time) *TemporaryGrantResponse {
+	return &TemporaryGrantResponse{
+		TemporaryGrantForExport: temporaryGrant.Export(now),
+	}
+}
+
+func (resp *TemporaryGrantResponse) ExportForFeedback() interface{} {
+	return resp
+}
+
+type GetTemporaryGrantsResponse struct {
+	TemporaryGrants []*TemporaryGrantForExport
+}
+
+func NewGetTemporaryGrantsResponse(temporaryGrants []*TemporaryGrant, now time.Time) *GetTemporaryGrantsResponse {
+	return &GetTemporaryGrantsResponse{
+		TemporaryGrants: ExportTemporaryGrants(temporaryGrants, now),
+	}
+}
+
+func (resp *GetTemporaryGrantsResponse) ExportForFeedback() interface{} {
+	return resp.TemporaryGrants
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TemporaryGrantStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTemporaryGrantStorage(db *gorm.DB, logger *zap.SugaredLogger) *TemporaryGrantStorage {
+	return &TemporaryGrantStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *TemporaryGrantStorage) Create(g *TemporaryGrant) (int, error) {
+	if err := d.db.Create(g).Error; err != nil {
+		return 0, err
+	}
+	return g.ID, nil
+}
+
+func (d *TemporaryGrantStorage) RetrieveByTeamIDAndUserIDAndID(teamID int, userID int, id int) (*TemporaryGrant, error) {
+	g := &TemporaryGrant{}
+	if err := d.db.Where("team_id = ? AND user_id = ? AND id = ?", teamID, userID, id).First(&g).Error; err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// retrieve the grants which are active or scheduled, the expired grants are skipped.
+func (d *TemporaryGrantStorage) RetrieveUnexpiredByTeamIDAndUserID(teamID int, userID int, now time.Time) ([]*TemporaryGrant, error) {
+	var temporaryGrants []*TemporaryGrant
+	if err := d.db.Where("team_id = ? AND user_id = ? AND end_at > ?", teamID, userID, now).Order("start_at asc, id asc").Find(&temporaryGrants).Error; err != nil {
+		return nil, err
+	}
+	return temporaryGrants, nil
+}
+
+func (d *TemporaryGrantStorage) RetrieveActiveByTeamIDAndUserIDs(teamID int, userIDs []int, now time.Time) ([]*TemporaryGrant, error) {
+	var temporaryGrants []*TemporaryGrant
+	if err := d.db.Where("team_id = ? AND user_id IN ? AND start_at <= ? AND end_at > ?", teamID, userIDs, now, now).Order("start_at asc, id asc").Find(&temporaryGrants).Error; err != nil {
+		return nil, err
+	}
+	return temporaryGrants, nil
+}
+
+// revoke the grant by ending it now, so the grant history is kept.
+func (d *TemporaryGrantStorage) EndByTeamIDAndID(teamID int, id int, now time.Time) error {
+	if err := d.db.Model(&TemporaryGrant{}).Where("team_id = ? AND id = ?", teamID, id).Updates(map[string]interface{}{
+		"end_at":     now,
+		"updated_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+type TransferTeamOwnerRequest struct {
+	TargetTeamMemberID string `json:"targetTeamMemberID" validate:"required"`
+	Password           string `json:"password" validate:"required"`
+}
+
+func NewTransferTeamOwnerRequest() *TransferTeamOwnerRequest {
+	return &TransferTeamOwnerRequest{}
+}
+
+func (req *TransferTeamOwnerRequest) ExportTargetTeamMemberIDInInt() int {
+	return idconvertor.ConvertStringToInt(req.TargetTeamMemberID)
+}
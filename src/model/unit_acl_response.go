@@ -0,0 +1,27 @@
+package model
+
+import "github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+
+type UnitACLResponse struct {
+	TeamID   string                       `json:"teamID"`
+	UnitType int                          `json:"unitType"`
+	UnitID   string                       `json:"unitID"`
+	Entries  []*UnitRoleRelationForExport `json:"entries"`
+}
+
+func NewUnitACLResponse(teamID int, unitType int, unitID int, unitRoleRelations []*UnitRoleRelation) *UnitACLResponse {
+	resp := &UnitACLResponse{
+		TeamID:   idconvertor.ConvertIntToString(teamID),
+		UnitType: unitType,
+		UnitID:   idconvertor.ConvertIntToString(unitID),
+		Entries:  make([]*UnitRoleRelationForExport, 0, len(unitRoleRelations)),
+	}
+	for _, unitRoleRelation := range unitRoleRelations {
+		resp.Entries = append(resp.Entries, unitRoleRelation.Export())
+	}
+	return resp
+}
+
+func (resp *UnitACLResponse) ExportForFeedback() interface{} {
+	return resp
+}
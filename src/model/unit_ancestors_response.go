@@ -0,0 +1,24 @@
+package model
+
+import "github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+
+type UnitAncestorsResponse struct {
+	TeamID    string                    `json:"teamID"`
+	UnitType  int                       `json:"unitType"`
+	UnitID    string                    `json:"unitID"`
+	Ancestors []*UnitReferenceForExport `json:"ancestors"`
+}
+
+// the ancestors are ordered from parent to root.
+func NewUnitAncestorsResponse(teamID int, unit *UnitReference, ancestors []*UnitReference) *UnitAncestorsResponse {
+	return &UnitAncestorsResponse{
+		TeamID:    idconvertor.ConvertIntToString(teamID),
+		UnitType:  unit.UnitType,
+		UnitID:    idconvertor.ConvertIntToString(unit.UnitID),
+		Ancestors: ExportUnitReferences(ancestors),
+	}
+}
+
+func (resp *UnitAncestorsResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// the unit relation links a unit to its parent, e.g. an action belongs to an app. a unit has at most one parent.
+// the parent unit types can not have parent (see accesscontrol.UnitParentTypes), so the hierarchy has one level.
+type UnitRelation struct {
+	ID             int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID         int       `json:"teamID" gorm:"column:team_id;type:bigserial;uniqueIndex:unit_relations_team_unit;index:unit_relations_team_parent_unit"`
+	UnitType       int       `json:"unitType" gorm:"column:unit_type;type:smallint;uniqueIndex:unit_relations_team_unit"`
+	UnitID         int       `json:"unitID" gorm:"column:unit_id;type:bigint;uniqueIndex:unit_relations_team_unit"`
+	ParentUnitType int       `json:"parentUnitType" gorm:"column:parent_unit_type;type:smallint;index:unit_relations_team_parent_unit"`
+	ParentUnitID   int       `json:"parentUnitID" gorm:"column:parent_unit_id;type:bigint;index:unit_relations_team_parent_unit"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+// the unit reference identifies a unit in team.
+type UnitReference struct {
+	UnitType int
+	UnitID   int
+}
+
+type UnitReferenceForExport struct {
+	UnitType int    `json:"unitType"`
+	UnitID   string `json:"unitID"`
+}
+
+func NewUnitRelation(teamID int, unit *UnitReference, parent *UnitReference) *UnitRelation {
+	now := time.Now().UTC()
+	return &UnitRelation{
+		TeamID:         teamID,
+		UnitType:       unit.UnitType,
+		UnitID:         unit.UnitID,
+		ParentUnitType: parent.UnitType,
+		ParentUnitID:   parent.UnitID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func NewUnitReference(unitType int, unitID int) *UnitReference {
+	return &UnitReference{
+		UnitType: unitType,
+		UnitID:   unitID,
+	}
+}
+
+func (r *UnitRelation) ExportParent() *UnitReference {
+	return NewUnitReference(r.ParentUnitType, r.ParentUnitID)
+}
+
+func (u *UnitReference) Equals(other *UnitReference) bool {
+	return u.UnitType == other.UnitType && u.UnitID == other.UnitID
+}
+
+func (u *UnitReference) Export() *UnitReferenceForExport {
+	return &UnitReferenceForExport{
+		UnitType: u.UnitType,
+		UnitID:   idconvertor.ConvertIntToString(u.UnitID),
+	}
+}
+
+func ExportUnitReferences(units []*UnitReference) []*UnitReferenceForExport {
+	ret := make([]*UnitReferenceForExport, 0, len(units))
+	for _, unit := range units {
+		ret = append(ret, unit.Export())
+	}
+	return ret
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UnitRelationStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewUnitRelationStorage(db *gorm.DB, logger *zap.SugaredLogger) *UnitRelationStorage {
+	return &UnitRelationStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// retrieve the ancestors from parent to root, the unit without parent returns empty list.
+// the hierarchy has one level, so the ancestors are the parent only.
+func (d *UnitRelationStorage) RetrieveAncestorsByTeamIDAndUnit(teamID int, unit *UnitReference) ([]*UnitReference, error) {
+	ancestors := make([]*UnitReference, 0, 1)
+	var unitRelation *UnitRelation
+	err := d.db.Where("team_id = ? AND unit_type = ? AND unit_id = ?", teamID, unit.UnitType, unit.UnitID).First(&unitRelation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ancestors, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return append(ancestors, unitRelation.ExportParent()), nil
+}
+
+// the unit can only have one parent, set parent again will move the unit.
+func (d *UnitRelationStorage) Upsert(unitRelation *UnitRelation) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}, {Name: "unit_type"}, {Name: "unit_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"parent_unit_type", "parent_unit_id", "updated_at"}),
+	}).Omit("id").Create(unitRelation).Error
+}
+
+func (d *UnitRelationStorage) DeleteByTeamIDAndUnit(teamID int, unit *UnitReference) error {
+	if err := d.db.Where("team_id = ? AND unit_type = ? AND unit_id = ?", teamID, unit.UnitType, unit.UnitID).Delete(&UnitRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// delete the relations to parent and children of a removed unit, the children become roots.
+func (d *UnitRelationStorage) DeleteByTeamIDAndUnitOrParentUnit(teamID int, unit *UnitReference) error {
+	if err := d.db.Where("team_id = ? AND ((unit_type = ? AND unit_id = ?) OR (parent_unit_type = ? AND parent_unit_id = ?))", teamID, unit.UnitType, unit.UnitID, unit.UnitType, unit.UnitID).Delete(&UnitRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
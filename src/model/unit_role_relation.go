@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// 0 means the entry does not target by this subject.
+const UNIT_ACL_NO_SUBJECT = 0
+
+// the unit role relation is an ACL entry of a single unit, it targets one of custom role, built-in user role, user or user group.
+type UnitRoleRelation struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:unit_role_relations_team_unit"`
+	RoleID      int       `json:"roleID" gorm:"column:role_id;type:bigint"`
+	UnitID      int       `json:"unitID" gorm:"column:unit_id;type:bigserial;index:unit_role_relations_team_unit"`
+	UnitType    int       `json:"unitType" gorm:"column:unit_type;type:smallint;index:unit_role_relations_team_unit"`
+	Category    int       `json:"category" gorm:"column:category;type:smallint"`
+	UserRole    int       `json:"userRole" gorm:"column:user_role;type:smallint"`
+	UserID      int       `json:"userID" gorm:"column:user_id;type:bigint"`
+	UserGroupID int       `json:"userGroupID" gorm:"column:user_group_id;type:bigint"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type UnitRoleRelationForExport struct {
+	Category    int    `json:"category"`
+	RoleID      string `json:"roleID,omitempty"`
+	UserRole    int    `json:"userRole,omitempty"`
+	UserID      string `json:"userID,omitempty"`
+	UserGroupID string `json:"userGroupID,omitempty"`
+}
+
+func NewUnitRoleRelationByRequest(teamID int, unitType int, unitID int, req *UnitACLEntryRequest) (*UnitRoleRelation, error) {
+	now := time.Now().UTC()
+	relation := &UnitRoleRelation{
+		UID:         uuid.New(),
+		TeamID:      teamID,
+		UnitType:    unitType,
+		UnitID:      unitID,
+		Category:    req.Category,
+		RoleID:      convertOptionalStringIDToInt(req.RoleID),
+		UserRole:    req.UserRole,
+		UserID:      convertOptionalStringIDToInt(req.UserID),
+		UserGroupID: convertOptionalStringIDToInt(req.UserGroupID),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	subjects := 0
+	for _, subject := range []int{relation.RoleID, relation.UserRole, relation.UserID, relation.UserGroupID} {
+		if subject != UNIT_ACL_NO_SUBJECT {
+			subjects++
+		}
+	}
+	if subjects != 1 {
+		return nil, errors.New("the ACL entry should target exactly one of roleID, userRole, userID and userGroupID.")
+	}
+	return relation, nil
+}
+
+func (r *UnitRoleRelation) DoesTargetRole() bool {
+	return r.RoleID != UNIT_ACL_NO_SUBJECT
+}
+
+func (r *UnitRoleRelation) DoesTargetUser() bool {
+	return r.UserID != UNIT_ACL_NO_SUBJECT
+}
+
+func (r *UnitRoleRelation) DoesTargetUserGroup() bool {
+	return r.UserGroupID != UNIT_ACL_NO_SUBJECT
+}
+
+// check if the entry targets the user with the built-in user roles (includes the roles granted by user groups),
+// custom roles and user groups.
+func (r *UnitRoleRelation) DoesMatchSubject(userRoles []int, userID int, roleIDs []int, userGroupIDs []int) bool {
+	if r.UserRole != UNIT_ACL_NO_SUBJECT && doesIntsInclude(userRoles, r.UserRole) {
+		return true
+	}
+	if r.DoesTargetUser() && r.UserID == userID {
+		return true
+	}
+	if r.DoesTargetRole() && doesIntsInclude(roleIDs, r.RoleID) {
+		return true
+	}
+	if r.DoesTargetUserGroup() && doesIntsInclude(userGroupIDs, r.UserGroupID) {
+		return true
+	}
+	return false
+}
+
+func (r *UnitRoleRelation) Export() *UnitRoleRelationForExport {
+	ret := &UnitRoleRelationForExport{
+		Category: r.Category,
+		UserRole: r.UserRole,
+	}
+	if r.DoesTargetRole() {
+		ret.RoleID = idconvertor.ConvertIntToString(r.RoleID)
+	}
+	if r.DoesTargetUser() {
+		ret.UserID = idconvertor.ConvertIntToString(r.UserID)
+	}
+	if r.DoesTargetUserGroup() {
+		ret.UserGroupID = idconvertor.ConvertIntToString(r.UserGroupID)
+	}
+	return ret
+}
+
+// the picked up ids are unique.
+func PickUpRoleIDsInUnitRoleRelations(unitRoleRelations []*UnitRoleRelation) []int {
+	ids := make([]int, 0, len(unitRoleRelations))
+	lt := make(map[int]bool, len(unitRoleRelations))
+	for _, unitRoleRelation := range unitRoleRelations {
+		if !unitRoleRelation.DoesTargetRole() || lt[unitRoleRelation.RoleID] {
+			continue
+		}
+		lt[unitRoleRelation.RoleID] = true
+		ids = append(ids, unitRoleRelation.RoleID)
+	}
+	return ids
+}
+
+func PickUpUserIDsInUnitRoleRelations(unitRoleRelations []*UnitRoleRelation) []int {
+	ids := make([]int, 0, len(unitRoleRelations))
+	lt := make(map[int]bool, len(unitRoleRelations))
+	for _, unitRoleRelation := range unitRoleRelations {
+		if !unitRoleRelation.DoesTargetUser() || lt[unitRoleRelation.UserID] {
+			continue
+		}
+		lt[unitRoleRelation.UserID] = true
+		ids = append(ids, unitRoleRelation.UserID)
+	}
+	return ids
+}
+
+func PickUpUserGroupIDsInUnitRoleRelations(unitRoleRelations []*UnitRoleRelation) []int {
+	ids := make([]int, 0, len(unitRoleRelations))
+	lt := make(map[int]bool, len(unitRoleRelations))
+	for _, unitRoleRelation := range unitRoleRelations {
+		if !unitRoleRelation.DoesTargetUserGroup() || lt[unitRoleRelation.UserGroupID] {
+			continue
+		}
+		lt[unitRoleRelation.UserGroupID] = true
+		ids = append(ids, unitRoleRelation.UserGroupID)
+	}
+	return ids
+}
+
+func doesIntsInclude(ints []int, target int) bool {
+	for _, i := range ints {
+		if i == target {
+			return true
+		}
+	}
+	return false
+}
+
+func convertOptionalStringIDToInt(stringID string) int {
+	if stringID == "" {
+		return UNIT_ACL_NO_SUBJECT
+	}
+	return idconvertor.ConvertStringToInt(stringID)
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UnitRoleRelationStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewUnitRoleRelationStorage(db *gorm.DB, logger *zap.SugaredLogger) *UnitRoleRelationStorage {
+	return &UnitRoleRelationStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *UnitRoleRelationStorage) RetrieveByTeamIDAndUnit(teamID int, unitType int, unitID int) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if err := d.db.Where("team_id = ? AND unit_type = ? AND unit_id = ?", teamID, unitType, unitID).Order("id asc").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
+
+// replace the whole ACL of the unit.
+func (d *UnitRoleRelationStorage) ReplaceByTeamIDAndUnit(teamID int, unitType int, unitID int, unitRoleRelations []*UnitRoleRelation) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND unit_type = ? AND unit_id = ?", teamID, unitType, unitID).Delete(&UnitRoleRelation{}).Error; err != nil {
+			return err
+		}
+		if len(unitRoleRelations) == 0 {
+			return nil
+		}
+		return tx.Create(&unitRoleRelations).Error
+	})
+}
+
+func (d *UnitRoleRelationStorage) DeleteByTeamIDAndUserGroupID(teamID int, userGroupID int) error {
+	if err := d.db.Where("team_id = ? AND user_group_id = ?", teamID, userGroupID).Delete(&UnitRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// retrieve the ACL of units at once, it is used to evaluate the ACL inherited from ancestors.
+func (d *UnitRoleRelationStorage) RetrieveByTeamIDAndUnits(teamID int, units []*UnitReference) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if len(units) == 0 {
+		return unitRoleRelations, nil
+	}
+	unitPairs := make([][]interface{}, 0, len(units))
+	for _, unit := range units {
+		unitPairs = append(unitPairs, []interface{}{unit.UnitType, unit.UnitID})
+	}
+	if err := d.db.Where("team_id = ? AND (unit_type, unit_id) IN ?", teamID, unitPairs).Order("id asc").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
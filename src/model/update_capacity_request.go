@@ -0,0 +1,11 @@
+package model
+
+type UpdateCapacityRequest struct {
+	MaxMembers   int   `json:"maxMembers" validate:"gte=0"`   // 0 means unlimited
+	MaxEditors   int   `json:"maxEditors" validate:"gte=0"`   // 0 means unlimited
+	StorageQuota int64 `json:"storageQuota" validate:"gte=0"` // in bytes, 0 means unlimited
+}
+
+func NewUpdateCapacityRequest() *UpdateCapacityRequest {
+	return &UpdateCapacityRequest{}
+}
@@ -0,0 +1,9 @@
+package model
+
+type UpdateTeamIconRequest struct {
+	FileName string `json:"fileName" validate:"required"`
+}
+
+func NewUpdateTeamIconRequest() *UpdateTeamIconRequest {
+	return &UpdateTeamIconRequest{}
+}
@@ -0,0 +1,15 @@
+package model
+
+type UpdateTeamIPAllowlistEntryRequest struct {
+	CIDR        string `json:"cidr" validate:"required"` // the bare IP is accepted too
+	Description string `json:"description" validate:"max=255"`
+}
+
+// the empty entries disable the allowlist.
+type UpdateTeamIPAllowlistRequest struct {
+	Entries []*UpdateTeamIPAllowlistEntryRequest `json:"entries" validate:"max=100,dive,required"`
+}
+
+func NewUpdateTeamIPAllowlistRequest() *UpdateTeamIPAllowlistRequest {
+	return &UpdateTeamIPAllowlistRequest{}
+}
@@ -0,0 +1,13 @@
+package model
+
+type UpdateTeamMemberPermissionRequest struct {
+	Overrides []*PermissionOverride `json:"overrides" validate:"max=100,dive,required"`
+}
+
+func NewUpdateTeamMemberPermissionRequest() *UpdateTeamMemberPermissionRequest {
+	return &UpdateTeamMemberPermissionRequest{}
+}
+
+func (req *UpdateTeamMemberPermissionRequest) ExportOverrides() []*PermissionOverride {
+	return req.Overrides
+}
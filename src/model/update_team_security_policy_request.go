@@ -0,0 +1,13 @@
+package model
+
+type UpdateTeamSecurityPolicyRequest struct {
+	RequireTwoFactor   bool     `json:"requireTwoFactor"`
+	MaxSessionAge      int      `json:"maxSessionAge" validate:"gte=0"` // in seconds, 0 means unlimited
+	IdleTimeout        int      `json:"idleTimeout" validate:"gte=0"`   // in seconds, 0 means unlimited
+	SSOOnly            bool     `json:"ssoOnly"`
+	AllowedAuthMethods []string `json:"allowedAuthMethods" validate:"unique,dive,oneof=password sso"` // empty means all auth methods are allowed
+}
+
+func NewUpdateTeamSecurityPolicyRequest() *UpdateTeamSecurityPolicyRequest {
+	return &UpdateTeamSecurityPolicyRequest{}
+}
@@ -0,0 +1,32 @@
+package model
+
+// the entry targets one of roleID, userRole, userID and userGroupID.
+// the owner is always exempt from unit ACL, so only admin, editor and viewer can be targeted by userRole.
+type UnitACLEntryRequest struct {
+	Category    int    `json:"category" validate:"min=1,max=4"`
+	RoleID      string `json:"roleID"`
+	UserRole    int    `json:"userRole" validate:"omitempty,min=2,max=4"`
+	UserID      string `json:"userID"`
+	UserGroupID string `json:"userGroupID"`
+}
+
+// the empty entries remove the ACL of the unit.
+type UpdateUnitACLRequest struct {
+	Entries []*UnitACLEntryRequest `json:"entries" validate:"max=100,dive,required"`
+}
+
+func NewUpdateUnitACLRequest() *UpdateUnitACLRequest {
+	return &UpdateUnitACLRequest{}
+}
+
+func (req *UpdateUnitACLRequest) ExportUnitRoleRelations(teamID int, unitType int, unitID int) ([]*UnitRoleRelation, error) {
+	unitRoleRelations := make([]*UnitRoleRelation, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		unitRoleRelation, errInNew := NewUnitRoleRelationByRequest(teamID, unitType, unitID, entry)
+		if errInNew != nil {
+			return nil, errInNew
+		}
+		unitRoleRelations = append(unitRoleRelations, unitRoleRelation)
+	}
+	return unitRoleRelations, nil
+}
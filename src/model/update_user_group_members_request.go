@@ -0,0 +1,14 @@
+package model
+
+type UpdateUserGroupMembersRequest struct {
+	TeamMemberIDs []string `json:"teamMemberIDs" validate:"required,max=500,dive,required"`
+}
+
+func NewUpdateUserGroupMembersRequest() *UpdateUserGroupMembersRequest {
+	return &UpdateUserGroupMembersRequest{}
+}
+
+// the duplicated ids will be removed.
+func (req *UpdateUserGroupMembersRequest) ExportTeamMemberIDsInInt() []int {
+	return ConvertStringIDsToUniqueIntIDs(req.TeamMemberIDs)
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/illa-supervisor-backend/src/utils/idconvertor"
+)
+
+// the user group without role only grants the attributes in its permission overrides.
+const USER_GROUP_NO_ROLE = 0
+
+type UserGroup struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:user_groups_ukey"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:user_groups_ukey"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:user_groups_team_id_and_name"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar;size:64;index:user_groups_team_id_and_name"`
+	Description string    `json:"description" gorm:"column:description;type:varchar;size:255"`
+	UserRole    int       `json:"userRole" gorm:"column:user_role;type:smallint"`
+	Permission  string    `json:"permission" gorm:"column:permission;type:jsonb"`    // the attribute grants of this group
+	EditorSeat  bool      `json:"editorSeat" gorm:"column:editor_seat;type:boolean"` // the group grants role or attributes beyond viewer, so its members take editor seats
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type UserGroupForExport struct {
+	ID            string                `json:"userGroupID"`
+	TeamID        string                `json:"teamID"`
+	Name          string                `json:"name"`
+	Description   string                `json:"description"`
+	UserRole      int                   `json:"userRole"`
+	Overrides     []*PermissionOverride `json:"overrides"`
+	TeamMemberIDs []string              `json:"teamMemberIDs"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+}
+
+type UserGroupPermission struct {
+	Overrides []*PermissionOverride `json:"overrides"`
+}
+
+func NewUserGroup() *UserGroup {
+	return &UserGroup{}
+}
+
+func NewUserGroupByCreateRequest(teamID int, req *UserGroupRequest) *UserGroup {
+	userGroup := &UserGroup{
+		TeamID: teamID,
+	}
+	userGroup.InitUID()
+	userGroup.InitCreatedAt()
+	userGroup.UpdateByRequest(req)
+	return userGroup
+}
+
+func (g *UserGroup) InitUID() {
+	g.UID = uuid.New()
+}
+
+func (g *UserGroup) InitCreatedAt() {
+	g.CreatedAt = time.Now().UTC()
+}
+
+func (g *UserGroup) InitUpdatedAt() {
+	g.UpdatedAt = time.Now().UTC()
+}
+
+func (g *UserGroup) UpdateByRequest(req *UserGroupRequest) {
+	g.Name = req.Name
+	g.Description = req.Description
+	g.UserRole = req.UserRole
+	permission := &UserGroupPermission{Overrides: req.ExportOverrides()}
+	r, _ := json.Marshal(permission)
+	g.Permission = string(r)
+	g.InitUpdatedAt()
+}
+
+func (g *UserGroup) SetEditorSeat(editorSeat bool) {
+	g.EditorSeat = editorSeat
+}
+
+func (g *UserGroup) ExportID() int {
+	return g.ID
+}
+
+func (g *UserGroup) ExportUserRole() int {
+	return g.UserRole
+}
+
+func (g *UserGroup) HasUserRole() bool {
+	if g.UserRole == USER_GROUP_NO_ROLE {
+		return false
+	}
+	return true
+}
+
+func (g *UserGroup) ExportPermissionOverrides() []*PermissionOverride {
+	permission := &UserGroupPermission{}
+	json.Unmarshal([]byte(g.Permission), permission)
+	return permission.Overrides
+}
+
+func (g *UserGroup) Export(teamMemberIDs []int) *UserGroupForExport {
+	ret := &UserGroupForExport{
+		ID:            idconvertor.ConvertIntToString(g.ID),
+		TeamID:        idconvertor.ConvertIntToString(g.TeamID),
+		Name:          g.Name,
+		Description:   g.Description,
+		UserRole:      g.UserRole,
+		Overrides:     g.ExportPermissionOverrides(),
+		TeamMemberIDs: make([]string, 0, len(teamMemberIDs)),
+		CreatedAt:     g.CreatedAt,
+		UpdatedAt:     g.UpdatedAt,
+	}
+	for _, teamMemberID := range teamMemberIDs {
+		ret.TeamMemberIDs = append(ret.TeamMemberIDs, idconvertor.ConvertIntToString(teamMemberID))
+	}
+	return ret
+}
+
+func PickUpUserGroupIDsInUserGroups(userGroups []*UserGroup) []int {
+	ids := make([]int, len(userGroups))
+	for serial, userGroup := range userGroups {
+		ids[serial] = userGroup.ID
+	}
+	return ids
+}
+
+type UserGroupMember struct {
+	ID           int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID       int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:user_group_members_team_group_and_member_id"`
+	UserGroupID  int       `json:"userGroupID" gorm:"column:user_group_id;type:bigserial;index:user_group_members_team_group_and_member_id"`
+	TeamMemberID int       `json:"teamMemberID" gorm:"column:team_member_id;type:bigserial;index:user_group_members_team_group_and_member_id"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp"`
+}
+
+func NewUserGroupMember(teamID int, userGroupID int, teamMemberID int) *UserGroupMember {
+	return &UserGroupMember{
+		TeamID:       teamID,
+		UserGroupID:  userGroupID,
+		TeamMemberID: teamMemberID,
+		CreatedAt:    time.Now().UTC(),
+	}
+}
+
+func PickUpTeamMemberIDsInUserGroupMembers(userGroupMembers []*UserGroupMember) []int {
+	ids := make([]int, len(userGroupMembers))
+	for serial, userGroupMember := range userGroupMembers {
+		ids[serial] = userGroupMember.TeamMemberID
+	}
+	return ids
+}
+
+// build lookup table of user group id to team member ids.
+func BuildUserGroupIDLookUpTableForTeamMemberIDs(userGroupMembers []*UserGroupMember) map[int][]int {
+	lt := make(map[int][]int)
+	for _, userGroupMember := range userGroupMembers {
+		lt[userGroupMember.UserGroupID] = append(lt[userGroupMember.UserGroupID], userGroupMember.TeamMemberID)
+	}
+	return lt
+}
+
+func ConvertStringIDsToUniqueIntIDs(stringIDs []string) []int {
+	ids := make([]int, 0, len(stringIDs))
+	lt := make(map[int]bool, len(stringIDs))
+	for _, stringID := range stringIDs {
+		id := idconvertor.ConvertStringToInt(stringID)
+		if lt[id] {
+			continue
+		}
+		lt[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserGroupMemberStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewUserGroupMemberStorage(db *gorm.DB, logger *zap.SugaredLogger) *UserGroupMemberStorage {
+	return &UserGroupMemberStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *UserGroupMemberStorage) RetrieveByTeamID(teamID int) ([]*UserGroupMember, error) {
+	var userGroupMembers []*UserGroupMember
+	if err := d.db.Where("team_id = ?", teamID).Find(&userGroupMembers).Error; err != nil {
+		return nil, err
+	}
+	return userGroupMembers, nil
+}
+
+func (d *UserGroupMemberStorage) RetrieveByTeamIDAndUserGroupID(teamID int, userGroupID int) ([]*UserGroupMember, error) {
+	var userGroupMembers []*UserGroupMember
+	if err := d.db.Where("team_id = ? AND user_group_id = ?", teamID, userGroupID).Order("id asc").Find(&userGroupMembers).Error; err != nil {
+		return nil, err
+	}
+	return userGroupMembers, nil
+}
+
+// add team members into user groups, the existing members will be skipped.
+// the editor seats will be taken when the user groups grant editor seat.
+func (d *UserGroupMemberStorage) CreateByTeamIDAndUserGroupIDsAndTeamMemberIDs(teamID int, userGroupIDs []int, teamMemberIDs []int) error {
+	return takeSeats(d.db, teamID, func(tx *gorm.DB) error {
+		return createUserGroupMembers(tx, teamID, userGroupIDs, teamMemberIDs)
+	})
+}
+
+func (d *UserGroupMemberStorage) DeleteByTeamIDAndUserGroupIDAndTeamMemberID(teamID int, userGroupID int, teamMemberID int) error {
+	if err := d.db.Where("team_id = ? AND user_group_id = ? AND team_member_id = ?", teamID, userGroupID, teamMemberID).Delete(&UserGroupMember{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// the user groups which have been deleted will be skipped.
+func createUserGroupMembers(tx *gorm.DB, teamID int, userGroupIDs []int, teamMemberIDs []int) error {
+	if len(userGroupIDs) == 0 || len(teamMemberIDs) == 0 {
+		return nil
+	}
+	var avaliableUserGroupIDs []int
+	if err := tx.Model(&UserGroup{}).Where("team_id = ? AND id IN ?", teamID, userGroupIDs).Pluck("id", &avaliableUserGroupIDs).Error; err != nil {
+		return err
+	}
+	userGroupIDs = avaliableUserGroupIDs
+	var existing []*UserGroupMember
+	if err := tx.Where("team_id = ? AND user_group_id IN ? AND team_member_id IN ?", teamID, userGroupIDs, teamMemberIDs).Find(&existing).Error; err != nil {
+		return err
+	}
+	// the key is [user group id, team member id]
+	existingLT := make(map[[2]int]bool, len(existing))
+	for _, userGroupMember := range existing {
+		existingLT[[2]int{userGroupMember.UserGroupID, userGroupMember.TeamMemberID}] = true
+	}
+	userGroupMembers := make([]*UserGroupMember, 0, len(userGroupIDs)*len(teamMemberIDs))
+	for _, userGroupID := range userGroupIDs {
+		for _, teamMemberID := range teamMemberIDs {
+			key := [2]int{userGroupID, teamMemberID}
+			if existingLT[key] {
+				continue
+			}
+			existingLT[key] = true
+			userGroupMembers = append(userGroupMembers, NewUserGroupMember(teamID, userGroupID, teamMemberID))
+		}
+	}
+	if len(userGroupMembers) == 0 {
+		return nil
+	}
+	return tx.Create(&userGroupMembers).Error
+}
@@ -0,0 +1,16 @@
+package model
+
+type UserGroupRequest struct {
+	Name        string                `json:"name" validate:"required,max=64"`
+	Description string                `json:"description" validate:"max=255"`
+	UserRole    int                   `json:"userRole" validate:"omitempty,oneof=2 3 4"` // 0 means this group grants no role
+	Overrides   []*PermissionOverride `json:"overrides" validate:"max=100,dive,required"`
+}
+
+func NewUserGroupRequest() *UserGroupRequest {
+	return &UserGroupRequest{}
+}
+
+func (req *UserGroupRequest) ExportOverrides() []*PermissionOverride {
+	return req.Overrides
+}
@@ -0,0 +1,15 @@
+package model
+
+type UserGroupResponse struct {
+	*UserGroupForExport
+}
+
+func NewUserGroupResponse(userGroup *UserGroup, teamMemberIDs []int) *UserGroupResponse {
+	return &UserGroupResponse{
+		UserGroupForExport: userGroup.Export(teamMemberIDs),
+	}
+}
+
+func (resp *UserGroupResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserGroupStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewUserGroupStorage(db *gorm.DB, logger *zap.SugaredLogger) *UserGroupStorage {
+	return &UserGroupStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *UserGroupStorage) Create(u *UserGroup) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *UserGroupStorage) RetrieveByTeamID(teamID int) ([]*UserGroup, error) {
+	var userGroups []*UserGroup
+	if err := d.db.Where("team_id = ?", teamID).Order("id asc").Find(&userGroups).Error; err != nil {
+		return nil, err
+	}
+	return userGroups, nil
+}
+
+func (d *UserGroupStorage) RetrieveByTeamIDAndID(teamID int, id int) (*UserGroup, error) {
+	u := &UserGroup{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *UserGroupStorage) RetrieveByTeamIDAndIDs(teamID int, ids []int) ([]*UserGroup, error) {
+	var userGroups []*UserGroup
+	if err := d.db.Where("team_id = ? AND id IN ?", teamID, ids).Find(&userGroups).Error; err != nil {
+		return nil, err
+	}
+	return userGroups, nil
+}
+
+// retrieve the user groups which the team member belongs to.
+func (d *UserGroupStorage) RetrieveByTeamIDAndTeamMemberID(teamID int, teamMemberID int) ([]*UserGroup, error) {
+	var userGroups []*UserGroup
+	subQuery := d.db.Model(&UserGroupMember{}).Select("user_group_id").Where("team_id = ? AND team_member_id = ?", teamID, teamMemberID)
+	if err := d.db.Where("team_id = ? AND id IN (?)", teamID, subQuery).Find(&userGroups).Error; err != nil {
+		return nil, err
+	}
+	return userGroups, nil
+}
+
+func (d *UserGroupStorage) DoesNameExist(teamID int, name string, excludedID int) (bool, error) {
+	var count int64
+	if err := d.db.Model(&UserGroup{}).Where("team_id = ? AND name = ? AND id <> ?", teamID, name, excludedID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// the editor seats will be taken by the group members when the user group grants editor seat.
+func (d *UserGroupStorage) UpdateByID(u *UserGroup) error {
+	return takeSeats(d.db, u.TeamID, func(tx *gorm.DB) error {
+		return tx.Model(u).Where("id = ?", u.ID).Select("*").Omit("id").Updates(u).Error
+	})
+}
+
+// delete the user group with all its members and the unit ACL entries target to it.
+func (d *UserGroupStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND user_group_id = ?", teamID, id).Delete(&UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ? AND user_group_id = ?", teamID, id).Delete(&UnitRoleRelation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ? AND id = ?", teamID, id).Delete(&UserGroup{}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
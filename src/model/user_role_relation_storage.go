@@ -0,0 +1,68 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserRoleRelationStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewUserRoleRelationStorage(db *gorm.DB, logger *zap.SugaredLogger) *UserRoleRelationStorage {
+	return &UserRoleRelationStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *UserRoleRelationStorage) RetrieveByTeamID(teamID int) ([]*UserRoleRelation, error) {
+	var userRoleRelations []*UserRoleRelation
+	if err := d.db.Where("team_id = ?", teamID).Find(&userRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return userRoleRelations, nil
+}
+
+func (d *UserRoleRelationStorage) RetrieveByTeamIDAndRoleID(teamID int, roleID int) ([]*UserRoleRelation, error) {
+	var userRoleRelations []*UserRoleRelation
+	if err := d.db.Where("team_id = ? AND role_id = ?", teamID, roleID).Order("id asc").Find(&userRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return userRoleRelations, nil
+}
+
+// assign the role to users, the existing relations will be skipped.
+// the editor seats will be taken when the role grants editor seat.
+func (d *UserRoleRelationStorage) CreateByTeamIDAndRoleIDAndUserIDs(teamID int, roleID int, userIDs []int) error {
+	return takeSeats(d.db, teamID, func(tx *gorm.DB) error {
+		var existingUserIDs []int
+		if err := tx.Model(&UserRoleRelation{}).Where("team_id = ? AND role_id = ? AND user_id IN ?", teamID, roleID, userIDs).Pluck("user_id", &existingUserIDs).Error; err != nil {
+			return err
+		}
+		existingLT := make(map[int]bool, len(existingUserIDs))
+		for _, userID := range existingUserIDs {
+			existingLT[userID] = true
+		}
+		userRoleRelations := make([]*UserRoleRelation, 0, len(userIDs))
+		for _, userID := range userIDs {
+			if existingLT[userID] {
+				continue
+			}
+			existingLT[userID] = true
+			userRoleRelations = append(userRoleRelations, NewUserRoleRelation(teamID, roleID, userID))
+		}
+		if len(userRoleRelations) == 0 {
+			return nil
+		}
+		return tx.Create(&userRoleRelations).Error
+	})
+}
+
+func (d *UserRoleRelationStorage) DeleteByTeamIDAndRoleIDAndUserID(teamID int, roleID int, userID int) error {
+	if err := d.db.Where("team_id = ? AND role_id = ? AND user_id = ?", teamID, roleID, userID).Delete(&UserRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -24,11 +24,14 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	authRouter := routerGroup.Group("/auth")
 	usersRouter := routerGroup.Group("/users")
 	teamsRouter := routerGroup.Group("/teams")
+	invitesRouter := routerGroup.Group("/invites")
+	inviteLinksRouter := routerGroup.Group("/inviteLinks")
 	statusRouter := routerGroup.Group("/status")
 
 	// register auth
 	usersRouter.Use(r.Authenticator.JWTAuth())
 	teamsRouter.Use(r.Authenticator.JWTAuth())
+	invitesRouter.Use(r.Authenticator.JWTAuth())
 
 	// auth routers
 	authRouter.POST("/verification", r.Controller.GetVerificationCode)
@@ -50,6 +53,71 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	teamsRouter.GET("/my", r.Controller.GetMyTeams)
 	teamsRouter.PATCH("/:teamID/config", r.Controller.UpdateTeamConfig)
 	teamsRouter.PATCH("/:teamID/permission", r.Controller.UpdateTeamPermission)
+	teamsRouter.GET("/:teamID/icon/uploadAddress/fileName/:fileName", r.Controller.GetTeamIconUploadAddress)
+	teamsRouter.PATCH("/:teamID/icon", r.Controller.UpdateTeamIcon)
+	teamsRouter.GET("/:teamID/members", r.Controller.GetAllTeamMembers)
+	teamsRouter.POST("/:teamID/members/import", r.Controller.ImportTeamMembers)
+	teamsRouter.GET("/:teamID/members/export", r.Controller.ExportTeamMembers)
+	teamsRouter.DELETE("/:teamID/members/:teamMemberID", r.Controller.RemoveTeamMember)
+	teamsRouter.PATCH("/:teamID/members/:teamMemberID/role", r.Controller.UpdateTeamMemberRole)
+	teamsRouter.POST("/:teamID/members/:teamMemberID/suspend", r.Controller.SuspendTeamMember)
+	teamsRouter.POST("/:teamID/members/:teamMemberID/reactivate", r.Controller.ReactivateTeamMember)
+	teamsRouter.POST("/:teamID/members/:teamMemberID/approve", r.Controller.ApproveTeamMember)
+	teamsRouter.GET("/:teamID/members/:teamMemberID/permission", r.Controller.GetTeamMemberPermission)
+	teamsRouter.PUT("/:teamID/members/:teamMemberID/permission", r.Controller.UpdateTeamMemberPermission)
+	teamsRouter.GET("/:teamID/members/:teamMemberID/temporaryGrants", r.Controller.GetTemporaryGrants)
+	teamsRouter.POST("/:teamID/members/:teamMemberID/temporaryGrants", r.Controller.CreateTemporaryGrant)
+	teamsRouter.DELETE("/:teamID/members/:teamMemberID/temporaryGrants/:temporaryGrantID", r.Controller.RevokeTemporaryGrant)
+	teamsRouter.GET("/:teamID/userGroups", r.Controller.GetAllUserGroups)
+	teamsRouter.POST("/:teamID/userGroups", r.Controller.CreateUserGroup)
+	teamsRouter.GET("/:teamID/userGroups/:userGroupID", r.Controller.GetUserGroup)
+	teamsRouter.PUT("/:teamID/userGroups/:userGroupID", r.Controller.UpdateUserGroup)
+	teamsRouter.DELETE("/:teamID/userGroups/:userGroupID", r.Controller.DeleteUserGroup)
+	teamsRouter.POST("/:teamID/userGroups/:userGroupID/members", r.Controller.AddUserGroupMembers)
+	teamsRouter.DELETE("/:teamID/userGroups/:userGroupID/members/:teamMemberID", r.Controller.RemoveUserGroupMember)
+	teamsRouter.GET("/:teamID/roles", r.Controller.GetAllRoles)
+	teamsRouter.POST("/:teamID/roles", r.Controller.CreateRole)
+	teamsRouter.GET("/:teamID/roles/:roleID", r.Controller.GetRole)
+	teamsRouter.PUT("/:teamID/roles/:roleID", r.Controller.UpdateRole)
+	teamsRouter.DELETE("/:teamID/roles/:roleID", r.Controller.DeleteRole)
+	teamsRouter.POST("/:teamID/roles/:roleID/users", r.Controller.AssignRoleUsers)
+	teamsRouter.DELETE("/:teamID/roles/:roleID/users/:targetUserID", r.Controller.RemoveRoleUser)
+	teamsRouter.GET("/:teamID/unitType/:unitType/unitID/:unitID/acl", r.Controller.GetUnitACL)
+	teamsRouter.PUT("/:teamID/unitType/:unitType/unitID/:unitID/acl", r.Controller.UpdateUnitACL)
+	teamsRouter.GET("/:teamID/emailDomains", r.Controller.GetAllEmailDomains)
+	teamsRouter.POST("/:teamID/emailDomains", r.Controller.CreateEmailDomain)
+	teamsRouter.PUT("/:teamID/emailDomains/:emailDomainID", r.Controller.UpdateEmailDomain)
+	teamsRouter.POST("/:teamID/emailDomains/:emailDomainID/verify", r.Controller.VerifyEmailDomain)
+	teamsRouter.DELETE("/:teamID/emailDomains/:emailDomainID", r.Controller.DeleteEmailDomain)
+	teamsRouter.GET("/:teamID/domains", r.Controller.GetAllDomains)
+	teamsRouter.POST("/:teamID/domains", r.Controller.CreateDomain)
+	teamsRouter.POST("/:teamID/domains/:domainID/verify", r.Controller.VerifyDomain)
+	teamsRouter.DELETE("/:teamID/domains/:domainID", r.Controller.DeleteDomain)
+	teamsRouter.GET("/:teamID/ipAllowlist", r.Controller.GetTeamIPAllowlist)
+	teamsRouter.PUT("/:teamID/ipAllowlist", r.Controller.UpdateTeamIPAllowlist)
+	teamsRouter.GET("/:teamID/securityPolicy", r.Controller.GetTeamSecurityPolicy)
+	teamsRouter.PUT("/:teamID/securityPolicy", r.Controller.UpdateTeamSecurityPolicy)
+	teamsRouter.GET("/:teamID/settings/history", r.Controller.GetTeamSettingsHistory)
+	teamsRouter.POST("/:teamID/settings/history/:version/rollback", r.Controller.RollbackTeamSettings)
+	teamsRouter.POST("/:teamID/archive", r.Controller.ArchiveTeam)
+	teamsRouter.POST("/:teamID/restore", r.Controller.RestoreTeam)
+	teamsRouter.POST("/:teamID/leave", r.Controller.LeaveTeam)
+	teamsRouter.POST("/:teamID/owner/transfer", r.Controller.TransferTeamOwner)
+	teamsRouter.POST("/:teamID/invites/email", r.Controller.InviteMemberByEmail)
+	teamsRouter.GET("/:teamID/invites", r.Controller.GetAllPendingInvites)
+	teamsRouter.POST("/:teamID/invites/:inviteID/resend", r.Controller.ResendInvite)
+	teamsRouter.DELETE("/:teamID/invites/:inviteID", r.Controller.RevokeInvite)
+	teamsRouter.GET("/:teamID/inviteLinks/userRole/:userRole", r.Controller.GetInviteLink)
+	teamsRouter.POST("/:teamID/inviteLinks/userRole/:userRole", r.Controller.CreateInviteLink)
+	teamsRouter.POST("/:teamID/inviteLinks/userRole/:userRole/renew", r.Controller.RenewInviteLink)
+	teamsRouter.DELETE("/:teamID/inviteLinks/userRole/:userRole", r.Controller.DisableInviteLink)
+
+	// invite routers
+	invitesRouter.POST("/:inviteHash/accept", r.Controller.AcceptInvite)
+
+	// invite link routers, the preview is public for the user who has not signed in yet
+	inviteLinksRouter.GET("/:inviteLinkHash", r.Controller.PreviewInviteLink)
+	inviteLinksRouter.POST("/:inviteLinkHash/join", r.Authenticator.JWTAuth(), r.Controller.JoinByInviteLink)
 
 	// status router
 	statusRouter.GET("", r.Controller.Status)
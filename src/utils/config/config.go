@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -61,6 +62,34 @@ type Config struct {
 	DriveTeamBucketName   string `env:"ILLA_DRIVE_TEAM_BUCKET_NAME"   envDefault:"illa-supervisor-team"`
 	DriveUploadTimeoutRaw string `env:"ILLA_DRIVE_UPLOAD_TIMEOUT"     envDefault:"300s"`
 	DriveUploadTimeout    time.Duration
+
+	// domain config, the team system domain will be "{prefix}.{system domain}"
+	SystemDomain string `env:"ILLA_SYSTEM_DOMAIN" envDefault:"illa.ai"`
+
+	// team archive config, the archived team will be purged after retention
+	TeamArchiveRetentionRaw     string `env:"ILLA_TEAM_ARCHIVE_RETENTION"      envDefault:"720h"`
+	TeamArchiveRetention        time.Duration
+	TeamArchivePurgeIntervalRaw string `env:"ILLA_TEAM_ARCHIVE_PURGE_INTERVAL" envDefault:"1h"`
+	TeamArchivePurgeInterval    time.Duration
+
+	// dns resolver config, the empty address means system resolver
+	DNSResolverAddr       string `env:"ILLA_DNS_RESOLVER_ADDR"    envDefault:""`
+	DNSResolverTimeoutRaw string `env:"ILLA_DNS_RESOLVER_TIMEOUT" envDefault:"5s"`
+	DNSResolverTimeout    time.Duration
+
+	// trusted proxy config, comma separated IPs or CIDRs, the X-Forwarded-For header is only trusted when sent by them
+	TrustedProxiesRaw string `env:"ILLA_TRUSTED_PROXIES" envDefault:""`
+	TrustedProxies    []string
+
+	// access control policy config, the empty file means the compiled-in default policy
+	AccessControlPolicyFile             string `env:"ILLA_ACCESS_CONTROL_POLICY_FILE"           envDefault:""`
+	AccessControlPolicyWatchIntervalRaw string `env:"ILLA_ACCESS_CONTROL_POLICY_WATCH_INTERVAL" envDefault:"10s"`
+	AccessControlPolicyWatchInterval    time.Duration
+
+	// access control cache config, the member roles and team permissions are cached in redis and in-process LRU, 0 ttl disables the cache
+	AccessControlCacheTTLRaw string `env:"ILLA_ACCESS_CONTROL_CACHE_TTL"  envDefault:"60s"`
+	AccessControlCacheTTL    time.Duration
+	AccessControlCacheSize   int `env:"ILLA_ACCESS_CONTROL_CACHE_SIZE" envDefault:"10000"`
 }
 
 func getConfig() (*Config, error) {
@@ -74,6 +103,41 @@ func getConfig() (*Config, error) {
 	if errInParseDuration != nil {
 		return nil, errInParseDuration
 	}
+	cfg.DNSResolverTimeout, errInParseDuration = time.ParseDuration(cfg.DNSResolverTimeoutRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.TeamArchiveRetention, errInParseDuration = time.ParseDuration(cfg.TeamArchiveRetentionRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.TeamArchivePurgeInterval, errInParseDuration = time.ParseDuration(cfg.TeamArchivePurgeIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	if cfg.TeamArchivePurgeInterval <= 0 {
+		return nil, fmt.Errorf("ILLA_TEAM_ARCHIVE_PURGE_INTERVAL should be positive, got %s", cfg.TeamArchivePurgeIntervalRaw)
+	}
+	cfg.AccessControlPolicyWatchInterval, errInParseDuration = time.ParseDuration(cfg.AccessControlPolicyWatchIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	if cfg.AccessControlPolicyWatchInterval <= 0 {
+		return nil, fmt.Errorf("ILLA_ACCESS_CONTROL_POLICY_WATCH_INTERVAL should be positive, got %s", cfg.AccessControlPolicyWatchIntervalRaw)
+	}
+	cfg.AccessControlCacheTTL, errInParseDuration = time.ParseDuration(cfg.AccessControlCacheTTLRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	if cfg.AccessControlCacheSize <= 0 {
+		return nil, fmt.Errorf("ILLA_ACCESS_CONTROL_CACHE_SIZE should be positive, got %d", cfg.AccessControlCacheSize)
+	}
+	cfg.TrustedProxies = []string{}
+	for _, trustedProxy := range strings.Split(cfg.TrustedProxiesRaw, ",") {
+		if trustedProxy = strings.TrimSpace(trustedProxy); trustedProxy != "" {
+			cfg.TrustedProxies = append(cfg.TrustedProxies, trustedProxy)
+		}
+	}
 
 	// ok
 	fmt.Printf("----------------\n")
@@ -216,3 +280,44 @@ func (c *Config) GetMINIOTeamBucketName() string {
 func (c *Config) GetMINIOTimeout() time.Duration {
 	return c.DriveUploadTimeout
 }
+
+func (c *Config) GetSystemDomain() string {
+	return c.SystemDomain
+}
+
+func (c *Config) GetTeamArchiveRetention() time.Duration {
+	return c.TeamArchiveRetention
+}
+
+func (c *Config) GetTeamArchivePurgeInterval() time.Duration {
+	return c.TeamArchivePurgeInterval
+}
+
+func (c *Config) GetDNSResolverAddr() string {
+	return c.DNSResolverAddr
+}
+
+func (c *Config) GetDNSResolverTimeout() time.Duration {
+	return c.DNSResolverTimeout
+}
+
+// the empty list means no proxy is trusted, so the client IP is the remote address.
+func (c *Config) GetTrustedProxies() []string {
+	return c.TrustedProxies
+}
+
+func (c *Config) GetAccessControlPolicyFile() string {
+	return c.AccessControlPolicyFile
+}
+
+func (c *Config) GetAccessControlPolicyWatchInterval() time.Duration {
+	return c.AccessControlPolicyWatchInterval
+}
+
+func (c *Config) GetAccessControlCacheTTL() time.Duration {
+	return c.AccessControlCacheTTL
+}
+
+func (c *Config) GetAccessControlCacheSize() int {
+	return c.AccessControlCacheSize
+}
@@ -0,0 +1,61 @@
+package dnsresolver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/illacloud/illa-supervisor-backend/src/utils/config"
+)
+
+// the resolver used to prove domain ownership, it can be replaced for self-host deploy or testing.
+type Resolver interface {
+	LookupTXT(name string) ([]string, error)
+	LookupCNAME(name string) (string, error)
+}
+
+type NetResolver struct {
+	resolver *net.Resolver
+	timeout  time.Duration
+}
+
+func NewResolverByGlobalConfig() Resolver {
+	conf := config.GetInstance()
+	return NewNetResolver(conf.GetDNSResolverAddr(), conf.GetDNSResolverTimeout())
+}
+
+// the empty addr means using the system resolver, otherwise all queries will send to addr (e.g. "8.8.8.8:53").
+func NewNetResolver(addr string, timeout time.Duration) *NetResolver {
+	resolver := net.DefaultResolver
+	if addr != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				dialer := net.Dialer{Timeout: timeout}
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	return &NetResolver{
+		resolver: resolver,
+		timeout:  timeout,
+	}
+}
+
+func (r *NetResolver) LookupTXT(name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	return r.resolver.LookupTXT(ctx, name)
+}
+
+// the trailing dot of the canonical name will be removed.
+func (r *NetResolver) LookupCNAME(name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	cname, errInLookup := r.resolver.LookupCNAME(ctx, name)
+	if errInLookup != nil {
+		return "", errInLookup
+	}
+	return strings.TrimSuffix(cname, "."), nil
+}
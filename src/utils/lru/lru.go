@@ -0,0 +1,96 @@
+package lru
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the in-process LRU cache with a ttl, it is safe for concurrent use.
+type Cache struct {
+	size    int
+	ttl     time.Duration
+	lock    sync.Mutex
+	entries *list.List
+	index   map[string]*list.Element
+}
+
+type entry struct {
+	key      string
+	value    interface{}
+	expireAt time.Time
+}
+
+// the size should be greater than 0, the least recently used entry will be evicted when the cache is full.
+func NewCache(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+}
+
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, hit := c.index[key]
+	if !hit {
+		return nil, false
+	}
+	e := element.Value.(*entry)
+	if time.Now().After(e.expireAt) {
+		c.removeElement(element)
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+	return e.value, true
+}
+
+func (c *Cache) Set(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	expireAt := time.Now().Add(c.ttl)
+	if element, hit := c.index[key]; hit {
+		e := element.Value.(*entry)
+		e.value = value
+		e.expireAt = expireAt
+		c.entries.MoveToFront(element)
+		return
+	}
+	c.index[key] = c.entries.PushFront(&entry{key: key, value: value, expireAt: expireAt})
+	for c.entries.Len() > c.size {
+		c.removeElement(c.entries.Back())
+	}
+}
+
+func (c *Cache) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, hit := c.index[key]; hit {
+		c.removeElement(element)
+	}
+}
+
+// delete all entries which key starts with prefix, it walks through the whole cache.
+func (c *Cache) DeleteByPrefix(prefix string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, element := range c.index {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(element)
+		}
+	}
+}
+
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.entries.Len()
+}
+
+func (c *Cache) removeElement(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*entry).key)
+}